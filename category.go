@@ -3,6 +3,7 @@ package notes
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -144,3 +145,91 @@ func CollectCategories(cfg *Config, mode CategoryCollectMode) (Categories, error
 
 	return cats, nil
 }
+
+// Orphans represents directories and files under home which do not belong to any note
+type Orphans struct {
+	// EmptyDirs are paths to category directories which contain no file even in their subdirectories.
+	// Hidden files such as '.gitkeep' are not counted. When nested directories are all empty, only
+	// the outermost one is contained
+	EmptyDirs []string
+	// Files are paths to files in category directories which are not notes
+	Files []string
+}
+
+// CollectOrphans collects empty category directories and non-note files under home. Hidden files
+// and directories starting with '.' are not collected, but directories containing them are not
+// considered as empty. Returned paths are sorted
+func CollectOrphans(cfg *Config) (*Orphans, error) {
+	fs, err := os.ReadDir(cfg.HomePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read home")
+	}
+
+	dirs := []string{}
+	hasFile := map[string]bool{}
+	orphans := &Orphans{}
+
+	for _, f := range fs {
+		name := f.Name()
+		if !f.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		root := filepath.Join(cfg.HomePath, name)
+		if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			path = normPathNFD(path)
+			name := info.Name()
+
+			// Mark the directory and all its parents as not empty. Directory which only contains
+			// non-note files or hidden files such as .gitkeep is not considered as empty not to remove
+			// them accidentally
+			markParents := func() {
+				for d := filepath.Dir(path); d != cfg.HomePath && !hasFile[d]; d = filepath.Dir(d) {
+					hasFile[d] = true
+				}
+			}
+
+			if strings.HasPrefix(name, ".") {
+				markParents()
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if info.IsDir() {
+				dirs = append(dirs, path)
+				return nil
+			}
+
+			if !strings.HasSuffix(name, ".md") {
+				orphans.Files = append(orphans.Files, path)
+			}
+			markParents()
+
+			return nil
+		}); err != nil {
+			return nil, errors.Wrapf(err, "Cannot walk on directory for category %q", name)
+		}
+	}
+
+	for _, d := range dirs {
+		if hasFile[d] {
+			continue
+		}
+		if p := filepath.Dir(d); p != cfg.HomePath && !hasFile[p] {
+			// Parent directory is also empty. Only outermost empty directory is collected
+			continue
+		}
+		orphans.EmptyDirs = append(orphans.EmptyDirs, d)
+	}
+
+	sort.Strings(orphans.EmptyDirs)
+	sort.Strings(orphans.Files)
+
+	return orphans, nil
+}
@@ -200,3 +200,55 @@ func TestCategoriesNoNote(t *testing.T) {
 		t.Fatal("No note should mean no category:", cats)
 	}
 }
+
+func TestCollectOrphans(t *testing.T) {
+	for _, tc := range []struct {
+		subdir string
+		dirs   []string
+		files  []string
+	}{
+		{
+			subdir: "normal",
+			dirs:   nil,
+			files:  []string{"b/not-a-note.txt"},
+		},
+		{
+			// Directories only containing hidden files such as .gitkeep are not empty
+			subdir: "empty",
+			dirs:   nil,
+			files:  nil,
+		},
+	} {
+		t.Run(tc.subdir, func(t *testing.T) {
+			cfg := configForCategoryTest(tc.subdir)
+			orphans, err := CollectOrphans(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var dirs []string
+			for _, d := range tc.dirs {
+				dirs = append(dirs, filepath.Join(cfg.HomePath, filepath.FromSlash(d)))
+			}
+			if !reflect.DeepEqual(dirs, orphans.EmptyDirs) {
+				t.Fatal("Wanted empty dirs", dirs, "but have", orphans.EmptyDirs)
+			}
+
+			var files []string
+			for _, f := range tc.files {
+				files = append(files, filepath.Join(cfg.HomePath, filepath.FromSlash(f)))
+			}
+			if !reflect.DeepEqual(files, orphans.Files) {
+				t.Fatal("Wanted non-note files", files, "but have", orphans.Files)
+			}
+		})
+	}
+}
+
+func TestCollectOrphansNoHome(t *testing.T) {
+	cfg := &Config{HomePath: "/path/to/somewhere/unknown"}
+	_, err := CollectOrphans(cfg)
+	if err == nil || !strings.Contains(err.Error(), "Cannot read home") {
+		t.Fatal("Got unexpected", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/mattn/go-colorable"
 	"gopkg.in/alecthomas/kingpin.v2"
+	"io"
 	"os"
 )
 
@@ -33,22 +34,109 @@ command line.
 notes is developed at https://github.com/rhysd/notes-cli. If you're seeing a bug or having a feature request,
 please create a new issue. Pull requests are more than welcome.`
 
-// ParseCmd parses given arguments as command line options and returns corresponding subcommand instance.
-// When no subcommand matches or argus contains invalid argument, it returns an error
-func ParseCmd(args []string) (Cmd, error) {
-	cli := kingpin.New("notes", description)
-	noColor := cli.Flag("no-color", "Disable color output").Bool()
-	colorAlways := cli.Flag("color-always", "Enable color output always").Short('A').Bool()
+// globalFlags represents values of global flags of notes command
+type globalFlags struct {
+	noColor     *bool
+	colorAlways *bool
+	notebook    *string
+}
 
+// newCLI creates a command line parser of notes command with global flags. Subcommands are not defined
+func newCLI() (*kingpin.Application, *globalFlags) {
+	cli := kingpin.New("notes", description)
+	flags := &globalFlags{
+		noColor:     cli.Flag("no-color", "Disable color output").Bool(),
+		colorAlways: cli.Flag("color-always", "Enable color output always").Short('A').Bool(),
+		notebook:    cli.Flag("notebook", "Name of notebook to use as home. Notebooks are configured in [notebooks] section of config file").Short('n').String(),
+	}
 	cli.Version(Version)
 	cli.Author("rhysd <https://github.com/rhysd>")
 	cli.HelpFlag.Short('h')
+	return cli, flags
+}
 
-	c, err := NewConfig()
+// subcommands returns all subcommands of notes command sharing the given config
+func subcommands(c *Config, colorStdout io.Writer) []parsableCmd {
+	return []parsableCmd{
+		&NewCmd{Config: c},
+		&ListCmd{Config: c, Out: colorStdout},
+		&CategoriesCmd{Config: c, Out: os.Stdout},
+		&PruneCmd{Config: c, In: os.Stdin, Out: os.Stdout},
+		&TagsCmd{Config: c, Out: os.Stdout},
+		&SaveCmd{Config: c, Out: os.Stdout},
+		&SyncCmd{Config: c, In: os.Stdin, Out: os.Stdout},
+		&GitSetupCmd{Config: c, Out: os.Stdout},
+		&GitCmd{Config: c, In: os.Stdin, Out: os.Stdout},
+		&LogCmd{Config: c, Out: colorStdout},
+		&DiffCmd{Config: c, Out: colorStdout},
+		&RestoreCmd{Config: c, Out: os.Stdout},
+		&ExportHTMLCmd{Config: c, Out: os.Stdout},
+		&ExportBundleCmd{Config: c, Out: os.Stdout},
+		&ExportSiteCmd{Config: c, Out: os.Stdout},
+		&ImportBundleCmd{Config: c, In: os.Stdin, Out: os.Stdout},
+		&ImportMemolistCmd{Config: c, Out: os.Stdout},
+		&ImportDirCmd{Config: c, Out: os.Stdout},
+		&ImportEnexCmd{Config: c, Out: os.Stdout},
+		&ServeCmd{Config: c, Out: os.Stdout},
+		&WatchCmd{Config: c, Out: os.Stdout},
+		&LinksCmd{Config: c, Out: os.Stdout},
+		&BacklinksCmd{Config: c, Out: os.Stdout},
+		&MvCmd{Config: c, Out: os.Stdout},
+		&GraphCmd{Config: c, Out: os.Stdout},
+		&TodoCmd{Config: c, Out: os.Stdout},
+		&TodoCheckCmd{Config: c, Out: os.Stdout},
+		&AgendaCmd{Config: c, Out: colorStdout},
+		&ConfigCmd{Config: c, Out: os.Stdout},
+		&ConfigSetCmd{Config: c},
+		&NotebooksCmd{Config: c, Out: colorStdout},
+		&SelfupdateCmd{Out: colorStdout},
+	}
+}
+
+// notebookFlag parses given arguments only to know the value of --notebook option. Config cannot be
+// loaded before parsing arguments since it depends on the notebook, and default values of some flags
+// depend on config. So arguments are parsed twice. This parse does not run any action such as showing
+// help and invalid arguments are reported by the second parse
+func notebookFlag(args []string) string {
+	cli, _ := newCLI()
+	for _, cmd := range subcommands(nil, nil) {
+		cmd.defineCLI(cli)
+	}
+
+	ctx, _ := cli.ParseContext(passthroughGitArgs(args))
+	if ctx == nil {
+		return ""
+	}
+	notebook := ""
+	for _, e := range ctx.Elements {
+		if f, ok := e.Clause.(*kingpin.FlagClause); ok && f.Model().Name == "notebook" && e.Value != nil {
+			notebook = *e.Value
+		}
+	}
+	return notebook
+}
+
+// ParseCmd parses given arguments as command line options and returns corresponding subcommand instance.
+// When no subcommand matches or argus contains invalid argument, it returns an error
+func ParseCmd(args []string) (Cmd, error) {
+	// Notebook is resolved at first so that config file in its home is loaded and default home is not
+	// created when other notebook is specified
+	c, err := newConfig(notebookFlag(args))
 	if err != nil {
 		return nil, err
 	}
 
+	// Value of --notebook was already applied to the config
+	cli, flags := newCLI()
+
+	// Default of color output can be configured in config file. Flags are prioritized over it
+	switch c.Color {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	}
+
 	colorStdout := colorable.NewColorableStdout()
 
 	// When `notes` command is run with no argument,
@@ -61,37 +149,30 @@ func ParseCmd(args []string) (Cmd, error) {
 				Config:  c,
 				Out:     colorStdout,
 				Oneline: true,
+				SortBy:  c.List.SortBy,
 			}, nil
 		}
 	}
 
-	cmds := []parsableCmd{
-		&NewCmd{Config: c},
-		&ListCmd{Config: c, Out: colorStdout},
-		&CategoriesCmd{Config: c, Out: os.Stdout},
-		&TagsCmd{Config: c, Out: os.Stdout},
-		&SaveCmd{Config: c},
-		&ConfigCmd{Config: c, Out: os.Stdout},
-		&SelfupdateCmd{Out: colorStdout},
-	}
-
+	cmds := subcommands(c, colorStdout)
 	for _, cmd := range cmds {
 		cmd.defineCLI(cli)
 	}
 
-	parsed, err := cli.Parse(args)
+	parsed, err := cli.Parse(passthroughGitArgs(args))
 	if err != nil {
 		if ext, ok := NewExternalCmd(err, args); ok {
+			ext.Env = externalCmdEnv(c)
 			return ext, nil
 		}
 		return nil, err
 	}
 
-	if *colorAlways {
+	if *flags.colorAlways {
 		color.NoColor = false
 	}
 
-	if *noColor {
+	if *flags.noColor {
 		color.NoColor = true
 	}
 
@@ -0,0 +1,262 @@
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// agendaItem is a note or a task item which has a due date
+type agendaItem struct {
+	Due  time.Time
+	Note *Note
+	// Line is a line number of the task item. It is 0 when the item is the note itself
+	Line int
+	Text string
+}
+
+// ref returns a reference to the item as 'category/file.md:line' or 'category/file.md'
+func (item *agendaItem) ref() string {
+	p := filepath.ToSlash(item.Note.RelFilePath())
+	if item.Line == 0 {
+		return p
+	}
+	return fmt.Sprintf("%s:%d", p, item.Line)
+}
+
+// AgendaCmd represents `notes agenda` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type AgendaCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Category is a regex string equivalent to --cateogry
+	Category string
+	// Tag is a regex string equivalent to --tag
+	Tag string
+	// ICS is a path to iCalendar file to write items instead of showing agenda. "-" means Out. This value
+	// is equivalent to --ics option
+	ICS string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+
+	// now is current time to decide which items are overdue. Zero value means time.Now()
+	now time.Time
+}
+
+func (cmd *AgendaCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("agenda", "Show overdue, today's and upcoming notes and unchecked task items with due dates grouped by category. Due date is written as '- Due: YYYY-MM-DD' metadata of note or '@due(YYYY-MM-DD)' in task item")
+	cmd.cli.Flag("category", "Filter notes by category name with regular expression").Short('c').StringVar(&cmd.Category)
+	cmd.cli.Flag("tag", "Filter notes by tag name with regular expression").Short('t').StringVar(&cmd.Tag)
+	cmd.cli.Flag("ics", "Write all items to the iCalendar file instead so that calendar apps can subscribe it. '-' means stdout").PlaceHolder("FILE").StringVar(&cmd.ICS)
+}
+
+func (cmd *AgendaCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// collect collects items with due dates from filtered notes. Items are sorted by their due dates
+func (cmd *AgendaCmd) collect() ([]*agendaItem, error) {
+	catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := collectNotes(cmd.Config, catReg, tagReg)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*agendaItem{}
+	for _, n := range notes {
+		header, body, err := n.readContent()
+		if err != nil {
+			return nil, err
+		}
+
+		// Malformed due date is ignored by LoadNote. It is reported as warning not to prevent showing
+		// other items
+		for _, l := range strings.Split(header, "\n") {
+			if strings.HasPrefix(l, "- Due: ") {
+				if _, err := parseDueDate(l[7:]); err != nil {
+					fmt.Fprintln(os.Stderr, "Warning:", errors.Wrapf(err, "Invalid due date of note %s", filepath.ToSlash(n.RelFilePath())))
+				}
+			}
+		}
+		if !n.Due.IsZero() {
+			items = append(items, &agendaItem{n.Due, n, 0, n.Title})
+		}
+
+		for _, t := range parseTasks(body, strings.Count(header, "\n")+1) {
+			if t.Done {
+				continue
+			}
+			due, err := t.due()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Warning:", errors.Wrapf(err, "Invalid due date of task at %s:%d", filepath.ToSlash(n.RelFilePath()), t.Line))
+				continue
+			}
+			if !due.IsZero() {
+				items = append(items, &agendaItem{due, n, t.Line, t.textWithoutDue()})
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		l, r := items[i], items[j]
+		if !l.Due.Equal(r.Due) {
+			return l.Due.Before(r.Due)
+		}
+		if lp, rp := l.Note.RelFilePath(), r.Note.RelFilePath(); lp != rp {
+			return lp < rp
+		}
+		return l.Line < r.Line
+	})
+
+	return items, nil
+}
+
+func (cmd *AgendaCmd) today() time.Time {
+	now := cmd.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	y, m, d := now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func (cmd *AgendaCmd) writeSection(out *bufio.Writer, title string, items []*agendaItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	// Group items by category keeping order of due dates
+	cats := []string{}
+	grouped := map[string][]*agendaItem{}
+	for _, item := range items {
+		c := item.Note.Category
+		if _, ok := grouped[c]; !ok {
+			cats = append(cats, c)
+		}
+		grouped[c] = append(grouped[c], item)
+	}
+
+	bold.Fprintln(out, title)
+	for _, c := range cats {
+		out.WriteString("  ")
+		green.Fprintln(out, c)
+		for _, item := range grouped[c] {
+			out.WriteString("    ")
+			yellow.Fprint(out, item.Due.Format("2006-01-02"))
+			fmt.Fprintf(out, " %s: %s\n", item.ref(), item.Text)
+		}
+	}
+}
+
+func (cmd *AgendaCmd) writeAgenda(items []*agendaItem) error {
+	today := cmd.today()
+	var overdue, due, upcoming []*agendaItem
+	for _, item := range items {
+		switch {
+		case item.Due.Before(today):
+			overdue = append(overdue, item)
+		case item.Due.Equal(today):
+			due = append(due, item)
+		default:
+			upcoming = append(upcoming, item)
+		}
+	}
+
+	out := bufio.NewWriter(cmd.Out)
+	cmd.writeSection(out, "Overdue", overdue)
+	cmd.writeSection(out, "Today", due)
+	cmd.writeSection(out, "Upcoming", upcoming)
+	return out.Flush()
+}
+
+// escapeICSText escapes TEXT value of iCalendar (RFC 5545 3.3.11)
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// writeICSLine writes a content line of iCalendar folding it at 75 octets (RFC 5545 3.1)
+func writeICSLine(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i] + "\r\n ")
+		line = line[i:]
+		// Leading space of continuation line is counted
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+}
+
+func (cmd *AgendaCmd) writeICS(items []*agendaItem) error {
+	stamp := cmd.now
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	var b bytes.Buffer
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//rhysd//notes-cli//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "X-WR-CALNAME:notes")
+
+	uids := map[string]int{}
+	for _, item := range items {
+		// UID should be stable while the item is not changed. Line number is not used since it is easily
+		// changed by editing other lines
+		h := sha1.Sum([]byte(filepath.ToSlash(item.Note.RelFilePath()) + "\x00" + item.Text))
+		uid := hex.EncodeToString(h[:])
+		if n := uids[uid]; n > 0 {
+			uid = fmt.Sprintf("%s-%d", uid, n)
+		}
+		uids[hex.EncodeToString(h[:])]++
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+uid+"@notes-cli")
+		writeICSLine(&b, "DTSTAMP:"+stamp.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+item.Due.Format("20060102"))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+item.Due.AddDate(0, 0, 1).Format("20060102"))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(item.Text))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(item.ref()))
+		writeICSLine(&b, "CATEGORIES:"+escapeICSText(item.Note.Category))
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+
+	if cmd.ICS == "-" {
+		_, err := cmd.Out.Write(b.Bytes())
+		return errors.Wrap(err, "Cannot write iCalendar")
+	}
+	return errors.Wrapf(os.WriteFile(cmd.ICS, b.Bytes(), 0644), "Cannot write iCalendar file '%s'", cmd.ICS)
+}
+
+// Do runs `notes agenda` command and returns an error if occurs
+func (cmd *AgendaCmd) Do() error {
+	items, err := cmd.collect()
+	if err != nil {
+		return err
+	}
+	if cmd.ICS != "" {
+		return cmd.writeICS(items)
+	}
+	return cmd.writeAgenda(items)
+}
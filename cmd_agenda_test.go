@@ -0,0 +1,248 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhysd/go-fakeio"
+)
+
+func testAgendaConfig(subdir string) *Config {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	return &Config{HomePath: filepath.Join(cwd, "testdata", "agenda", subdir)}
+}
+
+func testAgendaNow() time.Time {
+	return time.Date(2026, 10, 19, 12, 34, 56, 0, time.Local)
+}
+
+func TestAgendaCmd(t *testing.T) {
+	for _, tc := range []struct {
+		what     string
+		category string
+		tag      string
+		want     string
+	}{
+		{
+			what: "all",
+			want: "Overdue\n" +
+				"  memo\n" +
+				"    2026-10-17 memo/shopping.md:7: milk\n" +
+				"  work\n" +
+				"    2026-10-18 work/release.md:8: update changelog\n" +
+				"Today\n" +
+				"  blog\n" +
+				"    2026-10-19 blog/post.md: Post\n" +
+				"  work\n" +
+				"    2026-10-19 work/release.md:10: tag release\n" +
+				"Upcoming\n" +
+				"  work\n" +
+				"    2026-10-25 work/release.md: Release v1.0\n" +
+				"  memo\n" +
+				"    2026-11-01 memo/shopping.md:8: bread, butter; jam\n",
+		},
+		{
+			what:     "category",
+			category: "^work$",
+			want: "Overdue\n" +
+				"  work\n" +
+				"    2026-10-18 work/release.md:8: update changelog\n" +
+				"Today\n" +
+				"  work\n" +
+				"    2026-10-19 work/release.md:10: tag release\n" +
+				"Upcoming\n" +
+				"  work\n" +
+				"    2026-10-25 work/release.md: Release v1.0\n",
+		},
+		{
+			what: "tag",
+			tag:  "^draft$",
+			want: "Today\n" +
+				"  blog\n" +
+				"    2026-10-19 blog/post.md: Post\n",
+		},
+		{
+			what:     "no item",
+			category: "^unknown$",
+			want:     "",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &AgendaCmd{
+				Config:   testAgendaConfig("normal"),
+				Category: tc.category,
+				Tag:      tc.tag,
+				Out:      &buf,
+				now:      testAgendaNow(),
+			}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have := buf.String(); have != tc.want {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
+func TestAgendaCmdICS(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &AgendaCmd{
+		Config:   testAgendaConfig("normal"),
+		Category: "^memo$",
+		ICS:      "-",
+		Out:      &buf,
+		now:      testAgendaNow(),
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	have := buf.String()
+	stamp := "DTSTAMP:" + testAgendaNow().UTC().Format("20060102T150405Z") + "\r\n"
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//rhysd//notes-cli//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"X-WR-CALNAME:notes\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:@notes-cli\r\n" +
+		stamp +
+		"DTSTART;VALUE=DATE:20261017\r\n" +
+		"DTEND;VALUE=DATE:20261018\r\n" +
+		"SUMMARY:milk\r\n" +
+		"DESCRIPTION:memo/shopping.md:7\r\n" +
+		"CATEGORIES:memo\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:@notes-cli\r\n" +
+		stamp +
+		"DTSTART;VALUE=DATE:20261101\r\n" +
+		"DTEND;VALUE=DATE:20261102\r\n" +
+		"SUMMARY:bread\\, butter\\; jam\r\n" +
+		"DESCRIPTION:memo/shopping.md:8\r\n" +
+		"CATEGORIES:memo\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	// UIDs are hashes. Check they are unique and remove them for comparison
+	uids := map[string]struct{}{}
+	lines := strings.SplitAfter(have, "\r\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "UID:") {
+			uid := strings.TrimSuffix(strings.TrimPrefix(l, "UID:"), "@notes-cli\r\n")
+			if len(uid) != 40 {
+				t.Fatal("Unexpected UID:", l)
+			}
+			uids[uid] = struct{}{}
+			lines[i] = "UID:@notes-cli\r\n"
+		}
+	}
+	if len(uids) != 2 {
+		t.Fatal("UIDs are not unique:", uids)
+	}
+	if have := strings.Join(lines, ""); have != want {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestAgendaCmdICSFile(t *testing.T) {
+	dir := "test-tmp-dir-agenda-ics"
+	panicIfErr(os.MkdirAll(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	path := filepath.Join(dir, "notes.ics")
+
+	var buf bytes.Buffer
+	cmd := &AgendaCmd{Config: testAgendaConfig("normal"), ICS: path, Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("Unexpected output:", buf.String())
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "BEGIN:VEVENT\r\n"); n != 6 {
+		t.Fatal("Unexpected number of events:", n, string(b))
+	}
+}
+
+func TestAgendaCmdInvalidDue(t *testing.T) {
+	dir := "test-tmp-dir-agenda-invalid"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+	writeTestNote(cfg, "memo/ok.md", "ok\n===\n- Category: memo\n- Tags:\n- Created: 2026-10-01T10:00:00+09:00\n- Due: 2026-10-20\n\n")
+
+	for _, tc := range []struct {
+		what    string
+		content string
+		want    string
+	}{
+		{
+			what:    "metadata",
+			content: "foo\n===\n- Category: memo\n- Tags:\n- Created: 2026-10-01T10:00:00+09:00\n- Due: tomorrow\n\n",
+			want:    "Due date must be in 'YYYY-MM-DD' format but got 'tomorrow'",
+		},
+		{
+			what:    "task",
+			content: "foo\n===\n- Category: memo\n- Tags:\n- Created: 2026-10-01T10:00:00+09:00\n\n- [ ] task @due(2026/10/01)\n",
+			want:    "Invalid due date of task at memo/foo.md:7",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			writeTestNote(cfg, "memo/foo.md", tc.content)
+
+			fake := fakeio.Stderr()
+			defer fake.Restore()
+
+			// Malformed due date is reported as warning and other items are still shown
+			var buf bytes.Buffer
+			if err := (&AgendaCmd{Config: cfg, Out: &buf, now: testAgendaNow()}).Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have, want := buf.String(), "Upcoming\n  memo\n    2026-10-20 memo/ok.md: ok\n"; have != want {
+				t.Fatal(cmp.Diff(want, have))
+			}
+			stderr, err := fake.String()
+			panicIfErr(err)
+			if !strings.Contains(stderr, "Warning:") || !strings.Contains(stderr, tc.want) {
+				t.Fatalf("Warning %q is not reported: %q", tc.want, stderr)
+			}
+
+			// Other commands are not affected by the malformed due date
+			var out bytes.Buffer
+			if err := (&ListCmd{Config: cfg, Relative: true, Out: &out}).Do(); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), filepath.Join("memo", "foo.md")) {
+				t.Fatal("Note with malformed due date is not listed:", out.String())
+			}
+		})
+	}
+}
+
+func TestWriteICSLineFolding(t *testing.T) {
+	var b bytes.Buffer
+	writeICSLine(&b, "SUMMARY:"+strings.Repeat("あ", 40))
+	for _, l := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Fatalf("Line is longer than 75 octets: %q", l)
+		}
+	}
+	unfolded := strings.ReplaceAll(b.String(), "\r\n ", "")
+	if unfolded != "SUMMARY:"+strings.Repeat("あ", 40)+"\r\n" {
+		t.Fatalf("Unexpected unfolded line: %q", unfolded)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gopkg.in/alecthomas/kingpin.v2"
 	"io"
+	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -13,21 +14,63 @@ import (
 type CategoriesCmd struct {
 	cli, cliAlias *kingpin.CmdClause
 	Config        *Config
+	// Empty is a flag equivalent to --empty
+	Empty bool
 	// Out is a writer to write output of this command. Kind of stdout is expected
 	Out io.Writer
 }
 
+func (cmd *CategoriesCmd) defineCategoriesCLI(c *kingpin.CmdClause) {
+	c.Flag("empty", "List empty category directories (with trailing '/') and non-note files in category directories instead").BoolVar(&cmd.Empty)
+}
+
 func (cmd *CategoriesCmd) defineCLI(app *kingpin.Application) {
 	cmd.cli = app.Command("categories", "List all categories to stdout (alias: cats)")
+	cmd.defineCategoriesCLI(cmd.cli)
 	cmd.cliAlias = app.Command("cats", "List all categories to stdout. Please do not expect 🐱!").Hidden()
+	cmd.defineCategoriesCLI(cmd.cliAlias)
 }
 
 func (cmd *CategoriesCmd) matchesCmdline(cmdline string) bool {
 	return cmd.cli.FullCommand() == cmdline || cmd.cliAlias.FullCommand() == cmdline
 }
 
+func (cmd *CategoriesCmd) printOrphans() error {
+	orphans, err := CollectOrphans(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, 0, len(orphans.EmptyDirs)+len(orphans.Files))
+	for _, d := range orphans.EmptyDirs {
+		rel, err := filepath.Rel(cmd.Config.HomePath, d)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, filepath.ToSlash(rel)+"/")
+	}
+	for _, f := range orphans.Files {
+		rel, err := filepath.Rel(cmd.Config.HomePath, f)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, filepath.ToSlash(rel))
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	_, err = fmt.Fprintln(cmd.Out, strings.Join(lines, "\n"))
+	return err
+}
+
 // Do runs `notes categories` command and returns an error if occurs
 func (cmd *CategoriesCmd) Do() error {
+	if cmd.Empty {
+		return cmd.printOrphans()
+	}
+
 	cats, err := CollectCategories(cmd.Config, 0)
 	if err != nil {
 		return err
@@ -52,6 +52,43 @@ func TestCategoriesCmd(t *testing.T) {
 	}
 }
 
+func TestCategoriesCmdEmpty(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+
+	for _, tc := range []struct {
+		subdir string
+		want   string
+	}{
+		{
+			subdir: "normal",
+			want:   "b/not-a-note.txt\n",
+		},
+		{
+			subdir: "empty",
+			want:   "",
+		},
+	} {
+		t.Run(tc.subdir, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := CategoriesCmd{
+				Config: &Config{HomePath: filepath.Join(cwd, "testdata", "category", tc.subdir)},
+				Empty:  true,
+				Out:    &buf,
+			}
+
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			have := buf.String()
+			if have != tc.want {
+				t.Fatalf("wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
+
 func TestCategoriesCmdError(t *testing.T) {
 	cfg := &Config{
 		HomePath: filepath.FromSlash("/path/to/somewhere/unknown/home"),
@@ -1,52 +1,230 @@
 package notes
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 	"gopkg.in/alecthomas/kingpin.v2"
 	"io"
+	"strconv"
 	"strings"
 )
 
+// configEntry is an entry of configuration shown by `notes config`
+type configEntry struct {
+	// key is a key in config file such as "list.sort"
+	key string
+	// label is a label shown in KEY=VALUE style output
+	label string
+	value func(c *Config) string
+}
+
+var configEntries = []configEntry{
+	{"home", "HOME", func(c *Config) string { return c.HomePath }},
+	{"git", "GIT", func(c *Config) string { return c.GitPath }},
+	{"editor", "EDITOR", func(c *Config) string { return c.EditorCmd }},
+	{"pager", "PAGER", func(c *Config) string { return c.PagerCmd }},
+	{"color", "COLOR", func(c *Config) string { return c.Color }},
+	{"gitattributes", "GITATTRIBUTES", func(c *Config) string { return strconv.FormatBool(c.GitAttributes) }},
+	{"list.sort", "LIST_SORT", func(c *Config) string { return c.List.SortBy }},
+	{"list.oneline", "LIST_ONELINE", func(c *Config) string { return strconv.FormatBool(c.List.Oneline) }},
+	{"save.message_template", "SAVE_MESSAGE_TEMPLATE", func(c *Config) string { return c.Save.MessageTemplate }},
+	{"save.auto", "SAVE_AUTO", func(c *Config) string { return strconv.FormatBool(c.Save.Auto) }},
+	{"save.auto_push", "SAVE_AUTO_PUSH", func(c *Config) string { return strconv.FormatBool(c.Save.AutoPush) }},
+	{"save.remote", "SAVE_REMOTE", func(c *Config) string { return c.Save.Remote }},
+	{"save.branch", "SAVE_BRANCH", func(c *Config) string { return c.Save.Branch }},
+	{"sync.remote", "SYNC_REMOTE", func(c *Config) string { return c.Sync.Remote }},
+	{"sync.branch", "SYNC_BRANCH", func(c *Config) string { return c.Sync.Branch }},
+	{"sync.strategy", "SYNC_STRATEGY", func(c *Config) string { return c.Sync.Strategy }},
+	{"export.category", "EXPORT_CATEGORY", func(c *Config) string { return c.Export.Category }},
+	{"export.tag", "EXPORT_TAG", func(c *Config) string { return c.Export.Tag }},
+	{"export.exclude_tag", "EXPORT_EXCLUDE_TAG", func(c *Config) string { return c.Export.ExcludeTag }},
+	{"watch.command", "WATCH_COMMAND", func(c *Config) string { return c.Watch.Command }},
+	{"watch.save", "WATCH_SAVE", func(c *Config) string { return strconv.FormatBool(c.Watch.Save) }},
+	{"notebook", "NOTEBOOK", func(c *Config) string { return c.Notebook }},
+}
+
+func configEntryOf(name string) (*configEntry, bool) {
+	name = strings.ToLower(name)
+	for i := range configEntries {
+		e := &configEntries[i]
+		// 'list_sort' style is also accepted since it is used for KEY=VALUE style output
+		if e.key == name || strings.ToLower(e.label) == name {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
 // ConfigCmd represents `notes config` command. Each public fields represent options of the command.
 // Out field represents where this command should output.
 type ConfigCmd struct {
-	cli    *kingpin.CmdClause
-	Config *Config
-	// Name is a name of configuration. Must be one of "", "home", "git" or "editor"
+	cli, cliShow *kingpin.CmdClause
+	Config       *Config
+	// Name is a name of configuration. Must be empty or one of keys in config file such as "home", "list.sort"
 	Name string
+	// Source is a flag equivalent to --source
+	Source bool
+	// Format is a format of output. One of "", "text" or "json". Empty means "text"
+	Format string
 	// Out is a writer to write output of this command. Kind of stdout is expected
 	Out io.Writer
 }
 
 func (cmd *ConfigCmd) defineCLI(app *kingpin.Application) {
-	cmd.cli = app.Command("config", "Output config values to stdout. By default output all values with KEY=VALUE style")
-	cmd.cli.Arg("name", "Key name. One of 'home', 'git', 'editor'. Only value will be output").StringVar(&cmd.Name)
+	cmd.cli = app.Command("config", "Output config values to stdout or set config values to config file. By default output all values with KEY=VALUE style")
+	cmd.cliShow = cmd.cli.Command("show", "Output config values to stdout. By default output all values with KEY=VALUE style").Default()
+	cmd.cliShow.Arg("name", "Key name. One of 'home', 'git', 'editor', 'pager', 'color', 'gitattributes', 'list.sort', 'list.oneline', 'save.message_template', 'save.auto', 'save.auto_push', 'save.remote', 'save.branch', 'sync.remote', 'sync.branch', 'sync.strategy', 'export.category', 'export.tag', 'export.exclude_tag', 'watch.command', 'watch.save', 'notebook'. Only value will be output").StringVar(&cmd.Name)
+	cmd.cliShow.Flag("source", "Show where each value came from (environment variable, config file or default) after the value").Short('s').BoolVar(&cmd.Source)
+	cmd.cliShow.Flag("format", "Output format. 'text' or 'json'").Default("text").EnumVar(&cmd.Format, "text", "json")
 }
 
 func (cmd *ConfigCmd) matchesCmdline(cmdline string) bool {
-	return cmd.cli.FullCommand() == cmdline
+	return cmd.cliShow.FullCommand() == cmdline
+}
+
+func (cmd *ConfigCmd) sourceOf(key string) string {
+	if src, ok := cmd.Config.Sources[key]; ok {
+		return src
+	}
+	return "unknown"
+}
+
+func (cmd *ConfigCmd) printText(entries []configEntry) error {
+	var b strings.Builder
+	for _, e := range entries {
+		if cmd.Name == "" {
+			b.WriteString(e.label + "=")
+		}
+		b.WriteString(e.value(cmd.Config))
+		if cmd.Source {
+			fmt.Fprintf(&b, " (from %s)", cmd.sourceOf(e.key))
+		}
+		b.WriteRune('\n')
+	}
+	_, err := io.WriteString(cmd.Out, b.String())
+	return err
+}
+
+func (cmd *ConfigCmd) printJSON(entries []configEntry) error {
+	type valueWithSource struct {
+		Value  string `json:"value"`
+		Source string `json:"source"`
+	}
+
+	values := make(map[string]interface{}, len(entries))
+	for _, e := range entries {
+		if cmd.Source {
+			values[e.key] = valueWithSource{e.value(cmd.Config), cmd.sourceOf(e.key)}
+		} else {
+			values[e.key] = e.value(cmd.Config)
+		}
+	}
+
+	var v interface{} = values
+	if cmd.Name != "" {
+		// Only value is output when name is specified
+		v = values[entries[0].key]
+	}
+
+	enc := json.NewEncoder(cmd.Out)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(v), "Cannot output config values as JSON")
 }
 
 // Do runs `notes config` command and returns an error if occurs
 func (cmd *ConfigCmd) Do() error {
-	switch strings.ToLower(cmd.Name) {
-	case "":
-		fmt.Fprintf(
-			cmd.Out,
-			"HOME=%s\nGIT=%s\nEDITOR=%s\n",
-			cmd.Config.HomePath,
-			cmd.Config.GitPath,
-			cmd.Config.EditorCmd,
-		)
-	case "home":
-		fmt.Fprintln(cmd.Out, cmd.Config.HomePath)
-	case "git":
-		fmt.Fprintln(cmd.Out, cmd.Config.GitPath)
-	case "editor":
-		fmt.Fprintln(cmd.Out, cmd.Config.EditorCmd)
-	default:
-		return errors.Errorf("Unknown config name '%s'", cmd.Name)
-	}
-	return nil
+	entries := configEntries
+	if cmd.Name != "" {
+		e, ok := configEntryOf(cmd.Name)
+		if !ok {
+			return errors.Errorf("Unknown config name '%s'", cmd.Name)
+		}
+		entries = []configEntry{*e}
+	}
+
+	if cmd.Format == "json" {
+		return cmd.printJSON(entries)
+	}
+	return cmd.printText(entries)
+}
+
+// ConfigSetCmd represents `notes config set` and `notes config unset` commands. Each public fields
+// represent options of the command.
+type ConfigSetCmd struct {
+	cliSet, cliUnset *kingpin.CmdClause
+	Config           *Config
+	// Key is a key of config file to set such as "editor" or "list.sort"
+	Key string
+	// Value is a value to set. This value is ignored when Unset is true
+	Value string
+	// Unset is true when the command is `notes config unset`
+	Unset bool
+	// Local is a flag equivalent to --local
+	Local bool
+}
+
+func (cmd *ConfigSetCmd) defineCLI(app *kingpin.Application) {
+	// Parent command 'config' is defined by ConfigCmd
+	parent := app.GetCommand("config")
+
+	cmd.cliSet = parent.Command("set", "Set config value to config file. By default $XDG_CONFIG_HOME/notes-cli/config.toml (or config.yaml when it exists) is written")
+	cmd.cliSet.Arg("key", "Key name. One of "+strings.Join(configFileKeys, ", ")).Required().StringVar(&cmd.Key)
+	cmd.cliSet.Arg("value", "Value to set").Required().StringVar(&cmd.Value)
+	cmd.cliSet.Flag("local", "Write .notes.toml (or .notes.yaml when it exists) in home directory instead of user-wide config file").BoolVar(&cmd.Local)
+
+	cmd.cliUnset = parent.Command("unset", "Remove config value from config file. By default $XDG_CONFIG_HOME/notes-cli/config.toml (or config.yaml when it exists) is written")
+	cmd.cliUnset.Arg("key", "Key name. One of "+strings.Join(configFileKeys, ", ")).Required().StringVar(&cmd.Key)
+	cmd.cliUnset.Flag("local", "Write .notes.toml (or .notes.yaml when it exists) in home directory instead of user-wide config file").BoolVar(&cmd.Local)
+}
+
+func (cmd *ConfigSetCmd) matchesCmdline(cmdline string) bool {
+	if cmd.cliUnset.FullCommand() == cmdline {
+		cmd.Unset = true
+		return true
+	}
+	return cmd.cliSet.FullCommand() == cmdline
+}
+
+// Do runs `notes config set` or `notes config unset` command and returns an error if occurs
+func (cmd *ConfigSetCmd) Do() error {
+	key := cmd.Key
+	if e, ok := configEntryOf(key); ok {
+		key = e.key
+	}
+
+	var path string
+	if cmd.Local {
+		if key == "home" || strings.HasPrefix(key, "notebooks.") {
+			return errors.New("'home' and 'notebooks' cannot be configured in config file in home. Please remove --local")
+		}
+		path = homeConfigFilePath(cmd.Config.HomePath)
+	} else {
+		p, err := userConfigFilePath()
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+
+	f, err := loadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		f = &configFile{path: path}
+	}
+
+	val := cmd.Value
+	if cmd.Unset {
+		val = ""
+	} else if val == "" {
+		return errors.Errorf("Value for '%s' cannot be empty. Please use 'notes config unset %s' to remove the value", cmd.Key, cmd.Key)
+	}
+
+	if err := f.set(key, val); err != nil {
+		return err
+	}
+
+	return f.save()
 }
@@ -2,23 +2,64 @@ package notes
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/rhysd/go-tmpenv"
 )
 
-func TestConfigCmd(t *testing.T) {
-	cfg := &Config{
-		HomePath:  "/path/to/home",
-		GitPath:   "/path/to/git",
-		EditorCmd: "vim",
+func testConfigForConfigCmd() *Config {
+	return &Config{
+		HomePath:      "/path/to/home",
+		GitPath:       "/path/to/git",
+		EditorCmd:     "vim",
+		PagerCmd:      "less",
+		Color:         "always",
+		GitAttributes: true,
+		List:          ListConfig{SortBy: "modified", Oneline: true},
+		Save:          SaveConfig{MessageTemplate: "Save {{len .Changes}} notes", Auto: true, Branch: "feature/notes"},
+		Sync:          SyncConfig{Remote: "upstream", Strategy: "merge"},
+		Export:        ExportConfig{Category: "^blog", ExcludeTag: "^draft$"},
+		Watch:         WatchConfig{Command: "make"},
+		Sources: map[string]string{
+			"home":                  "$NOTES_CLI_HOME",
+			"git":                   "default",
+			"editor":                "/path/to/config.toml",
+			"pager":                 "$PAGER",
+			"color":                 "/path/to/config.toml",
+			"gitattributes":         "/path/to/.notes.toml",
+			"list.sort":             "/path/to/config.toml",
+			"list.oneline":          "/path/to/config.toml",
+			"save.message_template": "/path/to/config.toml",
+			"save.auto":             "/path/to/.notes.toml",
+			"save.auto_push":        "default",
+			"save.remote":           "default",
+			"save.branch":           "/path/to/config.toml",
+			"sync.remote":           "/path/to/.notes.toml",
+			"sync.branch":           "default",
+			"sync.strategy":         "/path/to/config.toml",
+			"export.category":       "/path/to/.notes.toml",
+			"export.tag":            "default",
+			"export.exclude_tag":    "/path/to/config.toml",
+			"watch.command":         "/path/to/.notes.toml",
+			"watch.save":            "default",
+			"notebook":              "--notebook",
+		},
+		Notebook: "work",
 	}
+}
+
+func TestConfigCmd(t *testing.T) {
+	cfg := testConfigForConfigCmd()
 	for _, tc := range []struct {
 		name string
 		want string
 	}{
 		{
 			name: "",
-			want: "HOME=/path/to/home\nGIT=/path/to/git\nEDITOR=vim\n",
+			want: "HOME=/path/to/home\nGIT=/path/to/git\nEDITOR=vim\nPAGER=less\nCOLOR=always\nGITATTRIBUTES=true\nLIST_SORT=modified\nLIST_ONELINE=true\nSAVE_MESSAGE_TEMPLATE=Save {{len .Changes}} notes\nSAVE_AUTO=true\nSAVE_AUTO_PUSH=false\nSAVE_REMOTE=\nSAVE_BRANCH=feature/notes\nSYNC_REMOTE=upstream\nSYNC_BRANCH=\nSYNC_STRATEGY=merge\nEXPORT_CATEGORY=^blog\nEXPORT_TAG=\nEXPORT_EXCLUDE_TAG=^draft$\nWATCH_COMMAND=make\nWATCH_SAVE=false\nNOTEBOOK=work\n",
 		},
 		{
 			name: "home",
@@ -32,10 +73,22 @@ func TestConfigCmd(t *testing.T) {
 			name: "editor",
 			want: "vim\n",
 		},
+		{
+			name: "pager",
+			want: "less\n",
+		},
+		{
+			name: "list.sort",
+			want: "modified\n",
+		},
 		{
 			name: "HOME",
 			want: "/path/to/home\n",
 		},
+		{
+			name: "LIST_ONELINE",
+			want: "true\n",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			var buf bytes.Buffer
@@ -55,12 +108,139 @@ func TestConfigCmd(t *testing.T) {
 	}
 }
 
-func TestConfigCmdError(t *testing.T) {
-	cfg := &Config{
-		HomePath:  "/path/to/home",
-		GitPath:   "/path/to/git",
-		EditorCmd: "vim",
+func TestConfigCmdSource(t *testing.T) {
+	cfg := testConfigForConfigCmd()
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{
+			name: "",
+			want: "HOME=/path/to/home (from $NOTES_CLI_HOME)\n" +
+				"GIT=/path/to/git (from default)\n" +
+				"EDITOR=vim (from /path/to/config.toml)\n" +
+				"PAGER=less (from $PAGER)\n" +
+				"COLOR=always (from /path/to/config.toml)\n" +
+				"GITATTRIBUTES=true (from /path/to/.notes.toml)\n" +
+				"LIST_SORT=modified (from /path/to/config.toml)\n" +
+				"LIST_ONELINE=true (from /path/to/config.toml)\n" +
+				"SAVE_MESSAGE_TEMPLATE=Save {{len .Changes}} notes (from /path/to/config.toml)\n" +
+				"SAVE_AUTO=true (from /path/to/.notes.toml)\n" +
+				"SAVE_AUTO_PUSH=false (from default)\n" +
+				"SAVE_REMOTE= (from default)\n" +
+				"SAVE_BRANCH=feature/notes (from /path/to/config.toml)\n" +
+				"SYNC_REMOTE=upstream (from /path/to/.notes.toml)\n" +
+				"SYNC_BRANCH= (from default)\n" +
+				"SYNC_STRATEGY=merge (from /path/to/config.toml)\n" +
+				"EXPORT_CATEGORY=^blog (from /path/to/.notes.toml)\n" +
+				"EXPORT_TAG= (from default)\n" +
+				"EXPORT_EXCLUDE_TAG=^draft$ (from /path/to/config.toml)\n" +
+				"WATCH_COMMAND=make (from /path/to/.notes.toml)\n" +
+				"WATCH_SAVE=false (from default)\n" +
+				"NOTEBOOK=work (from --notebook)\n",
+		},
+		{
+			name: "home",
+			want: "/path/to/home (from $NOTES_CLI_HOME)\n",
+		},
+		{
+			name: "editor",
+			want: "vim (from /path/to/config.toml)\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := ConfigCmd{
+				Config: cfg,
+				Name:   tc.name,
+				Source: true,
+				Out:    &buf,
+			}
+			if err := c.Do(); err != nil {
+				t.Fatal(err)
+			}
+			have := buf.String()
+			if have != tc.want {
+				t.Fatalf("want '%#v' but have '%#v'", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestConfigCmdJSON(t *testing.T) {
+	cfg := testConfigForConfigCmd()
+	for _, tc := range []struct {
+		what   string
+		name   string
+		source bool
+		want   string
+	}{
+		{
+			what: "all",
+			want: `{
+  "color": "always",
+  "editor": "vim",
+  "export.category": "^blog",
+  "export.exclude_tag": "^draft$",
+  "export.tag": "",
+  "git": "/path/to/git",
+  "gitattributes": "true",
+  "home": "/path/to/home",
+  "list.oneline": "true",
+  "list.sort": "modified",
+  "notebook": "work",
+  "pager": "less",
+  "save.auto": "true",
+  "save.auto_push": "false",
+  "save.branch": "feature/notes",
+  "save.message_template": "Save {{len .Changes}} notes",
+  "save.remote": "",
+  "sync.branch": "",
+  "sync.remote": "upstream",
+  "sync.strategy": "merge",
+  "watch.command": "make",
+  "watch.save": "false"
+}
+`,
+		},
+		{
+			what: "name",
+			name: "editor",
+			want: "\"vim\"\n",
+		},
+		{
+			what:   "name with source",
+			name:   "home",
+			source: true,
+			want: `{
+  "value": "/path/to/home",
+  "source": "$NOTES_CLI_HOME"
+}
+`,
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := ConfigCmd{
+				Config: cfg,
+				Name:   tc.name,
+				Source: tc.source,
+				Format: "json",
+				Out:    &buf,
+			}
+			if err := c.Do(); err != nil {
+				t.Fatal(err)
+			}
+			have := buf.String()
+			if have != tc.want {
+				t.Fatalf("want '%s' but have '%s'", tc.want, have)
+			}
+		})
 	}
+}
+
+func TestConfigCmdError(t *testing.T) {
+	cfg := testConfigForConfigCmd()
 	c := ConfigCmd{
 		Config: cfg,
 		Name:   "unknown name",
@@ -73,3 +253,223 @@ func TestConfigCmdError(t *testing.T) {
 		t.Fatal("Unexpected error:", err)
 	}
 }
+
+func TestConfigSetCmd(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	dir := filepath.Join(cwd, "test-tmp-dir-config-set")
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	env := tmpenv.New("XDG_CONFIG_HOME")
+	defer env.Restore()
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", dir))
+
+	home := filepath.Join(dir, "home")
+	cfg := &Config{HomePath: home}
+	userFile := filepath.Join(dir, "notes-cli", "config.toml")
+	homeFile := filepath.Join(home, ".notes.toml")
+
+	for _, tc := range []struct {
+		key   string
+		value string
+		unset bool
+		local bool
+		file  string
+		want  string
+	}{
+		{
+			key:   "editor",
+			value: "vim",
+			file:  userFile,
+			want:  "editor = \"vim\"\n",
+		},
+		{
+			key:   "LIST_ONELINE",
+			value: "true",
+			file:  userFile,
+			want:  "editor = \"vim\"\n\n[list]\noneline = true\n",
+		},
+		{
+			key:   "list.sort",
+			value: "filename",
+			local: true,
+			file:  homeFile,
+			want:  "[list]\nsort = \"filename\"\n",
+		},
+		{
+			key:   "editor",
+			unset: true,
+			file:  userFile,
+			want:  "[list]\noneline = true\n",
+		},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			c := &ConfigSetCmd{
+				Config: cfg,
+				Key:    tc.key,
+				Value:  tc.value,
+				Unset:  tc.unset,
+				Local:  tc.local,
+			}
+			if err := c.Do(); err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have := string(b); have != tc.want {
+				t.Fatalf("want %q but have %q", tc.want, have)
+			}
+		})
+	}
+
+	// Written config files can be loaded
+	panicIfErr(os.Setenv("NOTES_CLI_HOME", home))
+	defer os.Unsetenv("NOTES_CLI_HOME")
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.List.SortBy != "filename" || !c.List.Oneline {
+		t.Fatal("Config values were not loaded:", c.List)
+	}
+}
+
+func TestConfigSetCmdYAML(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	dir := filepath.Join(cwd, "test-tmp-dir-config-set-yaml")
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	env := tmpenv.New("XDG_CONFIG_HOME")
+	defer env.Restore()
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", dir))
+
+	// Existing YAML config file is updated instead of creating TOML file
+	file := filepath.Join(dir, "notes-cli", "config.yaml")
+	panicIfErr(os.MkdirAll(filepath.Dir(file), 0755))
+	panicIfErr(os.WriteFile(file, []byte("editor: vim\n"), 0644))
+
+	c := &ConfigSetCmd{Config: &Config{HomePath: filepath.Join(dir, "home")}, Key: "list.oneline", Value: "true"}
+	if err := c.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(file)
+	panicIfErr(err)
+	if want := "editor: vim\nlist:\n  oneline: true\n"; string(b) != want {
+		t.Fatalf("Wanted %q but got %q", want, b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes-cli", "config.toml")); err == nil {
+		t.Fatal("TOML config file was created")
+	}
+}
+
+func TestConfigSetCmdError(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	dir := filepath.Join(cwd, "test-tmp-dir-config-set-error")
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	env := tmpenv.New("XDG_CONFIG_HOME")
+	defer env.Restore()
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", dir))
+
+	for _, tc := range []struct {
+		what  string
+		key   string
+		value string
+		local bool
+		want  string
+	}{
+		{
+			what:  "unknown key",
+			key:   "foo",
+			value: "bar",
+			want:  "Unknown config key 'foo'",
+		},
+		{
+			what:  "invalid sort",
+			key:   "list.sort",
+			value: "title",
+			want:  "'list.sort' must be one of",
+		},
+		{
+			what:  "invalid bool",
+			key:   "list.oneline",
+			value: "foo",
+			want:  "'list.oneline' must be boolean value",
+		},
+		{
+			what:  "invalid sync strategy",
+			key:   "sync.strategy",
+			value: "squash",
+			want:  "'sync.strategy' must be one of",
+		},
+		{
+			what:  "invalid export regex",
+			key:   "export.exclude_tag",
+			value: "(draft",
+			want:  "Invalid regular expression of 'export.exclude_tag'",
+		},
+		{
+			what:  "empty value",
+			key:   "editor",
+			value: "",
+			want:  "cannot be empty",
+		},
+		{
+			what:  "home in home",
+			key:   "home",
+			value: "/path/to/home",
+			local: true,
+			want:  "'home' and 'notebooks' cannot be configured in config file in home",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			c := &ConfigSetCmd{
+				Config: &Config{HomePath: dir},
+				Key:    tc.key,
+				Value:  tc.value,
+				Local:  tc.local,
+			}
+			err := c.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestConfigSetCmdNotebooks(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	dir := filepath.Join(cwd, "test-tmp-dir-config-set-notebooks")
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	env := tmpenv.New("XDG_CONFIG_HOME")
+	defer env.Restore()
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", dir))
+
+	cfg := &Config{HomePath: dir}
+	for _, c := range []*ConfigSetCmd{
+		{Config: cfg, Key: "notebooks.Work", Value: "~/work"},
+		{Config: cfg, Key: "notebooks.personal", Value: "~/personal"},
+		{Config: cfg, Key: "notebooks.personal", Unset: true},
+	} {
+		if err := c.Do(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "notes-cli", "config.toml"))
+	panicIfErr(err)
+	want := "[notebooks]\nWork = \"~/work\"\n"
+	if have := string(b); have != want {
+		t.Fatalf("Wanted %q but have %q", want, have)
+	}
+}
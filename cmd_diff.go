@@ -0,0 +1,71 @@
+package notes
+
+import (
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// DiffCmd represents `notes diff` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type DiffCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Note is a note to show its changes. It is an absolute path or a relative path from home such as
+	// "category/file.md"
+	Note string
+	// Revision is a Git revision to compare with. Empty means HEAD
+	Revision string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *DiffCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("diff", "Show changes of the note from the revision using Git. The output is paged with pager command")
+	cmd.cli.Arg("note", "Note to show changes. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted").Required().StringVar(&cmd.Note)
+	cmd.cli.Arg("rev", "Git revision to compare with such as commit hash shown by `notes log`. Default is HEAD").StringVar(&cmd.Revision)
+}
+
+func (cmd *DiffCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes diff` command and returns an error if occurs
+func (cmd *DiffCmd) Do() error {
+	git, err := newGitForCmd("diff", cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	path, err := noteRelPath(cmd.Config.HomePath, cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	diff, err := git.Diff(path, cmd.Revision, !color.NoColor)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		return nil
+	}
+
+	if cmd.Config.PagerCmd == "" {
+		_, err := io.WriteString(cmd.Out, diff)
+		return err
+	}
+
+	pager, err := StartPagerWriter(cmd.Config.PagerCmd, cmd.Out)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(pager, diff); err != nil {
+		return errors.Wrap(err, "Pager command did not run successfully")
+	}
+
+	pager.Wait()
+	return errors.Wrap(pager.Err, "Pager command did not run successfully")
+}
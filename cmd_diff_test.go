@@ -0,0 +1,100 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestDiffCmd(t *testing.T) {
+	old := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = old }()
+
+	cfg, revs := testNewHistoryRepo("test-tmp-dir-diff")
+	defer os.RemoveAll(cfg.HomePath)
+
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"third\n")
+
+	for _, tc := range []struct {
+		what string
+		rev  string
+		want []string
+	}{
+		{
+			what: "from HEAD",
+			want: []string{"--- a/memo/foo.md", "+++ b/memo/foo.md", "-second", "+third"},
+		},
+		{
+			what: "from revision",
+			rev:  revs[0],
+			want: []string{"--- a/memo/foo.md", "+++ b/memo/foo.md", "-first", "+third"},
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &DiffCmd{Config: cfg, Note: "memo/foo", Revision: tc.rev, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			for _, want := range tc.want {
+				if !strings.Contains(out, want+"\n") {
+					t.Errorf("Line '%s' is not included in output: %s", want, out)
+				}
+			}
+			if strings.Contains(out, "\x1b[") {
+				t.Error("Output contains color sequence:", out)
+			}
+		})
+	}
+}
+
+func TestDiffCmdWithPager(t *testing.T) {
+	cfg, _ := testNewHistoryRepo("test-tmp-dir-diff-pager")
+	defer os.RemoveAll(cfg.HomePath)
+
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"third\n")
+	cfg.PagerCmd = "cat"
+
+	var buf bytes.Buffer
+	cmd := &DiffCmd{Config: cfg, Note: "memo/foo.md", Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "+third") {
+		t.Fatal("Diff was not output via pager:", buf.String())
+	}
+}
+
+func TestDiffCmdNoChange(t *testing.T) {
+	cfg, _ := testNewHistoryRepo("test-tmp-dir-diff-nochange")
+	defer os.RemoveAll(cfg.HomePath)
+
+	var buf bytes.Buffer
+	cmd := &DiffCmd{Config: cfg, Note: "memo/foo.md", Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("Output should be empty:", buf.String())
+	}
+}
+
+func TestDiffCmdUnknownRevision(t *testing.T) {
+	cfg, _ := testNewHistoryRepo("test-tmp-dir-diff-error")
+	defer os.RemoveAll(cfg.HomePath)
+
+	var buf bytes.Buffer
+	cmd := &DiffCmd{Config: cfg, Note: "memo/foo.md", Revision: "unknown-revision", Out: &buf}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Cannot show diff of 'memo/foo.md' from 'unknown-revision'") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,14 @@
+package notes
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// exportCommand returns `notes export` command which is a parent of all export subcommands. It is
+// defined at first call
+func exportCommand(app *kingpin.Application) *kingpin.CmdClause {
+	if c := app.GetCommand("export"); c != nil {
+		return c
+	}
+	return app.Command("export", "Export notes to other formats")
+}
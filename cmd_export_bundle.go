@@ -0,0 +1,162 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// bundleVersion is a version of format of JSON bundle. It is incremented when the format is changed
+// incompatibly
+const bundleVersion = 1
+
+// bundleNote is a note in JSON bundle
+type bundleNote struct {
+	// Path is a slash-separated relative path of the note from home
+	Path     string    `json:"path"`
+	Category string    `json:"category"`
+	Tags     []string  `json:"tags"`
+	Created  time.Time `json:"created"`
+	Title    string    `json:"title"`
+	// Content is a raw content of the note file including title and metadata
+	Content string `json:"content"`
+}
+
+// bundle is an archive of notes written by `notes export bundle` and read by `notes import bundle`
+type bundle struct {
+	Version int           `json:"version"`
+	Notes   []*bundleNote `json:"notes"`
+}
+
+// ExportBundleCmd represents `notes export bundle` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type ExportBundleCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Category is a regex string equivalent to --cateogry
+	Category string
+	// Tag is a regex string equivalent to --tag
+	Tag string
+	// SortBy is a string indicating how to sort notes in the bundle. This value is equivalent to --sort option
+	SortBy string
+	// Format is a format of the bundle. One of "markdown" or "json". Empty means "markdown"
+	Format string
+	// File is a path to file where the bundle is written. When empty, it is written to Out
+	File string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *ExportBundleCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = exportCommand(app).Command("bundle", "Export notes into one Markdown document with table of contents or into JSON archive which can be imported with 'import bundle'. Notes can be filtered as 'list' command")
+	cmd.cli.Flag("category", "Filter notes by category name with regular expression").Short('c').StringVar(&cmd.Category)
+	cmd.cli.Flag("tag", "Filter notes by tag name with regular expression").Short('t').StringVar(&cmd.Tag)
+	cmd.cli.Flag("sort", "Sort notes by 'modified', 'created', 'filename' or 'category'. Default is 'created'").Short('s').EnumVar(&cmd.SortBy, "modified", "created", "filename", "category")
+	cmd.cli.Flag("format", "Format of the bundle. 'markdown' or 'json'").Default("markdown").EnumVar(&cmd.Format, "markdown", "json")
+	cmd.cli.Flag("out", "File to write the bundle. If omitted, it is output to stdout").Short('o').StringVar(&cmd.File)
+}
+
+func (cmd *ExportBundleCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *ExportBundleCmd) writeMarkdown(w io.Writer, notes []*Note) error {
+	var b bytes.Buffer
+	b.WriteString("# Notes\n\n")
+	for i, n := range notes {
+		fmt.Fprintf(&b, "- [%s](#note-%d) (`%s`)\n", n.Title, i+1, filepath.ToSlash(n.RelFilePath()))
+	}
+
+	for i, n := range notes {
+		body, err := n.ReadBody()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "\n---\n\n<a id=\"note-%d\"></a>\n\n## %s\n\n", i+1, n.Title)
+		fmt.Fprintf(&b, "- Category: %s\n", n.Category)
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(n.Tags, ", "))
+		fmt.Fprintf(&b, "- Created: %s\n", n.Created.Format(time.RFC3339))
+		if body != "" {
+			b.WriteRune('\n')
+			b.WriteString(body)
+			if !strings.HasSuffix(body, "\n") {
+				b.WriteRune('\n')
+			}
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return errors.Wrap(err, "Cannot write bundle")
+}
+
+func (cmd *ExportBundleCmd) writeJSON(w io.Writer, notes []*Note) error {
+	b := &bundle{
+		Version: bundleVersion,
+		Notes:   make([]*bundleNote, 0, len(notes)),
+	}
+	for _, n := range notes {
+		content, err := os.ReadFile(n.FilePath())
+		if err != nil {
+			return errors.Wrapf(err, "Cannot read note '%s'", n.RelFilePath())
+		}
+		b.Notes = append(b.Notes, &bundleNote{
+			Path:     filepath.ToSlash(n.RelFilePath()),
+			Category: n.Category,
+			Tags:     n.Tags,
+			Created:  n.Created,
+			Title:    n.Title,
+			Content:  string(content),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(b), "Cannot write bundle as JSON")
+}
+
+// Do runs `notes export bundle` command and returns an error if occurs
+func (cmd *ExportBundleCmd) Do() error {
+	catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+	if err != nil {
+		return err
+	}
+
+	notes, err := collectNotes(cmd.Config, catReg, tagReg)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		return errors.New("No note to export was found")
+	}
+
+	by := cmd.SortBy
+	if by == "" {
+		by = cmd.Config.List.SortBy
+	}
+	if err := sortNotes(notes, by); err != nil {
+		return err
+	}
+
+	w := cmd.Out
+	if cmd.File != "" {
+		f, err := os.Create(cmd.File)
+		if err != nil {
+			return errors.Wrap(err, "Cannot create file to write bundle")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if cmd.Format == "json" {
+		return cmd.writeJSON(w, notes)
+	}
+	return cmd.writeMarkdown(w, notes)
+}
@@ -0,0 +1,141 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportBundleCmdMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &ExportBundleCmd{
+		Config: testExportConfig("normal"),
+		Format: "markdown",
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Notes\n" +
+		"\n" +
+		"- [Todo](#note-1) (`memo/todo.md`)\n" +
+		"- [Introduction to notes](#note-2) (`blog/tech/intro.md`)\n" +
+		"\n" +
+		"---\n" +
+		"\n" +
+		"<a id=\"note-1\"></a>\n" +
+		"\n" +
+		"## Todo\n" +
+		"\n" +
+		"- Category: memo\n" +
+		"- Tags: go\n" +
+		"- Created: 2018-11-01T09:00:00+09:00\n" +
+		"\n" +
+		"- [ ] Write a blog post about [intro](/blog/tech/intro.md)\n" +
+		"\n" +
+		"---\n" +
+		"\n" +
+		"<a id=\"note-2\"></a>\n" +
+		"\n" +
+		"## Introduction to notes\n" +
+		"\n" +
+		"- Category: blog/tech\n" +
+		"- Tags: go, cli\n" +
+		"- Created: 2018-10-30T11:37:45+09:00\n" +
+		"\n" +
+		"This is [my todo](../../memo/todo.md#today) and [website](https://example.com).\n" +
+		"\n" +
+		"![logo](img/logo.png)\n"
+	if have := buf.String(); have != want {
+		t.Fatalf("Unexpected bundle:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestExportBundleCmdJSON(t *testing.T) {
+	dir := "test-tmp-dir-export-bundle"
+	panicIfErr(os.MkdirAll(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cfg := testExportConfig("normal")
+	file := filepath.Join(dir, "bundle.json")
+	cmd := &ExportBundleCmd{
+		Config: cfg,
+		Tag:    "^cli$",
+		Format: "json",
+		File:   file,
+		Out:    &bytes.Buffer{},
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(file)
+	panicIfErr(err)
+	var have bundle
+	if err := json.Unmarshal(b, &have); err != nil {
+		t.Fatal(err, string(b))
+	}
+
+	if have.Version != bundleVersion {
+		t.Error("Unexpected version:", have.Version)
+	}
+	if len(have.Notes) != 1 {
+		t.Fatal("Only one note should be selected by tag:", have.Notes)
+	}
+
+	n := have.Notes[0]
+	content, err := os.ReadFile(filepath.Join(cfg.HomePath, "blog", "tech", "intro.md"))
+	panicIfErr(err)
+	if n.Path != "blog/tech/intro.md" || n.Category != "blog/tech" || n.Title != "Introduction to notes" {
+		t.Error("Unexpected note:", *n)
+	}
+	if strings.Join(n.Tags, ",") != "go,cli" {
+		t.Error("Unexpected tags:", n.Tags)
+	}
+	if n.Created.Format("2006-01-02T15:04:05Z07:00") != "2018-10-30T11:37:45+09:00" {
+		t.Error("Unexpected created date:", n.Created)
+	}
+	if n.Content != string(content) {
+		t.Errorf("Content is not raw file content: %q", n.Content)
+	}
+}
+
+func TestExportBundleCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		what string
+		cmd  *ExportBundleCmd
+		want string
+	}{
+		{
+			what: "invalid category regex",
+			cmd:  &ExportBundleCmd{Category: "(foo"},
+			want: "Regular expression for filtering categories is invalid",
+		},
+		{
+			what: "invalid tag regex",
+			cmd:  &ExportBundleCmd{Tag: "(foo"},
+			want: "Regular expression for filtering tags is invalid",
+		},
+		{
+			what: "no note",
+			cmd:  &ExportBundleCmd{Category: "^not-existing$"},
+			want: "No note to export was found",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			tc.cmd.Config = testExportConfig("normal")
+			tc.cmd.Out = &bytes.Buffer{}
+			err := tc.cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
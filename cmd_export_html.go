@@ -0,0 +1,467 @@
+package notes
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const exportHTMLLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 860px; margin: 0 auto; padding: 1em; font-family: sans-serif; line-height: 1.6; color: #24292e; }
+a { color: #0366d6; text-decoration: none; }
+a:hover { text-decoration: underline; }
+nav { border-bottom: 1px solid #e1e4e8; padding-bottom: .5em; }
+nav form { display: inline; margin-left: 1em; }
+.meta { color: #586069; font-size: 90%; }
+.tag { display: inline-block; margin-right: .5em; }
+.date { color: #586069; font-size: 90%; margin-left: .5em; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+code { background: #f6f8fa; }
+img { max-width: 100%; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #dfe2e5; padding: .3em .8em; }
+blockquote { color: #6a737d; border-left: .25em solid #dfe2e5; margin-left: 0; padding-left: 1em; }
+</style>
+</head>
+<body>
+<nav><a href="{{.Root}}index.html">Index</a>{{with .Search}}
+<form class="search" action="{{$.Root}}search">
+<input name="category" placeholder="Category regex" value="{{.Category}}">
+<input name="tag" placeholder="Tag regex" value="{{.Tag}}">
+<select name="sort">{{range .Sorts}}<option{{if eq . $.Search.Sort}} selected{{end}}>{{.}}</option>{{end}}</select>
+<button>Search</button>
+</form>{{end}}</nav>
+{{template "content" .}}
+</body>
+</html>
+`
+
+const exportHTMLIndex = `{{define "content"}}
+<h1>Notes</h1>
+{{range .Categories}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+<ul>
+{{range .Notes}}<li><a href="{{.Path}}">{{.Title}}</a><span class="date">{{.Created}}</span></li>
+{{end}}</ul>
+{{end}}
+{{if .Tags}}<h2>Tags</h2>
+<p>{{range .Tags}}<a class="tag" href="{{.Path}}">{{.Name}} ({{.Count}})</a>{{end}}</p>
+{{end}}{{end}}`
+
+const exportHTMLTag = `{{define "content"}}
+<h1>Tag: {{.Title}}</h1>
+<ul>
+{{range .Notes}}<li><a href="{{.Path}}">{{.Title}}</a><span class="date">{{.Created}}</span></li>
+{{end}}</ul>
+{{end}}`
+
+const exportHTMLSearch = `{{define "content"}}
+<h1>Search</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>
+{{else}}<p>{{len .Notes}} notes</p>
+<ul>
+{{range .Notes}}<li><a href="{{.Path}}">{{.Title}}</a><span class="date">{{.Created}}</span></li>
+{{end}}</ul>
+{{end}}{{end}}`
+
+const exportHTMLNote = `{{define "content"}}
+<h1>{{.Title}}</h1>
+<div class="meta">
+<div>Category: <a href="{{.Root}}index.html#{{.Category}}">{{.Category}}</a></div>
+<div>Tags: {{range .Tags}}<a class="tag" href="{{.Path}}">{{.Name}}</a>{{end}}</div>
+<div>Created: {{.Created}}</div>
+</div>
+<article>
+{{.Body}}
+</article>
+{{end}}`
+
+type exportHTMLLink struct {
+	Name    string
+	Title   string
+	Path    string
+	Created string
+	Count   int
+}
+
+type exportHTMLCategory struct {
+	Name  string
+	Notes []exportHTMLLink
+}
+
+type exportHTMLPage struct {
+	// Root is a relative path from the page to root directory of the site such as "../../"
+	Root       string
+	Title      string
+	Category   string
+	Created    string
+	Tags       []exportHTMLLink
+	Notes      []exportHTMLLink
+	Categories []exportHTMLCategory
+	Body       template.HTML
+	// Search is a state of search form. It is set only when the page is served by `notes serve`
+	Search *exportHTMLSearchForm
+	Error  string
+}
+
+type exportHTMLSearchForm struct {
+	Category string
+	Tag      string
+	Sort     string
+	Sorts    []string
+}
+
+// exportHTMLSite is a set of notes and tags to render the site
+type exportHTMLSite struct {
+	// notes is a list of notes sorted by categories and created dates
+	notes  []*Note
+	index  *exportHTMLPage
+	tags   []string
+	tagged map[string][]*Note
+}
+
+// htmlRenderer renders pages of notes into HTML. It is shared by `notes export html` and `notes serve`
+type htmlRenderer struct {
+	config    *Config
+	md        goldmark.Markdown
+	templates map[string]*template.Template
+	// siteDir is a directory where local files linked from notes are copied. When it is empty, files are
+	// not copied
+	siteDir string
+}
+
+// ExportHTMLCmd represents `notes export html` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type ExportHTMLCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Dir is a path to directory where the site is generated. This value is equivalent to --out option
+	Dir string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+
+	renderer *htmlRenderer
+}
+
+func (cmd *ExportHTMLCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = exportCommand(app).Command("html", "Export all notes to static HTML site which can be browsed offline. Index page grouped by categories, tag pages and page for each note are generated")
+	cmd.cli.Flag("out", "Directory to generate the site").Short('o').Required().StringVar(&cmd.Dir)
+}
+
+func (cmd *ExportHTMLCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// exportHTMLTagFile returns a file name of tag page. Characters which cannot be used for file name
+// are replaced with '_'. Since different tags such as "a/b" and "a:b" are replaced with the same name,
+// short hash of the tag is appended to the name when some characters were replaced
+func exportHTMLTagFile(tag string) string {
+	r := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+	name := r.Replace(tag)
+	if name != tag {
+		sum := sha1.Sum([]byte(tag))
+		name += "-" + hex.EncodeToString(sum[:4])
+	}
+	return name + ".html"
+}
+
+// exportHTMLPath converts a relative path of note from home into slash-separated path of the page
+// from root of the site
+func exportHTMLPath(rel string) string {
+	return path.Join("notes", strings.TrimSuffix(filepath.ToSlash(rel), ".md")+".html")
+}
+
+func urlOfPath(p string) string {
+	return (&url.URL{Path: p}).String()
+}
+
+func newHTMLRenderer(cfg *Config, siteDir string) (*htmlRenderer, error) {
+	r := &htmlRenderer{
+		config:    cfg,
+		md:        goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		templates: map[string]*template.Template{},
+		siteDir:   siteDir,
+	}
+	for name, src := range map[string]string{
+		"index":  exportHTMLIndex,
+		"tag":    exportHTMLTag,
+		"search": exportHTMLSearch,
+		"note":   exportHTMLNote,
+	} {
+		t, err := template.New(name).Parse(exportHTMLLayout)
+		if err == nil {
+			_, err = t.Parse(src)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot parse HTML template for %s page", name)
+		}
+		r.templates[name] = t
+	}
+	return r, nil
+}
+
+func (r *htmlRenderer) render(w io.Writer, kind string, page *exportHTMLPage) error {
+	return r.templates[kind].Execute(w, page)
+}
+
+func (cmd *ExportHTMLCmd) writePage(kind, rel string, page *exportHTMLPage) error {
+	var b bytes.Buffer
+	if err := cmd.renderer.render(&b, kind, page); err != nil {
+		return errors.Wrapf(err, "Cannot render HTML page '%s'", rel)
+	}
+	p := filepath.Join(cmd.Dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return errors.Wrapf(err, "Cannot create directory for HTML page '%s'", canonPath(p))
+	}
+	return errors.Wrapf(os.WriteFile(p, b.Bytes(), 0644), "Cannot write HTML page '%s'", canonPath(p))
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot read file '%s'", canonPath(src))
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return errors.Wrapf(err, "Cannot create directory for '%s'", canonPath(dst))
+	}
+	return errors.Wrapf(os.WriteFile(dst, b, 0644), "Cannot write file '%s'", canonPath(dst))
+}
+
+// rewriteDest rewrites destination of link or image in the note. Links to other notes are rewritten to
+// their HTML pages and local files such as images are copied into the site. Destinations outside home
+// and URLs are not changed
+func (r *htmlRenderer) rewriteDest(note *Note, dest string) (string, error) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return dest, nil
+	}
+
+	home := r.config.HomePath
+	target := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(note.DirPath(), target)
+	}
+	rel, err := filepath.Rel(home, target)
+	if err == nil && filepath.IsAbs(filepath.FromSlash(u.Path)) && (rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+		// Absolute path such as "/category/file.md" is a path from home
+		rel, err = filepath.Rel(home, filepath.Join(home, target))
+		target = filepath.Join(home, rel)
+	}
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dest, nil
+	}
+
+	var page string
+	if strings.HasSuffix(rel, ".md") {
+		page = exportHTMLPath(rel)
+	} else {
+		s, err := os.Stat(target)
+		if err != nil || s.IsDir() {
+			return dest, nil
+		}
+		page = path.Join("notes", filepath.ToSlash(rel))
+		if r.siteDir != "" {
+			if err := copyFile(target, filepath.Join(r.siteDir, filepath.FromSlash(page))); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	from := path.Dir(exportHTMLPath(note.RelFilePath()))
+	p, err := filepath.Rel(filepath.FromSlash(from), filepath.FromSlash(page))
+	if err != nil {
+		return dest, nil
+	}
+	u.Path = filepath.ToSlash(p)
+	return u.String(), nil
+}
+
+func (r *htmlRenderer) renderBody(note *Note) (template.HTML, error) {
+	body, err := note.ReadBody()
+	if err != nil {
+		return "", err
+	}
+
+	src := []byte(body)
+	doc := r.md.Parser().Parse(text.NewReader(src))
+	err = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			d, err := r.rewriteDest(note, string(n.Destination))
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			n.Destination = []byte(d)
+		case *ast.Image:
+			d, err := r.rewriteDest(note, string(n.Destination))
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			n.Destination = []byte(d)
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := r.md.Renderer().Render(&b, src, doc); err != nil {
+		return "", errors.Wrapf(err, "Cannot render note '%s' as HTML", note.RelFilePath())
+	}
+	return template.HTML(b.String()), nil
+}
+
+func exportHTMLLinkOf(note *Note, root string) exportHTMLLink {
+	return exportHTMLLink{
+		Title:   note.Title,
+		Path:    root + urlOfPath(exportHTMLPath(note.RelFilePath())),
+		Created: note.Created.Format("2006-01-02"),
+	}
+}
+
+// notePage returns a page of the note. root is a path to root of the site from the page
+func (r *htmlRenderer) notePage(note *Note, root string) (*exportHTMLPage, error) {
+	body, err := r.renderBody(note)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]exportHTMLLink, 0, len(note.Tags))
+	for _, t := range note.Tags {
+		tags = append(tags, exportHTMLLink{
+			Name: t,
+			Path: root + "tags/" + urlOfPath(exportHTMLTagFile(t)),
+		})
+	}
+
+	return &exportHTMLPage{
+		Root:     root,
+		Title:    note.Title,
+		Category: note.Category,
+		Created:  note.Created.Format(time.RFC3339),
+		Tags:     tags,
+		Body:     body,
+	}, nil
+}
+
+// tagPage returns a page listing notes which have the tag. root is a path to root of the site from the page
+func tagPage(tag string, notes []*Note, root string) *exportHTMLPage {
+	page := &exportHTMLPage{Root: root, Title: tag}
+	for _, n := range notes {
+		page.Notes = append(page.Notes, exportHTMLLinkOf(n, root))
+	}
+	return page
+}
+
+// collectHTMLSite collects all notes in home and builds the index page
+func collectHTMLSite(cfg *Config) (*exportHTMLSite, error) {
+	cats, err := CollectCategories(cfg, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	site := &exportHTMLSite{
+		index:  &exportHTMLPage{Title: "Notes"},
+		tagged: map[string][]*Note{},
+	}
+
+	names := cats.Names()
+	sort.Strings(names)
+	for _, name := range names {
+		notes, err := cats[name].Notes(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sortByCreated(notes)
+
+		c := exportHTMLCategory{Name: name, Notes: make([]exportHTMLLink, 0, len(notes))}
+		for _, n := range notes {
+			c.Notes = append(c.Notes, exportHTMLLinkOf(n, ""))
+			for _, t := range n.Tags {
+				site.tagged[t] = append(site.tagged[t], n)
+			}
+		}
+		site.index.Categories = append(site.index.Categories, c)
+		site.notes = append(site.notes, notes...)
+	}
+
+	site.tags = make([]string, 0, len(site.tagged))
+	for t := range site.tagged {
+		site.tags = append(site.tags, t)
+	}
+	sort.Strings(site.tags)
+
+	for _, t := range site.tags {
+		sortByCreated(site.tagged[t])
+		site.index.Tags = append(site.index.Tags, exportHTMLLink{
+			Name:  t,
+			Path:  "tags/" + urlOfPath(exportHTMLTagFile(t)),
+			Count: len(site.tagged[t]),
+		})
+	}
+
+	return site, nil
+}
+
+// Do runs `notes export html` command and returns an error if occurs
+func (cmd *ExportHTMLCmd) Do() error {
+	site, err := collectHTMLSite(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	if cmd.renderer, err = newHTMLRenderer(cmd.Config, cmd.Dir); err != nil {
+		return err
+	}
+
+	for _, n := range site.notes {
+		rel := exportHTMLPath(n.RelFilePath())
+		page, err := cmd.renderer.notePage(n, strings.Repeat("../", strings.Count(rel, "/")))
+		if err != nil {
+			return err
+		}
+		if err := cmd.writePage("note", rel, page); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range site.tags {
+		if err := cmd.writePage("tag", "tags/"+exportHTMLTagFile(t), tagPage(t, site.tagged[t], "../")); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.writePage("index", "index.html", site.index); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Out, "Exported %d notes to %s\n", len(site.notes), canonPath(cmd.Dir))
+	return nil
+}
@@ -0,0 +1,141 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testExportConfig(subdir string) *Config {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	return &Config{HomePath: filepath.Join(cwd, "testdata", "export", subdir)}
+}
+
+func TestExportHTMLCmd(t *testing.T) {
+	dir := "test-tmp-dir-export-html"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cfg := testExportConfig("normal")
+	var buf bytes.Buffer
+	cmd := &ExportHTMLCmd{Config: cfg, Dir: dir, Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := buf.String(); !strings.HasPrefix(out, "Exported 2 notes to ") {
+		t.Fatal("Unexpected output:", out)
+	}
+
+	read := func(rel string) string {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatal(rel, "was not generated:", err)
+		}
+		return string(b)
+	}
+
+	for _, tc := range []struct {
+		file     string
+		contains []string
+		excludes []string
+	}{
+		{
+			file: "index.html",
+			contains: []string{
+				`<h2 id="blog/tech">blog/tech</h2>`,
+				`<a href="notes/blog/tech/intro.html">Introduction to notes</a><span class="date">2018-10-30</span>`,
+				`<h2 id="memo">memo</h2>`,
+				`<a href="notes/memo/todo.html">Todo</a>`,
+				`<a class="tag" href="tags/cli.html">cli (1)</a>`,
+				`<a class="tag" href="tags/go.html">go (2)</a>`,
+			},
+		},
+		{
+			file: "tags/go.html",
+			contains: []string{
+				`<h1>Tag: go</h1>`,
+				`<a href="../notes/blog/tech/intro.html">Introduction to notes</a>`,
+				`<a href="../notes/memo/todo.html">Todo</a>`,
+				`<a href="../index.html">Index</a>`,
+			},
+		},
+		{
+			file: "notes/blog/tech/intro.html",
+			contains: []string{
+				`<h1>Introduction to notes</h1>`,
+				`<a href="../../../index.html#blog%2ftech">blog/tech</a>`,
+				`<a class="tag" href="../../../tags/go.html">go</a><a class="tag" href="../../../tags/cli.html">cli</a>`,
+				`Created: 2018-10-30T11:37:45`,
+				`<a href="../../memo/todo.html#today">my todo</a>`,
+				`<a href="https://example.com">website</a>`,
+				`<img src="img/logo.png" alt="logo">`,
+			},
+			excludes: []string{"- Category:", "- Tags:", "====="},
+		},
+		{
+			file: "notes/memo/todo.html",
+			contains: []string{
+				`<input disabled="" type="checkbox"> Write a blog post about <a href="../blog/tech/intro.html">intro</a>`,
+			},
+			excludes: []string{"- Category:", "-->"},
+		},
+		{
+			file:     "notes/blog/tech/img/logo.png",
+			contains: []string{"not a real png"},
+		},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			html := read(tc.file)
+			for _, s := range tc.contains {
+				if !strings.Contains(html, s) {
+					t.Errorf("%q is not contained in %s:\n%s", s, tc.file, html)
+				}
+			}
+			for _, s := range tc.excludes {
+				if strings.Contains(html, s) {
+					t.Errorf("%q should not be contained in %s:\n%s", s, tc.file, html)
+				}
+			}
+		})
+	}
+}
+
+func TestExportHTMLCmdTagFile(t *testing.T) {
+	for _, tc := range []struct {
+		tag  string
+		want string
+	}{
+		{"go", "go.html"},
+		{"a_b", "a_b.html"},
+		{"c/c++", "c_c++-14e5b7d4.html"},
+		{"a:b", "a_b-dcea6d9c.html"},
+	} {
+		if have := exportHTMLTagFile(tc.tag); have != tc.want {
+			t.Errorf("Tag file of %q should be %q but got %q", tc.tag, tc.want, have)
+		}
+	}
+
+	// Tags which are sanitized into the same name are written to different files
+	files := map[string]string{}
+	for _, tag := range []string{"a_b", "a/b", "a:b", "a\\b", "a*b", "a?b", "a|b"} {
+		f := exportHTMLTagFile(tag)
+		if other, ok := files[f]; ok {
+			t.Errorf("Tags %q and %q are written to the same file %q", other, tag, f)
+		}
+		files[f] = tag
+	}
+}
+
+func TestExportHTMLCmdError(t *testing.T) {
+	cmd := &ExportHTMLCmd{
+		Config: testExportConfig("not-existing"),
+		Dir:    "test-tmp-dir-export-html-error",
+		Out:    &bytes.Buffer{},
+	}
+	if err := cmd.Do(); err == nil {
+		t.Fatal("Error did not occur")
+	}
+}
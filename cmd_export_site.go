@@ -0,0 +1,282 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// exportSiteManifest is a file name to record files written by `notes export hugo` or `notes export jekyll`.
+// It is put in the output directory and used to remove files of deleted notes at next export
+const exportSiteManifest = ".notes-export"
+
+// ExportSiteCmd represents `notes export hugo` and `notes export jekyll` commands. Each public fields represent
+// options of the command. Out field represents where this command should output.
+type ExportSiteCmd struct {
+	cliHugo, cliJekyll *kingpin.CmdClause
+	Config             *Config
+	// Generator is a name of static site generator. One of "hugo" or "jekyll"
+	Generator string
+	// Category is a regex string equivalent to --category
+	Category string
+	// Tag is a regex string equivalent to --tag
+	Tag string
+	// ExcludeTag is a regex string equivalent to --exclude-tag
+	ExcludeTag string
+	// Dir is a path to content directory of the site. This value is equivalent to --out option
+	Dir string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *ExportSiteCmd) defineSiteCLI(c *kingpin.CmdClause) {
+	cat := c.Flag("category", "Publish only notes whose categories match to the regular expression. Default is 'export.category' config").Short('c')
+	tag := c.Flag("tag", "Publish only notes which have a tag matching to the regular expression. Default is 'export.tag' config").Short('t')
+	exclude := c.Flag("exclude-tag", "Do not publish notes which have a tag matching to the regular expression. Default is 'export.exclude_tag' config")
+	if cmd.Config != nil {
+		// Rules to publish notes can be configured in [export] section of config file
+		if cmd.Config.Export.Category != "" {
+			cat.Default(cmd.Config.Export.Category)
+		}
+		if cmd.Config.Export.Tag != "" {
+			tag.Default(cmd.Config.Export.Tag)
+		}
+		if cmd.Config.Export.ExcludeTag != "" {
+			exclude.Default(cmd.Config.Export.ExcludeTag)
+		}
+	}
+	cat.StringVar(&cmd.Category)
+	tag.StringVar(&cmd.Tag)
+	exclude.StringVar(&cmd.ExcludeTag)
+	c.Flag("out", "Content directory of the site. Only changed notes are written and files of removed notes are deleted").Short('o').Required().StringVar(&cmd.Dir)
+}
+
+func (cmd *ExportSiteCmd) defineCLI(app *kingpin.Application) {
+	export := exportCommand(app)
+	cmd.cliHugo = export.Command("hugo", "Export notes to content directory of Hugo. Metadata of notes is converted to front matter and categories are mapped to sections")
+	cmd.defineSiteCLI(cmd.cliHugo)
+	cmd.cliJekyll = export.Command("jekyll", "Export notes as posts of Jekyll into _posts directory. Metadata of notes is converted to front matter")
+	cmd.defineSiteCLI(cmd.cliJekyll)
+}
+
+func (cmd *ExportSiteCmd) matchesCmdline(cmdline string) bool {
+	switch cmdline {
+	case cmd.cliHugo.FullCommand():
+		cmd.Generator = "hugo"
+	case cmd.cliJekyll.FullCommand():
+		cmd.Generator = "jekyll"
+	default:
+		return false
+	}
+	return true
+}
+
+func yamlStrings(ss []string) string {
+	qs := make([]string, 0, len(ss))
+	for _, s := range ss {
+		qs = append(qs, strconv.Quote(s))
+	}
+	return "[" + strings.Join(qs, ", ") + "]"
+}
+
+// sitePath returns slash-separated relative path of the exported note from the output directory
+func (cmd *ExportSiteCmd) sitePath(note *Note) string {
+	if cmd.Generator == "jekyll" {
+		return path.Join("_posts", note.Created.Format("2006-01-02")+"-"+note.File)
+	}
+	return path.Join(note.Category, note.File)
+}
+
+func (cmd *ExportSiteCmd) render(note *Note) ([]byte, error) {
+	body, err := note.ReadBody()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	if cmd.Generator == "jekyll" {
+		b.WriteString("layout: post\n")
+	}
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(note.Title))
+	if cmd.Generator == "jekyll" {
+		fmt.Fprintf(&b, "date: %s\n", note.Created.Format("2006-01-02 15:04:05 -0700"))
+		fmt.Fprintf(&b, "categories: %s\n", yamlStrings(strings.Split(note.Category, "/")))
+	} else {
+		fmt.Fprintf(&b, "date: %s\n", note.Created.Format("2006-01-02T15:04:05Z07:00"))
+		fmt.Fprintf(&b, "categories: %s\n", yamlStrings([]string{note.Category}))
+	}
+	fmt.Fprintf(&b, "tags: %s\n", yamlStrings(note.Tags))
+	b.WriteString("---\n\n")
+	b.WriteString(body)
+	return b.Bytes(), nil
+}
+
+func (cmd *ExportSiteCmd) selectNotes() ([]*Note, error) {
+	catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var excludeReg *regexp.Regexp
+	if cmd.ExcludeTag != "" {
+		if excludeReg, err = regexp.Compile(cmd.ExcludeTag); err != nil {
+			return nil, errors.Wrap(err, "Regular expression for excluding tags is invalid")
+		}
+	}
+
+	notes, err := collectNotes(cmd.Config, catReg, tagReg)
+	if err != nil {
+		return nil, err
+	}
+	if excludeReg == nil {
+		return notes, nil
+	}
+
+	selected := make([]*Note, 0, len(notes))
+Notes:
+	for _, n := range notes {
+		for _, t := range n.Tags {
+			if excludeReg.MatchString(t) {
+				continue Notes
+			}
+		}
+		selected = append(selected, n)
+	}
+	return selected, nil
+}
+
+func (cmd *ExportSiteCmd) readManifest() ([]string, error) {
+	b, err := os.ReadFile(filepath.Join(cmd.Dir, exportSiteManifest))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Cannot read list of exported files")
+	}
+	paths := []string{}
+	for _, p := range strings.Split(string(b), "\n") {
+		// File names may contain spaces. Only one path is written per line
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		// Ignore broken entries so that files outside the output directory are never removed
+		if path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+func (cmd *ExportSiteCmd) writeManifest(paths []string) error {
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString(p + "\n")
+	}
+	err := os.WriteFile(filepath.Join(cmd.Dir, exportSiteManifest), []byte(b.String()), 0644)
+	return errors.Wrap(err, "Cannot write list of exported files")
+}
+
+// writeIfChanged writes the content to the file only when it is different from current content. It
+// returns true when the file was written
+func writeIfChanged(p string, content []byte) (bool, error) {
+	if b, err := os.ReadFile(p); err == nil && bytes.Equal(b, content) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return false, errors.Wrapf(err, "Cannot create directory for '%s'", canonPath(p))
+	}
+	if err := os.WriteFile(p, content, 0644); err != nil {
+		return false, errors.Wrapf(err, "Cannot write file '%s'", canonPath(p))
+	}
+	return true, nil
+}
+
+// removeExported removes the exported file and its parent directories which became empty
+func (cmd *ExportSiteCmd) removeExported(rel string) error {
+	p := filepath.Join(cmd.Dir, filepath.FromSlash(rel))
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "Cannot remove file of deleted note '%s'", canonPath(p))
+	}
+	for d := path.Dir(rel); d != "." && d != "/"; d = path.Dir(d) {
+		// Fails when the directory is not empty
+		if os.Remove(filepath.Join(cmd.Dir, filepath.FromSlash(d))) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Do runs `notes export hugo` or `notes export jekyll` command and returns an error if occurs
+func (cmd *ExportSiteCmd) Do() error {
+	notes, err := cmd.selectNotes()
+	if err != nil {
+		return err
+	}
+
+	prev, err := cmd.readManifest()
+	if err != nil {
+		return err
+	}
+
+	exported := make(map[string]*Note, len(notes))
+	paths := make([]string, 0, len(notes))
+	written, unchanged, removed := 0, 0, 0
+	for _, n := range notes {
+		rel := cmd.sitePath(n)
+		if other, ok := exported[rel]; ok {
+			return errors.Errorf("Notes '%s' and '%s' are exported to the same file '%s'", other.RelFilePath(), n.RelFilePath(), rel)
+		}
+		exported[rel] = n
+		paths = append(paths, rel)
+
+		content, err := cmd.render(n)
+		if err != nil {
+			return err
+		}
+		ok, err := writeIfChanged(filepath.Join(cmd.Dir, filepath.FromSlash(rel)), content)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			unchanged++
+			continue
+		}
+		fmt.Fprintf(cmd.Out, "Wrote %s\n", rel)
+		written++
+	}
+
+	for _, rel := range prev {
+		if _, ok := exported[rel]; ok {
+			continue
+		}
+		if err := cmd.removeExported(rel); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Out, "Removed %s\n", rel)
+		removed++
+	}
+
+	if err := os.MkdirAll(cmd.Dir, 0755); err != nil {
+		return errors.Wrapf(err, "Cannot create output directory '%s'", canonPath(cmd.Dir))
+	}
+	if err := cmd.writeManifest(paths); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Out, "Exported %d notes to %s: %d written, %d unchanged, %d removed\n", len(notes), canonPath(cmd.Dir), written, unchanged, removed)
+	return nil
+}
@@ -0,0 +1,222 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testExportSiteHome(name string) (*Config, string) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	root := filepath.Join(cwd, name)
+	cfg := &Config{HomePath: filepath.Join(root, "home")}
+	writeTestNote(cfg, "blog/tech/intro.md", `Introduction to notes
+=====================
+- Category: blog/tech
+- Tags: go, cli
+- Created: 2018-10-30T11:37:45+09:00
+
+Hello, world
+`)
+	writeTestNote(cfg, "blog/draft.md", `Draft
+=====
+- Category: blog
+- Tags: draft
+- Created: 2018-11-01T09:00:00+09:00
+
+WIP
+`)
+	writeTestNote(cfg, "memo/todo.md", `Todo
+====
+- Category: memo
+- Tags:
+- Created: 2018-11-02T09:00:00+09:00
+
+- [ ] Write blog
+`)
+	return cfg, filepath.Join(root, "content")
+}
+
+func exportSiteForTest(t *testing.T, cmd *ExportSiteCmd) string {
+	var buf bytes.Buffer
+	cmd.Out = &buf
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestExportSiteCmdHugo(t *testing.T) {
+	cfg, out := testExportSiteHome("test-tmp-dir-export-hugo")
+	defer func() { panicIfErr(os.RemoveAll(filepath.Dir(out))) }()
+
+	cmd := &ExportSiteCmd{
+		Config:     cfg,
+		Generator:  "hugo",
+		Category:   "^blog",
+		ExcludeTag: "^draft$",
+		Dir:        out,
+	}
+
+	stdout := exportSiteForTest(t, cmd)
+	if !strings.Contains(stdout, "Wrote blog/tech/intro.md\n") || !strings.Contains(stdout, "1 written, 0 unchanged, 0 removed") {
+		t.Fatal("Unexpected output:", stdout)
+	}
+
+	b, err := os.ReadFile(filepath.Join(out, "blog", "tech", "intro.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+title: "Introduction to notes"
+date: 2018-10-30T11:37:45+09:00
+categories: ["blog/tech"]
+tags: ["go", "cli"]
+---
+
+Hello, world
+`
+	if string(b) != want {
+		t.Fatalf("Unexpected content:\n%s\nwant:\n%s", b, want)
+	}
+
+	for _, p := range []string{filepath.Join(out, "blog", "draft.md"), filepath.Join(out, "memo")} {
+		if _, err := os.Stat(p); err == nil {
+			t.Fatal(p, "should not be exported")
+		}
+	}
+
+	// Nothing is written when no note was changed
+	stdout = exportSiteForTest(t, cmd)
+	if !strings.HasSuffix(stdout, "0 written, 1 unchanged, 0 removed\n") || strings.Contains(stdout, "Wrote") {
+		t.Fatal("Unexpected output at second export:", stdout)
+	}
+
+	// File of deleted note is removed
+	panicIfErr(os.Remove(filepath.Join(cfg.HomePath, "blog", "tech", "intro.md")))
+	stdout = exportSiteForTest(t, cmd)
+	if !strings.Contains(stdout, "Removed blog/tech/intro.md\n") {
+		t.Fatal("Unexpected output after deleting note:", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(out, "blog")); err == nil {
+		t.Fatal("Empty directory was not removed")
+	}
+}
+
+func TestExportSiteCmdFileNameWithSpace(t *testing.T) {
+	cfg, out := testExportSiteHome("test-tmp-dir-export-space")
+	defer func() { panicIfErr(os.RemoveAll(filepath.Dir(out))) }()
+	writeTestNote(cfg, "blog/my note.md", `My note
+=======
+- Category: blog
+- Tags:
+- Created: 2018-11-03T09:00:00+09:00
+
+Hello
+`)
+	// File whose name is a part of the note's file name must not be removed
+	panicIfErr(os.MkdirAll(filepath.Join(out, "blog"), 0755))
+	panicIfErr(os.WriteFile(filepath.Join(out, "blog", "my"), []byte("keep"), 0644))
+
+	cmd := &ExportSiteCmd{Config: cfg, Generator: "hugo", Category: "^blog$", ExcludeTag: "^draft$", Dir: out}
+	if stdout := exportSiteForTest(t, cmd); !strings.Contains(stdout, "Wrote blog/my note.md\n") {
+		t.Fatal("Unexpected output:", stdout)
+	}
+
+	panicIfErr(os.Remove(filepath.Join(cfg.HomePath, "blog", "my note.md")))
+	if stdout := exportSiteForTest(t, cmd); !strings.Contains(stdout, "Removed blog/my note.md\n") || !strings.Contains(stdout, "1 removed") {
+		t.Fatal("Unexpected output after deleting note:", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(out, "blog", "my note.md")); err == nil {
+		t.Fatal("File of deleted note was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(out, "blog", "my")); err != nil {
+		t.Fatal("Unrelated file was removed:", err)
+	}
+}
+
+func TestExportSiteCmdJekyll(t *testing.T) {
+	cfg, out := testExportSiteHome("test-tmp-dir-export-jekyll")
+	defer func() { panicIfErr(os.RemoveAll(filepath.Dir(out))) }()
+
+	// File not written by notes is kept
+	panicIfErr(os.MkdirAll(filepath.Join(out, "_posts"), 0755))
+	mine := filepath.Join(out, "_posts", "2018-01-01-mine.md")
+	panicIfErr(os.WriteFile(mine, []byte("mine"), 0644))
+
+	cmd := &ExportSiteCmd{
+		Config:    cfg,
+		Generator: "jekyll",
+		Tag:       "go",
+		Dir:       out,
+	}
+	exportSiteForTest(t, cmd)
+
+	b, err := os.ReadFile(filepath.Join(out, "_posts", "2018-10-30-intro.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+layout: post
+title: "Introduction to notes"
+date: 2018-10-30 11:37:45 +0900
+categories: ["blog", "tech"]
+tags: ["go", "cli"]
+---
+
+Hello, world
+`
+	if string(b) != want {
+		t.Fatalf("Unexpected content:\n%s\nwant:\n%s", b, want)
+	}
+
+	cmd.Tag = "^not-existing$"
+	stdout := exportSiteForTest(t, cmd)
+	if !strings.Contains(stdout, "Removed _posts/2018-10-30-intro.md\n") {
+		t.Fatal("Unexpected output:", stdout)
+	}
+	if _, err := os.Stat(mine); err != nil {
+		t.Fatal("File which was not exported by notes was removed:", err)
+	}
+}
+
+func TestExportSiteCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		what string
+		cmd  *ExportSiteCmd
+		want string
+	}{
+		{
+			what: "invalid category regex",
+			cmd:  &ExportSiteCmd{Category: "(foo"},
+			want: "Regular expression for filtering categories is invalid",
+		},
+		{
+			what: "invalid tag regex",
+			cmd:  &ExportSiteCmd{Tag: "(foo"},
+			want: "Regular expression for filtering tags is invalid",
+		},
+		{
+			what: "invalid exclude regex",
+			cmd:  &ExportSiteCmd{ExcludeTag: "(foo"},
+			want: "Regular expression for excluding tags is invalid",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			tc.cmd.Config = testExportConfig("normal")
+			tc.cmd.Generator = "hugo"
+			tc.cmd.Dir = "test-tmp-dir-export-site-error"
+			tc.cmd.Out = &bytes.Buffer{}
+			err := tc.cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
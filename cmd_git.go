@@ -0,0 +1,76 @@
+package notes
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// GitCmd represents `notes git` command. Each public fields represent options of the command.
+// In and Out fields represent where the Git command should input and output.
+type GitCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Args is arguments passed to Git command as-is. The first argument is a Git subcommand
+	Args []string
+	// In is a reader passed to stdin of Git command. Kind of stdin is expected
+	In io.Reader
+	// Out is a writer passed to stdout of Git command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *GitCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("git", "Run Git command at home directory. All arguments are passed to Git as-is. e.g. 'notes git log --oneline' is equivalent to 'git -C $(notes config home) log --oneline'")
+	cmd.cli.Arg("args", "Git subcommand and its arguments").StringsVar(&cmd.Args)
+}
+
+func (cmd *GitCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes git` command and returns an error if occurs
+func (cmd *GitCmd) Do() error {
+	git := NewGit(cmd.Config)
+	if git == nil {
+		return errors.New("'git' command cannot work without Git. Please check Git command listed in output of 'config' command is available")
+	}
+	if len(cmd.Args) == 0 {
+		return errors.New("Git subcommand is not given. Please specify it like 'notes git status'")
+	}
+
+	c := git.Command(cmd.Args[0], cmd.Args[1:]...)
+	c.Stdin = cmd.In
+	c.Stdout = cmd.Out
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return errors.Wrapf(err, "Git command 'git %s' did not exit successfully", strings.Join(cmd.Args, " "))
+	}
+	return nil
+}
+
+// passthroughGitArgs inserts "--" after `git` subcommand in given command line arguments so that
+// options for Git such as `--oneline` are not parsed as options of notes
+func passthroughGitArgs(args []string) []string {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "-n" || a == "--notebook" {
+			// Skip value of global --notebook option
+			i++
+			continue
+		}
+		if strings.HasPrefix(a, "-") {
+			continue
+		}
+		if a != "git" {
+			return args
+		}
+		ret := make([]string, 0, len(args)+1)
+		ret = append(ret, args[:i+1]...)
+		ret = append(ret, "--")
+		return append(ret, args[i+1:]...)
+	}
+	return args
+}
@@ -0,0 +1,64 @@
+package notes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// GitSetupCmd represents `notes git-setup` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type GitSetupCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Attributes is a flag equivalent to --attributes
+	Attributes bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *GitSetupCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("git-setup", "Set up Git repository at home. Default .gitignore (and .gitattributes when 'gitattributes' config is enabled) is written. Existing entries in the files are kept. Git repository is created if it does not exist yet")
+	cmd.cli.Flag("attributes", "Write .gitattributes for Markdown diffs even if 'gitattributes' config is not enabled").BoolVar(&cmd.Attributes)
+}
+
+func (cmd *GitSetupCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes git-setup` command and returns an error if occurs
+func (cmd *GitSetupCmd) Do() error {
+	git := NewGit(cmd.Config)
+	if git == nil {
+		return errors.New("'git-setup' command cannot work without Git. Please check Git command listed in output of 'config' command is available")
+	}
+	if cmd.Attributes {
+		git.attributes = true
+	}
+
+	if _, err := os.Stat(filepath.Join(cmd.Config.HomePath, ".git")); err != nil {
+		if err := git.Init(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.Out, "Initialized Git repository at %s\n", canonPath(cmd.Config.HomePath))
+		return nil
+	}
+
+	updated, err := git.Setup()
+	if err != nil {
+		return err
+	}
+
+	if len(updated) == 0 {
+		fmt.Fprintln(cmd.Out, "Git repository is already set up")
+		return nil
+	}
+	for _, p := range updated {
+		fmt.Fprintf(cmd.Out, "Updated %s\n", canonPath(p))
+	}
+	return nil
+}
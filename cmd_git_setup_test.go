@@ -0,0 +1,77 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitSetupCmdInit(t *testing.T) {
+	dir := "test-tmp-dir-git-setup-cmd-init"
+	panicIfErr(os.Mkdir(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	var buf bytes.Buffer
+	cmd := &GitSetupCmd{
+		Config:     &Config{GitPath: "git", HomePath: dir},
+		Attributes: true,
+		Out:        &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := buf.String(); !strings.HasPrefix(out, "Initialized Git repository at ") {
+		t.Fatal("Unexpected output:", out)
+	}
+	for _, f := range []string{".git", ".gitignore", ".gitattributes"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Fatal(f, "was not created:", err)
+		}
+	}
+}
+
+func TestGitSetupCmdExistingRepo(t *testing.T) {
+	dir := "test-tmp-dir-git-setup-cmd-existing"
+	panicIfErr(os.Mkdir(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cfg := &Config{GitPath: "git", HomePath: dir}
+	out, err := NewGit(cfg).Exec("init")
+	if err != nil {
+		t.Fatal(err, out)
+	}
+
+	var buf bytes.Buffer
+	cmd := &GitSetupCmd{Config: cfg, Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "Updated ") || !strings.HasSuffix(out, ".gitignore\n") {
+		t.Fatal("Unexpected output:", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitattributes")); err == nil {
+		t.Fatal(".gitattributes should not be created without --attributes")
+	}
+
+	buf.Reset()
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); out != "Git repository is already set up\n" {
+		t.Fatal("Unexpected output:", out)
+	}
+}
+
+func TestGitSetupCmdNoGit(t *testing.T) {
+	cmd := &GitSetupCmd{Config: &Config{HomePath: "."}, Out: &bytes.Buffer{}}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "'git-setup' command cannot work without Git") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,117 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGitCmd(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-git-cmd")
+	defer repos.cleanup()
+
+	cfg := repos.clone("a")
+	writeTestNote(cfg, "memo/foo.md", testSyncNote)
+
+	var buf bytes.Buffer
+	cmd := &GitCmd{
+		Config: cfg,
+		Args:   []string{"status", "--porcelain"},
+		In:     os.Stdin,
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); out != "?? memo/\n" {
+		t.Fatalf("Unexpected output: %q", out)
+	}
+
+	buf.Reset()
+	cmd.In = strings.NewReader(testSyncNote)
+	cmd.Args = []string{"hash-object", "--stdin"}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	want, err := NewGit(cfg).Exec("hash-object", "memo/foo.md")
+	panicIfErr(err)
+	if out := buf.String(); out != want+"\n" {
+		t.Fatal("Stdin was not passed to Git:", out)
+	}
+}
+
+func TestGitCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		what string
+		cfg  *Config
+		args []string
+		want string
+	}{
+		{
+			what: "no git",
+			cfg:  &Config{HomePath: "."},
+			args: []string{"status"},
+			want: "'git' command cannot work without Git",
+		},
+		{
+			what: "no subcommand",
+			cfg:  &Config{GitPath: "git", HomePath: "."},
+			args: []string{},
+			want: "Git subcommand is not given",
+		},
+		{
+			what: "git fails",
+			cfg:  &Config{GitPath: "git", HomePath: "."},
+			args: []string{"hoge-fuga"},
+			want: "Git command 'git hoge-fuga' did not exit successfully",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			cmd := &GitCmd{Config: tc.cfg, Args: tc.args, Out: &bytes.Buffer{}}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestPassthroughGitArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want []string
+	}{
+		{
+			args: []string{"git", "log", "--oneline"},
+			want: []string{"git", "--", "log", "--oneline"},
+		},
+		{
+			args: []string{"-A", "--notebook", "git", "git", "diff"},
+			want: []string{"-A", "--notebook", "git", "git", "--", "diff"},
+		},
+		{
+			args: []string{"-n", "work", "git", "status"},
+			want: []string{"-n", "work", "git", "--", "status"},
+		},
+		{
+			args: []string{"list", "git", "--oneline"},
+			want: []string{"list", "git", "--oneline"},
+		},
+		{
+			args: []string{"--no-color"},
+			want: []string{"--no-color"},
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			have := passthroughGitArgs(tc.args)
+			if !reflect.DeepEqual(have, tc.want) {
+				t.Fatal("Unexpected arguments:", have)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// graphNode is a node of graph output by `notes graph`. Its kind is one of "note", "category" or "tag".
+// Title, Created and Path are set only for notes
+type graphNode struct {
+	ID      string     `json:"id"`
+	Kind    string     `json:"kind"`
+	Label   string     `json:"label"`
+	Path    string     `json:"path,omitempty"`
+	Title   string     `json:"title,omitempty"`
+	Created *time.Time `json:"created,omitempty"`
+}
+
+// graphEdge is an edge of graph output by `notes graph`. Its kind is one of "category" (note to its
+// category), "tag" (note to its tag) or "link" (note to linked note)
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// graph is a graph of notes, categories, tags and links between notes
+type graph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []*graphEdge `json:"edges"`
+}
+
+// GraphCmd represents `notes graph` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type GraphCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Category is a regex string equivalent to --cateogry
+	Category string
+	// Tag is a regex string equivalent to --tag
+	Tag string
+	// Format is a format of the graph. One of "dot" or "json". Empty means "dot"
+	Format string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *GraphCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("graph", "Output graph of notes, categories, tags and [[link]] between notes in Graphviz DOT or JSON format. Notes can be filtered as 'list' command")
+	cmd.cli.Flag("category", "Filter notes by category name with regular expression").Short('c').StringVar(&cmd.Category)
+	cmd.cli.Flag("tag", "Filter notes by tag name with regular expression").Short('t').StringVar(&cmd.Tag)
+	cmd.cli.Flag("format", "Format of the graph. 'dot' or 'json'").Short('f').Default("dot").EnumVar(&cmd.Format, "dot", "json")
+}
+
+func (cmd *GraphCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func graphNoteID(note *Note) string {
+	return "note:" + filepath.ToSlash(note.RelFilePath())
+}
+
+// build builds a graph of filtered notes. Links are resolved with all notes in home, but only links
+// between filtered notes are included in the graph
+func (cmd *GraphCmd) build() (*graph, error) {
+	catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := collectNoteLinkIndex(cmd.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := collectNotes(cmd.Config, catReg, tagReg)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].RelFilePath() < notes[j].RelFilePath()
+	})
+
+	included := make(map[string]struct{}, len(notes))
+	for _, n := range notes {
+		included[n.RelFilePath()] = struct{}{}
+	}
+
+	g := &graph{Nodes: []*graphNode{}, Edges: []*graphEdge{}}
+	cats, tags := map[string]struct{}{}, map[string]struct{}{}
+	for _, n := range notes {
+		created := n.Created
+		g.Nodes = append(g.Nodes, &graphNode{
+			ID:      graphNoteID(n),
+			Kind:    "note",
+			Label:   n.Title,
+			Path:    filepath.ToSlash(n.RelFilePath()),
+			Title:   n.Title,
+			Created: &created,
+		})
+		cats[n.Category] = struct{}{}
+		g.Edges = append(g.Edges, &graphEdge{graphNoteID(n), "category:" + n.Category, "category"})
+		for _, t := range n.Tags {
+			tags[t] = struct{}{}
+			g.Edges = append(g.Edges, &graphEdge{graphNoteID(n), "tag:" + t, "tag"})
+		}
+
+		links, err := n.links()
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]struct{}{}
+		for _, l := range links {
+			dest := idx.resolve(l.Target)
+			if dest == nil || dest.RelFilePath() == n.RelFilePath() {
+				continue
+			}
+			if _, ok := included[dest.RelFilePath()]; !ok {
+				continue
+			}
+			id := graphNoteID(dest)
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			g.Edges = append(g.Edges, &graphEdge{graphNoteID(n), id, "link"})
+		}
+	}
+
+	for _, c := range sortedKeys(cats) {
+		g.Nodes = append(g.Nodes, &graphNode{ID: "category:" + c, Kind: "category", Label: c})
+	}
+	for _, t := range sortedKeys(tags) {
+		g.Nodes = append(g.Nodes, &graphNode{ID: "tag:" + t, Kind: "tag", Label: "#" + t})
+	}
+
+	return g, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// dotQuote quotes the string as ID or attribute value of DOT language
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+var graphDotShapes = map[string]string{
+	"note":     "box",
+	"category": "folder",
+	"tag":      "ellipse",
+}
+
+func (cmd *GraphCmd) writeDot(g *graph) error {
+	var b bytes.Buffer
+	b.WriteString("digraph notes {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s, kind=%s", dotQuote(n.ID), dotQuote(n.Label), graphDotShapes[n.Kind], dotQuote(n.Kind))
+		if n.Kind == "note" {
+			fmt.Fprintf(&b, ", path=%s, title=%s, created=%s", dotQuote(n.Path), dotQuote(n.Title), dotQuote(n.Created.Format(time.RFC3339)))
+		}
+		b.WriteString("];\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [kind=%s", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Kind))
+		if e.Kind != "link" {
+			b.WriteString(", style=dashed")
+		}
+		b.WriteString("];\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := cmd.Out.Write(b.Bytes())
+	return errors.Wrap(err, "Cannot write graph in DOT format")
+}
+
+func (cmd *GraphCmd) writeJSON(g *graph) error {
+	enc := json.NewEncoder(cmd.Out)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(g), "Cannot write graph as JSON")
+}
+
+// Do runs `notes graph` command and returns an error if occurs
+func (cmd *GraphCmd) Do() error {
+	g, err := cmd.build()
+	if err != nil {
+		return err
+	}
+	if cmd.Format == "json" {
+		return cmd.writeJSON(g)
+	}
+	return cmd.writeDot(g)
+}
@@ -0,0 +1,143 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGraphCmdDot(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &GraphCmd{Config: testLinksConfig(), Format: "dot", Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph notes {
+  "note:blog/c.md" [label="Note C", shape=box, kind="note", path="blog/c.md", title="Note C", created="2018-11-01T11:37:45+09:00"];
+  "note:memo/a.md" [label="A", shape=box, kind="note", path="memo/a.md", title="A", created="2018-10-30T11:37:45+09:00"];
+  "note:memo/b.md" [label="B", shape=box, kind="note", path="memo/b.md", title="B", created="2018-10-31T11:37:45+09:00"];
+  "category:blog" [label="blog", shape=folder, kind="category"];
+  "category:memo" [label="memo", shape=folder, kind="category"];
+  "tag:wiki" [label="#wiki", shape=ellipse, kind="tag"];
+  "note:blog/c.md" -> "category:blog" [kind="category", style=dashed];
+  "note:blog/c.md" -> "tag:wiki" [kind="tag", style=dashed];
+  "note:memo/a.md" -> "category:memo" [kind="category", style=dashed];
+  "note:memo/a.md" -> "tag:wiki" [kind="tag", style=dashed];
+  "note:memo/a.md" -> "note:memo/b.md" [kind="link"];
+  "note:memo/a.md" -> "note:blog/c.md" [kind="link"];
+  "note:memo/b.md" -> "category:memo" [kind="category", style=dashed];
+  "note:memo/b.md" -> "note:memo/a.md" [kind="link"];
+}
+`
+	if have := buf.String(); have != want {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestGraphCmdJSON(t *testing.T) {
+	for _, tc := range []struct {
+		what     string
+		category string
+		tag      string
+		nodes    []string
+		edges    []string
+	}{
+		{
+			what:  "all",
+			nodes: []string{"note:blog/c.md", "note:memo/a.md", "note:memo/b.md", "category:blog", "category:memo", "tag:wiki"},
+			edges: []string{
+				"note:blog/c.md category:blog category",
+				"note:blog/c.md tag:wiki tag",
+				"note:memo/a.md category:memo category",
+				"note:memo/a.md tag:wiki tag",
+				"note:memo/a.md note:memo/b.md link",
+				"note:memo/a.md note:blog/c.md link",
+				"note:memo/b.md category:memo category",
+				"note:memo/b.md note:memo/a.md link",
+			},
+		},
+		{
+			what:     "category",
+			category: "^memo$",
+			nodes:    []string{"note:memo/a.md", "note:memo/b.md", "category:memo", "tag:wiki"},
+			edges: []string{
+				"note:memo/a.md category:memo category",
+				"note:memo/a.md tag:wiki tag",
+				"note:memo/a.md note:memo/b.md link",
+				"note:memo/b.md category:memo category",
+				"note:memo/b.md note:memo/a.md link",
+			},
+		},
+		{
+			what:  "tag",
+			tag:   "^wiki$",
+			nodes: []string{"note:blog/c.md", "note:memo/a.md", "category:blog", "category:memo", "tag:wiki"},
+			edges: []string{
+				"note:blog/c.md category:blog category",
+				"note:blog/c.md tag:wiki tag",
+				"note:memo/a.md category:memo category",
+				"note:memo/a.md tag:wiki tag",
+				"note:memo/a.md note:blog/c.md link",
+			},
+		},
+		{
+			what:     "no note",
+			category: "^nothing$",
+			nodes:    []string{},
+			edges:    []string{},
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &GraphCmd{Config: testLinksConfig(), Category: tc.category, Tag: tc.tag, Format: "json", Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			var g graph
+			if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+				t.Fatal(err, buf.String())
+			}
+
+			nodes := []string{}
+			for _, n := range g.Nodes {
+				nodes = append(nodes, n.ID)
+				if n.Kind == "note" && (n.Title == "" || n.Created == nil || n.Path == "") {
+					t.Error("Attributes of note are missing:", n)
+				}
+			}
+			if !cmp.Equal(tc.nodes, nodes) {
+				t.Error(cmp.Diff(tc.nodes, nodes))
+			}
+
+			edges := []string{}
+			for _, e := range g.Edges {
+				edges = append(edges, e.From+" "+e.To+" "+e.Kind)
+			}
+			if !cmp.Equal(tc.edges, edges) {
+				t.Error(cmp.Diff(tc.edges, edges))
+			}
+		})
+	}
+}
+
+func TestGraphCmdInvalidFilter(t *testing.T) {
+	cmd := &GraphCmd{Config: testLinksConfig(), Category: "(", Out: &bytes.Buffer{}}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Regular expression for filtering categories is invalid") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestDotQuote(t *testing.T) {
+	if have := dotQuote("say \"hi\" \\ bye\n"); have != `"say \"hi\" \\ bye\n"` {
+		t.Fatal("Unexpected quoted string:", have)
+	}
+}
@@ -0,0 +1,107 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// importedCategory is a default category for imported notes whose categories cannot be determined
+const importedCategory = "imported"
+
+// importCommand returns `notes import` command which is a parent of all import subcommands. It is
+// defined at first call
+func importCommand(app *kingpin.Application) *kingpin.CmdClause {
+	if c := app.GetCommand("import"); c != nil {
+		return c
+	}
+	return app.Command("import", "Import notes from other formats or tools")
+}
+
+// createImportedNote creates a file of the imported note with its title, metadata and the body. Unlike
+// Note.Create(), template is never inserted since the body is imported from other tool. This function
+// fails when the file already exists
+func createImportedNote(note *Note, body string) error {
+	var b bytes.Buffer
+	note.writeHeader(&b, false)
+	b.WriteRune('\n')
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString(body + "\n")
+	}
+	return note.createFile(b.Bytes())
+}
+
+// resolveImportConflict returns the note to write considering the conflict policy ("skip", "overwrite" or
+// "rename") when a file of the note already exists. It returns nil when the note should be skipped. The
+// second return value is true when the existing note should be overwritten. Existing file is not touched
+// here so that it is kept when importing the note fails. written is a set of file paths of notes already
+// imported in the same run. Such notes are never overwritten since they were imported from other sources
+func resolveImportConflict(note *Note, policy string, written map[string]struct{}) (*Note, bool) {
+	exists := func(n *Note) bool {
+		if _, ok := written[n.FilePath()]; ok {
+			return true
+		}
+		_, err := os.Stat(n.FilePath())
+		return err == nil
+	}
+
+	if !exists(note) {
+		return note, false
+	}
+
+	switch policy {
+	case "overwrite":
+		if _, ok := written[note.FilePath()]; !ok {
+			return note, true
+		}
+		fallthrough
+	case "rename":
+		base := strings.TrimSuffix(note.File, ".md")
+		for i := 1; ; i++ {
+			n := *note
+			n.File = base + "-" + strconv.Itoa(i) + ".md"
+			if !exists(&n) {
+				return &n, false
+			}
+		}
+	default:
+		return nil, false
+	}
+}
+
+// writeImportedNote creates a file of the imported note with the body. When overwrite is true, the note
+// is written to a temporary file in the same directory at first and the existing file is replaced with it
+// only when writing succeeded
+func writeImportedNote(note *Note, body string, overwrite bool) error {
+	if !overwrite {
+		return createImportedNote(note, body)
+	}
+
+	f, err := os.CreateTemp(note.DirPath(), "."+note.File+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "Cannot create temporary file to overwrite note '%s'", note.RelFilePath())
+	}
+	tmp := f.Name()
+	f.Close()
+	// createImportedNote() fails when the file already exists
+	if err := os.Remove(tmp); err != nil {
+		return errors.Wrap(err, "Cannot remove temporary file")
+	}
+
+	n := *note
+	n.File = filepath.Base(tmp)
+	if err := createImportedNote(&n, body); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, note.FilePath()); err != nil {
+		os.Remove(tmp)
+		return errors.Wrapf(err, "Cannot overwrite note '%s'", note.RelFilePath())
+	}
+	return nil
+}
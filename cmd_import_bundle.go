@@ -0,0 +1,111 @@
+package notes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// ImportBundleCmd represents `notes import bundle` command. Each public fields represent options of the command.
+// In and Out fields represent where this command should input and output.
+type ImportBundleCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// File is a path to JSON bundle written by `notes export bundle --format json`. "-" means In
+	File string
+	// In is a reader to read the bundle when File is "-". Kind of stdin is expected
+	In io.Reader
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *ImportBundleCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = importCommand(app).Command("bundle", "Import notes from JSON bundle written by 'export bundle --format json'. Notes which already exist in home are skipped")
+	cmd.cli.Arg("file", "Path to JSON bundle. '-' reads it from stdin").Required().StringVar(&cmd.File)
+}
+
+func (cmd *ImportBundleCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *ImportBundleCmd) readBundle() (*bundle, error) {
+	r := cmd.In
+	if cmd.File != "-" {
+		f, err := os.Open(cmd.File)
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot open bundle file")
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var b bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, errors.Wrap(err, "Cannot parse bundle. Only JSON bundle written by 'export bundle --format json' can be imported")
+	}
+	if b.Version != bundleVersion {
+		return nil, errors.Errorf("Unsupported version of bundle: %d. Version %d is supported", b.Version, bundleVersion)
+	}
+	return &b, nil
+}
+
+// validateBundleNote returns an error when the note in bundle cannot be imported to its path
+func validateBundleNote(n *bundleNote) error {
+	p := n.Path
+	if p == "" || path.IsAbs(p) || path.Clean(p) != p || strings.HasPrefix(p, "../") || !strings.HasSuffix(p, ".md") {
+		return errors.Errorf("Invalid path of note '%s' in bundle. It must be a relative path from home ending with '.md'", p)
+	}
+	if path.Dir(p) != n.Category {
+		return errors.Errorf("Category '%s' does not match to path of note '%s' in bundle", n.Category, p)
+	}
+	// Hidden directories such as .git must not be written
+	for _, part := range strings.Split(n.Category, "/") {
+		if err := validateDirname(part); err != nil {
+			return errors.Wrapf(err, "Invalid category part '%s' of note '%s' in bundle as directory name", part, p)
+		}
+	}
+	if strings.HasPrefix(path.Base(p), ".") {
+		return errors.Errorf("File name of note '%s' in bundle cannot start with '.'", p)
+	}
+	return nil
+}
+
+// Do runs `notes import bundle` command and returns an error if occurs
+func (cmd *ImportBundleCmd) Do() error {
+	b, err := cmd.readBundle()
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	for _, n := range b.Notes {
+		if err := validateBundleNote(n); err != nil {
+			return err
+		}
+
+		dest := filepath.Join(cmd.Config.HomePath, filepath.FromSlash(n.Path))
+		if _, err := os.Stat(dest); err == nil {
+			fmt.Fprintf(cmd.Out, "Skipped %s: already exists\n", n.Path)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return errors.Wrapf(err, "Could not create category directory for '%s'", n.Path)
+		}
+		if err := os.WriteFile(dest, []byte(n.Content), 0644); err != nil {
+			return errors.Wrapf(err, "Cannot write note '%s'", n.Path)
+		}
+		fmt.Fprintf(cmd.Out, "Imported %s\n", n.Path)
+		imported++
+	}
+
+	fmt.Fprintf(cmd.Out, "Imported %d notes to %s\n", imported, canonPath(cmd.Config.HomePath))
+	return nil
+}
@@ -0,0 +1,139 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportBundleCmdRoundTrip(t *testing.T) {
+	dir := "test-tmp-dir-import-bundle"
+	panicIfErr(os.MkdirAll(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	src := testExportConfig("normal")
+	var bundle bytes.Buffer
+	export := &ExportBundleCmd{Config: src, Format: "json", Out: &bundle}
+	if err := export.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	dst := &Config{HomePath: filepath.Join(cwd, dir, "home")}
+
+	var buf bytes.Buffer
+	cmd := &ImportBundleCmd{
+		Config: dst,
+		File:   "-",
+		In:     bytes.NewReader(bundle.Bytes()),
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Imported memo/todo.md\n", "Imported blog/tech/intro.md\n", "Imported 2 notes to "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not in output: %s", want, out)
+		}
+	}
+
+	for _, rel := range []string{"memo/todo.md", "blog/tech/intro.md"} {
+		want, err := os.ReadFile(filepath.Join(src.HomePath, filepath.FromSlash(rel)))
+		panicIfErr(err)
+		have, err := os.ReadFile(filepath.Join(dst.HomePath, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatal(rel, "was not imported:", err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Errorf("Content of %s was changed:\n%s", rel, have)
+		}
+	}
+
+	notes, err := collectNotes(dst, nil, nil)
+	if err != nil {
+		t.Fatal("Imported notes cannot be loaded:", err)
+	}
+	if len(notes) != 2 {
+		t.Fatal("Unexpected number of imported notes:", len(notes))
+	}
+
+	// Importing again skips existing notes
+	buf.Reset()
+	cmd.In = bytes.NewReader(bundle.Bytes())
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Skipped memo/todo.md: already exists\n") || !strings.Contains(out, "Imported 0 notes") {
+		t.Fatal("Unexpected output:", out)
+	}
+}
+
+func TestImportBundleCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "not json",
+			input: "# Notes\n",
+			want:  "Only JSON bundle written by 'export bundle --format json' can be imported",
+		},
+		{
+			what:  "unknown version",
+			input: `{"version": 100, "notes": []}`,
+			want:  "Unsupported version of bundle: 100",
+		},
+		{
+			what:  "path outside home",
+			input: `{"version": 1, "notes": [{"path": "../foo.md", "category": ".."}]}`,
+			want:  "Invalid path of note '../foo.md'",
+		},
+		{
+			what:  "not markdown",
+			input: `{"version": 1, "notes": [{"path": "memo/foo.txt", "category": "memo"}]}`,
+			want:  "Invalid path of note 'memo/foo.txt'",
+		},
+		{
+			what:  "category mismatch",
+			input: `{"version": 1, "notes": [{"path": "memo/foo.md", "category": "blog"}]}`,
+			want:  "Category 'blog' does not match to path of note 'memo/foo.md'",
+		},
+		{
+			what:  "hidden category",
+			input: `{"version": 1, "notes": [{"path": ".git/hooks/post-commit.md", "category": ".git/hooks"}]}`,
+			want:  "Invalid category part '.git' of note '.git/hooks/post-commit.md'",
+		},
+		{
+			what:  "hidden nested category",
+			input: `{"version": 1, "notes": [{"path": "memo/.obsidian/foo.md", "category": "memo/.obsidian"}]}`,
+			want:  "Invalid category part '.obsidian' of note 'memo/.obsidian/foo.md'",
+		},
+		{
+			what:  "hidden file",
+			input: `{"version": 1, "notes": [{"path": "memo/.foo.md", "category": "memo"}]}`,
+			want:  "File name of note 'memo/.foo.md' in bundle cannot start with '.'",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			cmd := &ImportBundleCmd{
+				Config: &Config{HomePath: "/path/to/not/existing/home"},
+				File:   "-",
+				In:     strings.NewReader(tc.input),
+				Out:    &bytes.Buffer{},
+			}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// Hashtag like #foo or #foo/bar used by Obsidian. It must be at start of line or after whitespace
+	reHashtag = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+	// ATX heading like "# Title"
+	reATXHeading = regexp.MustCompile(`^#\s+(.+?)\s*#*\s*$`)
+)
+
+// importedDirFile is a Markdown file in the imported directory
+type importedDirFile struct {
+	category string
+	file     string
+	title    string
+	tags     []string
+	created  time.Time
+	body     string
+}
+
+// splitFrontMatter splits YAML front matter surrounded by '---' from the content. When the content
+// has no front matter, it returns empty string as front matter
+func splitFrontMatter(content string) (string, string) {
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return "", content
+	}
+	lines := strings.SplitAfter(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		l := strings.TrimRight(lines[i], "\r\n")
+		if l == "---" || l == "..." {
+			return strings.Join(lines[1:i], ""), strings.Join(lines[i+1:], "")
+		}
+	}
+	return "", content
+}
+
+func frontMatterTags(v interface{}) []string {
+	var ss []string
+	switch v := v.(type) {
+	case string:
+		ss = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []interface{}:
+		for _, t := range v {
+			if t != nil {
+				ss = append(ss, fmt.Sprint(t))
+			}
+		}
+	}
+	tags := make([]string, 0, len(ss))
+	for _, t := range ss {
+		if t = strings.TrimPrefix(strings.TrimSpace(t), "#"); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func frontMatterTime(v interface{}) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, true
+		}
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// hashtags collects hashtags in the body. Hashtags in code blocks and headings are ignored
+func hashtags(body string) []string {
+	tags := []string{}
+	inCode := false
+	s := bufio.NewScanner(strings.NewReader(body))
+	for s.Scan() {
+		l := s.Text()
+		if t := strings.TrimSpace(l); strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || strings.HasPrefix(l, "    ") || reATXHeading.MatchString(strings.TrimSpace(l)) {
+			continue
+		}
+		// Remove inline code
+		parts := strings.Split(l, "`")
+		for i := 0; i < len(parts); i += 2 {
+			for _, m := range reHashtag.FindAllStringSubmatch(parts[i], -1) {
+				tags = append(tags, m[1])
+			}
+		}
+	}
+	return tags
+}
+
+// extractTitle returns the first heading in the body as title and the body without the heading. When no
+// heading is found before other contents, it returns empty title
+func extractTitle(body string) (string, string) {
+	lines := strings.SplitAfter(body, "\n")
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		if t == "" {
+			continue
+		}
+		if m := reATXHeading.FindStringSubmatch(t); m != nil {
+			return m[1], strings.Join(lines[i+1:], "")
+		}
+		if i+1 < len(lines) && reTitleBar.MatchString(strings.TrimSpace(lines[i+1])) {
+			return t, strings.Join(lines[i+2:], "")
+		}
+		break
+	}
+	return "", body
+}
+
+func uniqueStrings(ss []string) []string {
+	seen := make(map[string]struct{}, len(ss))
+	ret := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+// parseImportedDirFile parses Markdown file at path. rel is a slash-separated relative path from the
+// imported directory
+func parseImportedDirFile(p, rel, defaultCategory string) (*importedDirFile, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read file")
+	}
+
+	f := &importedDirFile{
+		category: path.Dir(rel),
+		file:     path.Base(rel),
+	}
+	if f.category == "." {
+		f.category = defaultCategory
+	}
+
+	fm, body := splitFrontMatter(string(b))
+	meta := map[string]interface{}{}
+	if fm != "" {
+		if err := yaml.Unmarshal([]byte(fm), &meta); err != nil {
+			return nil, errors.Wrap(err, "Cannot parse YAML front matter")
+		}
+	}
+
+	if t, ok := meta["title"].(string); ok {
+		f.title = t
+	}
+	if f.title == "" {
+		f.title, body = extractTitle(body)
+	}
+	if f.title == "" {
+		f.title = strings.TrimSuffix(f.file, ".md")
+	}
+
+	f.tags = append(frontMatterTags(meta["tags"]), frontMatterTags(meta["tag"])...)
+	f.tags = uniqueStrings(append(f.tags, hashtags(body)...))
+
+	for _, k := range []string{"created", "date", "created_at"} {
+		if t, ok := frontMatterTime(meta[k]); ok {
+			f.created = t
+			break
+		}
+	}
+	if f.created.IsZero() {
+		s, err := os.Stat(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot get modified time")
+		}
+		f.created = s.ModTime()
+	}
+
+	f.body = body
+	return f, nil
+}
+
+// ImportDirCmd represents `notes import dir` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type ImportDirCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Dir is a path to directory which contains Markdown files
+	Dir string
+	// Category is a category of files put directly under Dir. This value is equivalent to --category
+	Category string
+	// Conflict is a policy when a note already exists. One of "skip", "overwrite" or "rename". Empty
+	// means "skip"
+	Conflict string
+	// DryRun is a flag equivalent to --dry-run
+	DryRun bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *ImportDirCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = importCommand(app).Command("dir", "Import Markdown files in directory such as Obsidian vault as notes. Categories are derived from sub directories. Title, tags and created date are taken from YAML front matter, first heading, #hashtags or modified time")
+	cmd.cli.Flag("category", "Category for files put directly under the directory").Short('c').Default(importedCategory).StringVar(&cmd.Category)
+	cmd.cli.Flag("conflict", "What to do when a note already exists. 'skip', 'overwrite' or 'rename'").Default("skip").EnumVar(&cmd.Conflict, "skip", "overwrite", "rename")
+	cmd.cli.Flag("dry-run", "Show what would be imported without writing any note").BoolVar(&cmd.DryRun)
+	cmd.cli.Arg("path", "Directory to import").Required().StringVar(&cmd.Dir)
+}
+
+func (cmd *ImportDirCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *ImportDirCmd) collect() ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(cmd.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			// Skip hidden directories such as .obsidian, .git, .trash
+			if p != cmd.Dir && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".md") && !strings.HasPrefix(name, ".") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot walk directory '%s'", canonPath(cmd.Dir))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// importFile imports the file at p as a note. written is a set of file paths of notes imported in the run
+func (cmd *ImportDirCmd) importFile(p string, written map[string]struct{}) (string, error) {
+	rel, err := filepath.Rel(cmd.Dir, p)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot get relative path")
+	}
+
+	f, err := parseImportedDirFile(p, filepath.ToSlash(rel), cmd.Category)
+	if err != nil {
+		return "", err
+	}
+
+	note, err := NewNote(f.category, strings.Join(f.tags, ","), f.file, f.title, cmd.Config)
+	if err != nil {
+		return "", err
+	}
+	note.Created = f.created
+
+	// Different files can be imported to the same note. For example, "foo.md" directly under the directory
+	// and "inbox/foo.md" are both imported to "inbox/foo.md" when --category is "inbox"
+	note, overwrite := resolveImportConflict(note, cmd.Conflict, written)
+	if note == nil {
+		return "", errors.New("Note already exists")
+	}
+
+	if !cmd.DryRun {
+		if err := writeImportedNote(note, f.body, overwrite); err != nil {
+			return "", err
+		}
+	}
+	written[note.FilePath()] = struct{}{}
+
+	return filepath.ToSlash(note.RelFilePath()), nil
+}
+
+// Do runs `notes import dir` command and returns an error if occurs
+func (cmd *ImportDirCmd) Do() error {
+	if s, err := os.Stat(cmd.Dir); err != nil || !s.IsDir() {
+		return errors.Errorf("Directory to import '%s' does not exist", canonPath(cmd.Dir))
+	}
+
+	files, err := cmd.collect()
+	if err != nil {
+		return err
+	}
+
+	verb := "Imported"
+	if cmd.DryRun {
+		verb = "Would import"
+	}
+
+	imported, skipped := 0, 0
+	written := map[string]struct{}{}
+	for _, p := range files {
+		rel, _ := filepath.Rel(cmd.Dir, p)
+		rel = filepath.ToSlash(rel)
+
+		dest, err := cmd.importFile(p, written)
+		if err != nil {
+			fmt.Fprintf(cmd.Out, "Skipped %s: %s\n", rel, err)
+			skipped++
+			continue
+		}
+
+		fmt.Fprintf(cmd.Out, "%s %s to %s\n", verb, rel, dest)
+		imported++
+	}
+
+	fmt.Fprintf(cmd.Out, "%s %d files to %s (%d skipped)\n", verb, imported, canonPath(cmd.Config.HomePath), skipped)
+	return nil
+}
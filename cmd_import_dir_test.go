@@ -0,0 +1,376 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportDirCmd(t *testing.T) {
+	dir := "test-tmp-dir-import-dir"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	src := filepath.Join("testdata", "import", "dir")
+	mtime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.Local)
+	for _, f := range []string{"readme.md", filepath.Join("journal", "no-title.md")} {
+		panicIfErr(os.Chtimes(filepath.Join(src, f), mtime, mtime))
+	}
+
+	var buf bytes.Buffer
+	cmd := &ImportDirCmd{
+		Config:   cfg,
+		Dir:      src,
+		Category: "inbox",
+		Out:      &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Imported blog/tech/go.md to blog/tech/go.md\n",
+		"Imported journal/day.md to journal/day.md\n",
+		"Imported journal/no-title.md to journal/no-title.md\n",
+		"Imported readme.md to inbox/readme.md\n",
+		"Skipped journal/broken.md: Cannot parse YAML front matter",
+		"Imported 4 files to ",
+		"(1 skipped)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not contained in output:\n%s", want, out)
+		}
+	}
+	for _, s := range []string{".obsidian", "notes.txt"} {
+		if strings.Contains(out, s) {
+			t.Errorf("%q should be ignored: %s", s, out)
+		}
+	}
+
+	for _, tc := range []struct {
+		path     string
+		category string
+		title    string
+		tags     []string
+		created  time.Time
+		body     string
+	}{
+		{
+			path:     "blog/tech/go.md",
+			category: "blog/tech",
+			title:    "Introduction to Go",
+			tags:     []string{"golang", "programming"},
+			created:  time.Date(2019, 5, 1, 10, 20, 30, 0, time.Local),
+			body:     "Go is a programming language. #golang\n\n```sh\n# not a #tag\ngo run .\n```\n\nInline `#code` is not a tag.\n",
+		},
+		{
+			path:     "journal/day.md",
+			category: "journal",
+			title:    "Daily Note",
+			tags:     []string{"diary", "life"},
+			created:  time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			body:     "Today was good.\n",
+		},
+		{
+			path:     "journal/no-title.md",
+			category: "journal",
+			title:    "no-title",
+			tags:     []string{},
+			created:  mtime,
+			body:     "Just a text without heading.\n",
+		},
+		{
+			path:     "inbox/readme.md",
+			category: "inbox",
+			title:    "Welcome",
+			tags:     []string{"inbox"},
+			created:  mtime,
+			body:     "This is a top level note. #inbox\n",
+		},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			n, err := LoadNote(filepath.Join(cfg.HomePath, filepath.FromSlash(tc.path)), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Category != tc.category {
+				t.Error("Unexpected category:", n.Category)
+			}
+			if n.Title != tc.title {
+				t.Error("Unexpected title:", n.Title)
+			}
+			if strings.Join(n.Tags, ",") != strings.Join(tc.tags, ",") {
+				t.Error("Unexpected tags:", n.Tags)
+			}
+			if !n.Created.Equal(tc.created) {
+				t.Error("Unexpected created date:", n.Created)
+			}
+			body, err := n.ReadBody()
+			panicIfErr(err)
+			if body != tc.body {
+				t.Errorf("Unexpected body: %q", body)
+			}
+		})
+	}
+
+	// Existing notes are skipped by default
+	buf.Reset()
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Skipped readme.md: Note already exists") || !strings.Contains(out, "Imported 0 files") {
+		t.Fatal("Unexpected output at second import:", out)
+	}
+
+	// Dry run does not write anything
+	buf.Reset()
+	cmd.Conflict = "rename"
+	cmd.DryRun = true
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Would import readme.md to inbox/readme-1.md\n") || !strings.Contains(out, "Would import 4 files") {
+		t.Fatal("Unexpected output of dry run:", out)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.HomePath, "inbox", "readme-1.md")); err == nil {
+		t.Fatal("Note was created on dry run")
+	}
+
+	// Existing notes are renamed
+	buf.Reset()
+	cmd.DryRun = false
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Imported readme.md to inbox/readme-1.md\n") {
+		t.Fatal("Unexpected output of renaming:", out)
+	}
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "inbox", "readme-1.md"), cfg)
+	panicIfErr(err)
+	if n.Title != "Welcome" {
+		t.Error("Unexpected title of renamed note:", n.Title)
+	}
+
+	// Existing notes are overwritten
+	p := filepath.Join(cfg.HomePath, "journal", "day.md")
+	panicIfErr(os.WriteFile(p, []byte("modified"), 0644))
+	buf.Reset()
+	cmd.Conflict = "overwrite"
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Imported journal/day.md to journal/day.md\n") {
+		t.Fatal("Unexpected output of overwriting:", out)
+	}
+	n, err = LoadNote(p, cfg)
+	panicIfErr(err)
+	if n.Title != "Daily Note" {
+		t.Error("Note was not overwritten:", n.Title)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(cfg.HomePath, "journal", ".*.tmp")); len(tmps) > 0 {
+		t.Error("Temporary files to overwrite notes were left:", tmps)
+	}
+}
+
+func TestImportDirCmdNoDir(t *testing.T) {
+	cmd := &ImportDirCmd{
+		Config: &Config{HomePath: "."},
+		Dir:    "/path/to/not/existing/dir",
+		Out:    &bytes.Buffer{},
+	}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Directory to import") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestImportDirCmdIgnoreTemplate(t *testing.T) {
+	dir := "test-tmp-dir-import-dir-template"
+	src := "test-tmp-dir-import-dir-template-src"
+	defer func() {
+		panicIfErr(os.RemoveAll(dir))
+		panicIfErr(os.RemoveAll(src))
+	}()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+	panicIfErr(os.MkdirAll(filepath.Join(cfg.HomePath, "memo"), 0755))
+	panicIfErr(os.WriteFile(filepath.Join(cfg.HomePath, ".template.md"), []byte("TEMPLATE CONTENT\n"), 0644))
+	panicIfErr(os.WriteFile(filepath.Join(cfg.HomePath, "memo", ".template.md"), []byte("-->\nTEMPLATE CONTENT\n"), 0644))
+
+	panicIfErr(os.MkdirAll(filepath.Join(src, "memo"), 0755))
+	panicIfErr(os.WriteFile(filepath.Join(src, "memo", "foo.md"), []byte("# Foo\n\nbody of foo\n"), 0644))
+	panicIfErr(os.WriteFile(filepath.Join(src, "bar.md"), []byte("# Bar\n\nbody of bar\n"), 0644))
+
+	var buf bytes.Buffer
+	cmd := &ImportDirCmd{
+		Config:   cfg,
+		Dir:      src,
+		Category: "inbox",
+		Out:      &buf,
+	}
+
+	check := func(what string) {
+		for _, tc := range []struct {
+			path string
+			body string
+		}{
+			{"memo/foo.md", "body of foo\n"},
+			{"inbox/bar.md", "body of bar\n"},
+		} {
+			p := filepath.Join(cfg.HomePath, filepath.FromSlash(tc.path))
+			b, err := os.ReadFile(p)
+			panicIfErr(err)
+			if s := string(b); strings.Contains(s, "TEMPLATE CONTENT") || strings.Contains(s, "<!--") {
+				t.Errorf("Template was inserted to %s on %s:\n%s", tc.path, what, s)
+			}
+			n, err := LoadNote(p, cfg)
+			panicIfErr(err)
+			body, err := n.ReadBody()
+			panicIfErr(err)
+			if body != tc.body {
+				t.Errorf("Unexpected body of %s on %s: %q", tc.path, what, body)
+			}
+		}
+	}
+
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	check("import")
+
+	buf.Reset()
+	cmd.Conflict = "overwrite"
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Imported 2 files") {
+		t.Fatal("Unexpected output of overwriting:", out)
+	}
+	check("overwrite")
+}
+
+func TestImportDirCmdSameDestination(t *testing.T) {
+	dir := "test-tmp-dir-import-dir-same-dest"
+	src := "test-tmp-dir-import-dir-same-dest-src"
+	defer func() {
+		panicIfErr(os.RemoveAll(dir))
+		panicIfErr(os.RemoveAll(src))
+	}()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	// Both files are imported to inbox/foo.md
+	panicIfErr(os.MkdirAll(filepath.Join(src, "inbox"), 0755))
+	panicIfErr(os.WriteFile(filepath.Join(src, "inbox", "foo.md"), []byte("# Foo in inbox\n"), 0644))
+	panicIfErr(os.WriteFile(filepath.Join(src, "foo.md"), []byte("# Foo\n"), 0644))
+
+	for _, conflict := range []string{"overwrite", "rename"} {
+		t.Run(conflict, func(t *testing.T) {
+			panicIfErr(os.RemoveAll(dir))
+
+			var buf bytes.Buffer
+			cmd := &ImportDirCmd{
+				Config:   cfg,
+				Dir:      src,
+				Category: "inbox",
+				Conflict: conflict,
+				DryRun:   true,
+				Out:      &buf,
+			}
+
+			want := []string{
+				" foo.md to inbox/foo.md\n",
+				" inbox/foo.md to inbox/foo-1.md\n",
+				" 2 files to ",
+			}
+
+			// Dry run also considers notes which would be imported in the same run
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range want {
+				if out := buf.String(); !strings.Contains(out, "Would import"+w) {
+					t.Errorf("%q is not contained in output of dry run:\n%s", w, out)
+				}
+			}
+
+			buf.Reset()
+			cmd.DryRun = false
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range want {
+				if out := buf.String(); !strings.Contains(out, "Imported"+w) {
+					t.Errorf("%q is not contained in output:\n%s", w, out)
+				}
+			}
+
+			for file, title := range map[string]string{"foo.md": "Foo", "foo-1.md": "Foo in inbox"} {
+				n, err := LoadNote(filepath.Join(cfg.HomePath, "inbox", file), cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n.Title != title {
+					t.Errorf("Unexpected title of %s: %q", file, n.Title)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteImportedNoteOverwriteError(t *testing.T) {
+	dir := "test-tmp-dir-import-overwrite-error"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	// Existing file cannot be replaced since it is a non-empty directory
+	p := filepath.Join(cfg.HomePath, "memo", "foo.md", "keep")
+	panicIfErr(os.MkdirAll(filepath.Dir(p), 0755))
+	panicIfErr(os.WriteFile(p, []byte("keep"), 0644))
+
+	n, err := NewNote("memo", "", "foo.md", "Foo", cfg)
+	panicIfErr(err)
+	if err := writeImportedNote(n, "body", true); err == nil || !strings.Contains(err.Error(), "Cannot overwrite note") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := os.Stat(p); err != nil {
+		t.Fatal("Existing file was removed:", err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(cfg.HomePath, "memo", ".*.tmp")); len(tmps) > 0 {
+		t.Fatal("Temporary files were left:", tmps)
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		fm    string
+		body  string
+	}{
+		{"---\ntitle: foo\n---\nbody\n", "title: foo\n", "body\n"},
+		{"---\ntitle: foo\n...\nbody\n", "title: foo\n", "body\n"},
+		{"---\ntitle: foo\nbody\n", "", "---\ntitle: foo\nbody\n"},
+		{"body\n---\n", "", "body\n---\n"},
+	} {
+		fm, body := splitFrontMatter(tc.input)
+		if fm != tc.fm || body != tc.body {
+			t.Errorf("Unexpected result for %q: front matter=%q body=%q", tc.input, fm, body)
+		}
+	}
+}
@@ -0,0 +1,586 @@
+package notes
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var reSpaces = regexp.MustCompile(`\s+`)
+
+// enexResource is a resource such as an image embedded in Evernote note
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Body     string `xml:",chardata"`
+	} `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// enexNote is a <note> element in ENEX file exported by Evernote. <notebook> is not a part of Evernote's
+// format but some other tools put it
+type enexNote struct {
+	Title     string         `xml:"title"`
+	Content   string         `xml:"content"`
+	Created   string         `xml:"created"`
+	Tags      []string       `xml:"tag"`
+	Notebook  string         `xml:"notebook"`
+	Resources []enexResource `xml:"resource"`
+}
+
+// enexAttachmentsDir is a directory to put attachments of imported notes in a category. It is hidden so
+// that it is not treated as a category and attachments in it are not reported as orphans
+const enexAttachmentsDir = ".attachments"
+
+// enexAttachment is a decoded resource which will be written to attachments directory
+type enexAttachment struct {
+	name string
+	data []byte
+	mime string
+	link string
+}
+
+// enmlNode is a node of ENML document which is a content of Evernote note. Text node has empty tag
+type enmlNode struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*enmlNode
+}
+
+// parseENML parses ENML document. Since ENML is a XHTML, it is parsed with encoding/xml in non-strict mode
+// to accept HTML entities and unclosed elements
+func parseENML(content string) (*enmlNode, error) {
+	dec := xml.NewDecoder(strings.NewReader(content))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	root := &enmlNode{tag: "en-note"}
+	stack := []*enmlNode{root}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot parse note content")
+		}
+
+		parent := stack[len(stack)-1]
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &enmlNode{tag: strings.ToLower(tok.Name.Local), attrs: make(map[string]string, len(tok.Attr))}
+			for _, a := range tok.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &enmlNode{text: string(tok)})
+		}
+	}
+
+	return root, nil
+}
+
+func (n *enmlNode) isBlock() bool {
+	switch n.tag {
+	case "div", "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "pre", "blockquote", "hr", "table", "en-note", "center", "section", "article":
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *enmlNode) isCodeBlock() bool {
+	return n.tag == "pre" || strings.Contains(strings.ReplaceAll(n.attrs["style"], " ", ""), "-en-codeblock:true")
+}
+
+// textContent returns raw text in the node keeping line breaks
+func (n *enmlNode) textContent() string {
+	if n.tag == "" {
+		return n.text
+	}
+	if n.tag == "br" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.textContent())
+	}
+	s := b.String()
+	if (n.tag == "div" || n.tag == "p") && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s
+}
+
+// enmlRenderer converts ENML document into Markdown text
+type enmlRenderer struct {
+	// media is a map from MD5 hash of resource to its Markdown representation
+	media map[string]string
+	// lists is a depth of lists which are being rendered
+	lists int
+}
+
+func wrapInline(mark, s string) string {
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return s
+	}
+	i := strings.Index(s, t)
+	return s[:i] + mark + t + mark + s[i+len(t):]
+}
+
+func (r *enmlRenderer) inlines(nodes []*enmlNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(r.inline(n))
+	}
+	return b.String()
+}
+
+func (r *enmlRenderer) inline(n *enmlNode) string {
+	switch n.tag {
+	case "":
+		return reSpaces.ReplaceAllString(strings.ReplaceAll(n.text, "\u00a0", " "), " ")
+	case "br":
+		return "\n"
+	case "b", "strong":
+		return wrapInline("**", r.inlines(n.children))
+	case "i", "em":
+		return wrapInline("*", r.inlines(n.children))
+	case "s", "strike", "del":
+		return wrapInline("~~", r.inlines(n.children))
+	case "code", "tt":
+		return wrapInline("`", n.textContent())
+	case "a":
+		text := strings.TrimSpace(r.inlines(n.children))
+		href := n.attrs["href"]
+		if href == "" {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case "img":
+		return fmt.Sprintf("![%s](%s)", n.attrs["alt"], n.attrs["src"])
+	case "en-media":
+		return r.media[n.attrs["hash"]]
+	case "en-todo":
+		if n.attrs["checked"] == "true" {
+			return "[x] "
+		}
+		return "[ ] "
+	default:
+		return r.inlines(n.children)
+	}
+}
+
+// paragraph normalizes lines of inline contents. Checkboxes outside lists are converted into task list
+// items
+func (r *enmlRenderer) paragraph(s string) string {
+	lines := strings.Split(s, "\n")
+	ret := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l == "" {
+			continue
+		}
+		if r.lists == 0 && (strings.HasPrefix(l, "[ ] ") || strings.HasPrefix(l, "[x] ")) {
+			l = "- " + l
+		}
+		ret = append(ret, l)
+	}
+	return strings.Join(ret, "\n")
+}
+
+// blocks renders nodes as Markdown blocks. Consecutive inline nodes are put in one paragraph
+func (r *enmlRenderer) blocks(nodes []*enmlNode) []string {
+	blocks := []string{}
+	var para strings.Builder
+	flush := func() {
+		if p := r.paragraph(para.String()); p != "" {
+			blocks = append(blocks, p)
+		}
+		para.Reset()
+	}
+
+	for _, n := range nodes {
+		if !n.isBlock() {
+			para.WriteString(r.inline(n))
+			continue
+		}
+		flush()
+		blocks = append(blocks, r.block(n)...)
+	}
+	flush()
+
+	return blocks
+}
+
+func (r *enmlRenderer) block(n *enmlNode) []string {
+	if n.isCodeBlock() {
+		code := strings.Trim(n.textContent(), "\n")
+		if code == "" {
+			return nil
+		}
+		return []string{"```\n" + code + "\n```"}
+	}
+
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.tag[1:])
+		s := reSpaces.ReplaceAllString(strings.TrimSpace(r.inlines(n.children)), " ")
+		if s == "" {
+			return nil
+		}
+		return []string{strings.Repeat("#", level) + " " + s}
+	case "hr":
+		return []string{"---"}
+	case "ul", "ol":
+		if s := r.list(n); s != "" {
+			return []string{s}
+		}
+		return nil
+	case "blockquote":
+		lines := strings.Split(strings.Join(r.blocks(n.children), "\n\n"), "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+	case "table":
+		if s := r.table(n); s != "" {
+			return []string{s}
+		}
+		return nil
+	default:
+		return r.blocks(n.children)
+	}
+}
+
+func indentLines(s, marker string) string {
+	indent := strings.Repeat(" ", len(marker))
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if i == 0 {
+			lines[i] = marker + l
+		} else if l != "" {
+			lines[i] = indent + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *enmlRenderer) list(n *enmlNode) string {
+	r.lists++
+	defer func() { r.lists-- }()
+
+	items := []string{}
+	marker := "- "
+	num := 1
+	for _, c := range n.children {
+		switch c.tag {
+		case "li":
+			if n.tag == "ol" {
+				marker = strconv.Itoa(num) + ". "
+				num++
+			}
+			items = append(items, indentLines(strings.Join(r.blocks(c.children), "\n"), marker))
+		case "ul", "ol":
+			// Evernote puts nested list directly in parent list
+			if s := r.list(c); s != "" {
+				items = append(items, indentLines(s, strings.Repeat(" ", len(marker))))
+			}
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+func (r *enmlRenderer) tableRows(n *enmlNode) [][]string {
+	rows := [][]string{}
+	for _, c := range n.children {
+		switch c.tag {
+		case "tr":
+			cells := []string{}
+			for _, cell := range c.children {
+				if cell.tag != "td" && cell.tag != "th" {
+					continue
+				}
+				s := strings.Join(r.blocks(cell.children), " ")
+				s = strings.ReplaceAll(strings.ReplaceAll(s, "\n", " "), "|", `\|`)
+				cells = append(cells, s)
+			}
+			rows = append(rows, cells)
+		case "thead", "tbody", "tfoot":
+			rows = append(rows, r.tableRows(c)...)
+		}
+	}
+	return rows
+}
+
+func (r *enmlRenderer) table(n *enmlNode) string {
+	rows := r.tableRows(n)
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// enexCategory converts notebook name into category name
+func enexCategory(notebook string) string {
+	c := strings.ToLower(strings.TrimSpace(notebook))
+	c = strings.TrimLeft(reSpaces.ReplaceAllString(c, "-"), ".")
+	if c == "" {
+		return importedCategory
+	}
+	return c
+}
+
+// decodeResources decodes resources of the note. Links to the resources are relative paths from the note
+func decodeResources(rs []enexResource, dir string) ([]*enexAttachment, error) {
+	as := make([]*enexAttachment, 0, len(rs))
+	names := map[string]struct{}{}
+	for i, r := range rs {
+		enc := strings.TrimSpace(r.Data.Encoding)
+		if enc != "" && enc != "base64" {
+			return nil, errors.Errorf("Unsupported encoding '%s' of resource", enc)
+		}
+		data, err := base64.StdEncoding.DecodeString(reSpaces.ReplaceAllString(r.Data.Body, ""))
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot decode resource")
+		}
+
+		name := filepath.Base(filepath.FromSlash(strings.TrimSpace(r.FileName)))
+		if name == "." || name == string(filepath.Separator) || strings.HasPrefix(name, ".") {
+			name = "resource-" + strconv.Itoa(i+1)
+			if exts, err := mime.ExtensionsByType(r.Mime); err == nil && len(exts) > 0 {
+				name += exts[0]
+			}
+		}
+		ext := filepath.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		for j := 1; ; j++ {
+			if _, ok := names[name]; !ok {
+				break
+			}
+			name = base + "-" + strconv.Itoa(j) + ext
+		}
+		names[name] = struct{}{}
+
+		as = append(as, &enexAttachment{
+			name: name,
+			data: data,
+			mime: r.Mime,
+			link: enexAttachmentsDir + "/" + url.PathEscape(dir) + "/" + url.PathEscape(name),
+		})
+	}
+	return as, nil
+}
+
+func (a *enexAttachment) markdown() string {
+	if strings.HasPrefix(a.mime, "image/") {
+		return fmt.Sprintf("![%s](%s)", a.name, a.link)
+	}
+	return fmt.Sprintf("[%s](%s)", a.name, a.link)
+}
+
+// ImportEnexCmd represents `notes import enex` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type ImportEnexCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// File is a path to ENEX file exported by Evernote
+	File string
+	// Category is a category of imported notes. When it is empty, notebook name is used. This value is
+	// equivalent to --category
+	Category string
+	// Conflict is a policy when a note already exists. One of "skip", "overwrite" or "rename". Empty
+	// means "skip"
+	Conflict string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *ImportEnexCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = importCommand(app).Command("enex", "Import notes in ENEX file exported by Evernote. Contents are converted to Markdown and embedded resources are put in '.attachments' directory next to the notes")
+	cmd.cli.Flag("category", "Category of imported notes. By default, notebook name (file name of ENEX file) is used").Short('c').StringVar(&cmd.Category)
+	cmd.cli.Flag("conflict", "What to do when a note already exists. 'skip', 'overwrite' or 'rename'").Default("skip").EnumVar(&cmd.Conflict, "skip", "overwrite", "rename")
+	cmd.cli.Arg("file", "ENEX file to import").Required().StringVar(&cmd.File)
+}
+
+func (cmd *ImportEnexCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// importNote imports the note in ENEX file. written is a set of file paths of notes imported in the run
+func (cmd *ImportEnexCmd) importNote(en *enexNote, notebook string, written map[string]struct{}) (string, error) {
+	if en.Notebook != "" {
+		notebook = en.Notebook
+	}
+	cat := cmd.Category
+	if cat == "" {
+		cat = enexCategory(notebook)
+	}
+
+	tags := make([]string, 0, len(en.Tags))
+	for _, t := range en.Tags {
+		if t = strings.TrimSpace(strings.ReplaceAll(t, ",", " ")); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	title := strings.TrimSpace(en.Title)
+	note, err := NewNote(cat, strings.Join(tags, ","), fileNameOfTitle(title), title, cmd.Config)
+	if err != nil {
+		return "", err
+	}
+	if c := strings.TrimSpace(en.Created); c != "" {
+		t, err := time.Parse("20060102T150405Z", c)
+		if err != nil {
+			return "", errors.Wrapf(err, "Cannot parse created date '%s'", c)
+		}
+		note.Created = t.Local()
+	}
+
+	// Notes which have the same title in one ENEX file are imported to the same file
+	note, overwrite := resolveImportConflict(note, cmd.Conflict, written)
+	if note == nil {
+		return "", errors.New("Note already exists")
+	}
+
+	dir := strings.TrimSuffix(note.File, ".md")
+	as, err := decodeResources(en.Resources, dir)
+	if err != nil {
+		return "", err
+	}
+
+	r := &enmlRenderer{media: make(map[string]string, len(as))}
+	hashes := make([]string, 0, len(as))
+	for _, a := range as {
+		sum := md5.Sum(a.data)
+		h := hex.EncodeToString(sum[:])
+		r.media[h] = a.markdown()
+		hashes = append(hashes, h)
+	}
+
+	root, err := parseENML(en.Content)
+	if err != nil {
+		return "", err
+	}
+	blocks := r.blocks(root.children)
+
+	// Resources not referenced from content are linked at the end of note
+	body := strings.Join(blocks, "\n\n")
+	for i, a := range as {
+		if !strings.Contains(body, r.media[hashes[i]]) {
+			blocks = append(blocks, a.markdown())
+		}
+	}
+
+	if len(as) > 0 {
+		d := filepath.Join(note.DirPath(), enexAttachmentsDir, dir)
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return "", errors.Wrap(err, "Cannot create attachments directory")
+		}
+		for _, a := range as {
+			if err := os.WriteFile(filepath.Join(d, a.name), a.data, 0644); err != nil {
+				return "", errors.Wrap(err, "Cannot write attachment")
+			}
+		}
+	}
+
+	// Existing note is replaced at last so that it is kept when decoding or rendering the note failed
+	if err := writeImportedNote(note, strings.Join(blocks, "\n\n"), overwrite); err != nil {
+		return "", err
+	}
+	written[note.FilePath()] = struct{}{}
+
+	return filepath.ToSlash(note.RelFilePath()), nil
+}
+
+// Do runs `notes import enex` command and returns an error if occurs
+func (cmd *ImportEnexCmd) Do() error {
+	f, err := os.Open(cmd.File)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot open ENEX file '%s'", canonPath(cmd.File))
+	}
+	defer f.Close()
+
+	// Evernote exports one notebook to one ENEX file named after the notebook
+	notebook := strings.TrimSuffix(filepath.Base(cmd.File), filepath.Ext(cmd.File))
+
+	imported, skipped := 0, 0
+	written := map[string]struct{}{}
+	dec := xml.NewDecoder(f)
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Cannot parse ENEX file '%s'", canonPath(cmd.File))
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "note" {
+			continue
+		}
+
+		var en enexNote
+		if err := dec.DecodeElement(&en, &se); err != nil {
+			return errors.Wrapf(err, "Cannot parse note in ENEX file '%s'", canonPath(cmd.File))
+		}
+
+		p, err := cmd.importNote(&en, notebook, written)
+		if err != nil {
+			fmt.Fprintf(cmd.Out, "Skipped %q: %s\n", en.Title, err)
+			skipped++
+			continue
+		}
+
+		fmt.Fprintf(cmd.Out, "Imported %q to %s\n", en.Title, p)
+		imported++
+	}
+
+	fmt.Fprintf(cmd.Out, "Imported %d notes to %s (%d skipped)\n", imported, canonPath(cmd.Config.HomePath), skipped)
+	return nil
+}
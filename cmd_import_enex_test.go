@@ -0,0 +1,310 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportEnexCmd(t *testing.T) {
+	dir := "test-tmp-dir-import-enex"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	var buf bytes.Buffer
+	cmd := &ImportEnexCmd{
+		Config:   cfg,
+		File:     filepath.Join("testdata", "import", "enex", "Work Notes.enex"),
+		Conflict: "skip",
+		Out:      &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Imported \"Meeting Memo\" to work-notes/meeting-memo.md\n",
+		"Imported \"Untitled\" to work-notes/untitled.md\n",
+		"Skipped \"Broken date\": Cannot parse created date 'yesterday'",
+		"Imported 2 notes to ",
+		"(1 skipped)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not contained in output:\n%s", want, out)
+		}
+	}
+
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "work-notes", "meeting-memo.md"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Title != "Meeting Memo" {
+		t.Error("Unexpected title:", n.Title)
+	}
+	if n.Category != "work-notes" {
+		t.Error("Notebook should be mapped to category:", n.Category)
+	}
+	if strings.Join(n.Tags, ",") != "work,meeting" {
+		t.Error("Unexpected tags:", n.Tags)
+	}
+	if want := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC); !n.Created.Equal(want) {
+		t.Error("Unexpected created date:", n.Created)
+	}
+
+	body, err := n.ReadBody()
+	panicIfErr(err)
+	want := "# Agenda\n\n" +
+		"Discuss **release** plan with [team](https://example.com).\n\n" +
+		"- first\n- second\n  - nested\n\n" +
+		"1. one\n2. two\n\n" +
+		"- [x] done task\n\n- [ ] open task\n\n" +
+		"```\nfmt.Println(\"hi\")\nx := 1 < 2\n```\n\n" +
+		"> quoted *text*\n\n" +
+		"| a | b |\n| --- | --- |\n| 1 | 2 |\n\n" +
+		"![logo.png](.attachments/meeting-memo/logo.png)\n\n" +
+		"[note.txt](.attachments/meeting-memo/note.txt)\n"
+	if body != want {
+		t.Errorf("Unexpected body. Wanted %q but got %q", want, body)
+	}
+
+	attachments := filepath.Join(cfg.HomePath, "work-notes", ".attachments", "meeting-memo")
+	logo, err := os.ReadFile(filepath.Join(attachments, "logo.png"))
+	panicIfErr(err)
+	orig, err := os.ReadFile(filepath.Join("testdata", "export", "normal", "blog", "tech", "img", "logo.png"))
+	panicIfErr(err)
+	if !bytes.Equal(logo, orig) {
+		t.Error("Image resource was not extracted correctly")
+	}
+	txt, err := os.ReadFile(filepath.Join(attachments, "note.txt"))
+	panicIfErr(err)
+	if string(txt) != "hello attachment\n" {
+		t.Errorf("Unexpected attachment content: %q", txt)
+	}
+
+	// Attachments are neither categories nor orphans so that `notes prune` does not remove them
+	orphans, err := CollectOrphans(cfg)
+	panicIfErr(err)
+	if len(orphans.EmptyDirs) > 0 || len(orphans.Files) > 0 {
+		t.Error("Attachments were reported as orphans:", orphans.EmptyDirs, orphans.Files)
+	}
+	cats, err := CollectCategories(cfg, 0)
+	panicIfErr(err)
+	for name := range cats {
+		if strings.Contains(name, "attachments") {
+			t.Error("Attachments directory was collected as category:", name)
+		}
+	}
+
+	// Existing notes are skipped by default
+	buf.Reset()
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Skipped \"Untitled\": Note already exists") || !strings.Contains(out, "Imported 0 notes") {
+		t.Fatal("Unexpected output at second import:", out)
+	}
+
+	// Category can be specified and existing notes are renamed
+	buf.Reset()
+	cmd.Category = "memo"
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Conflict = "rename"
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Imported \"Meeting Memo\" to memo/meeting-memo-1.md\n") {
+		t.Fatal("Unexpected output of renaming:", out)
+	}
+	b, err := os.ReadFile(filepath.Join(cfg.HomePath, "memo", "meeting-memo-1.md"))
+	panicIfErr(err)
+	if !strings.Contains(string(b), "](.attachments/meeting-memo-1/logo.png)") {
+		t.Error("Attachments of renamed note should be put in its own directory:", string(b))
+	}
+	if _, err := os.Stat(filepath.Join(cfg.HomePath, "memo", ".attachments", "meeting-memo-1", "logo.png")); err != nil {
+		t.Error("Attachment of renamed note was not extracted:", err)
+	}
+}
+
+func TestImportEnexCmdOverwriteError(t *testing.T) {
+	dir := "test-tmp-dir-import-enex-overwrite"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	existing := map[string]string{}
+	for _, title := range []string{"Bad Resource", "Bad Content"} {
+		n, err := NewNote("memo", "", fileNameOfTitle(title), title, cfg)
+		panicIfErr(err)
+		panicIfErr(createNoteWithBody(n, "original"))
+		b, err := os.ReadFile(n.FilePath())
+		panicIfErr(err)
+		existing[n.FilePath()] = string(b)
+	}
+
+	enex := filepath.Join(cfg.HomePath, "notes.enex")
+	panicIfErr(os.WriteFile(enex, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<en-export>
+<note>
+<title>Bad Resource</title>
+<content><![CDATA[<en-note><div>text</div></en-note>]]></content>
+<resource><data encoding="base64">!!!</data><mime>text/plain</mime></resource>
+</note>
+<note>
+<title>Bad Content</title>
+<content><![CDATA[<en-note><div>text</div><!-- unterminated</en-note>]]></content>
+</note>
+</en-export>
+`), 0644))
+
+	var buf bytes.Buffer
+	cmd := &ImportEnexCmd{Config: cfg, File: enex, Category: "memo", Conflict: "overwrite", Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Imported 0 notes") || !strings.Contains(out, "(2 skipped)") {
+		t.Fatal("Unexpected output:", out)
+	}
+
+	for p, want := range existing {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal("Existing note was removed:", err)
+		}
+		if string(b) != want {
+			t.Fatalf("Existing note was changed: %q", b)
+		}
+	}
+}
+
+func writeTestEnex(path string, titles ...string) {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<en-export>\n")
+	for _, t := range titles {
+		b.WriteString("<note>\n<title>" + t + "</title>\n")
+		b.WriteString("<content><![CDATA[<en-note><div>body of " + t + "</div></en-note>]]></content>\n</note>\n")
+	}
+	b.WriteString("</en-export>\n")
+	panicIfErr(os.MkdirAll(filepath.Dir(path), 0755))
+	panicIfErr(os.WriteFile(path, []byte(b.String()), 0644))
+}
+
+func TestImportEnexCmdIgnoreTemplate(t *testing.T) {
+	dir := "test-tmp-dir-import-enex-template"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	enex := filepath.Join(cfg.HomePath, "notes.enex")
+	writeTestEnex(enex, "Foo")
+	panicIfErr(os.WriteFile(filepath.Join(cfg.HomePath, ".template.md"), []byte("TEMPLATE CONTENT\n"), 0644))
+
+	for _, conflict := range []string{"skip", "overwrite"} {
+		var buf bytes.Buffer
+		cmd := &ImportEnexCmd{Config: cfg, File: enex, Category: "memo", Conflict: conflict, Out: &buf}
+		if err := cmd.Do(); err != nil {
+			t.Fatal(err)
+		}
+		if out := buf.String(); !strings.Contains(out, "Imported \"Foo\" to memo/foo.md\n") {
+			t.Fatal("Unexpected output:", out)
+		}
+
+		p := filepath.Join(cfg.HomePath, "memo", "foo.md")
+		b, err := os.ReadFile(p)
+		panicIfErr(err)
+		if strings.Contains(string(b), "TEMPLATE CONTENT") {
+			t.Fatalf("Template was inserted to imported note with --conflict %s:\n%s", conflict, b)
+		}
+		n, err := LoadNote(p, cfg)
+		panicIfErr(err)
+		body, err := n.ReadBody()
+		panicIfErr(err)
+		if body != "body of Foo\n" {
+			t.Fatalf("Unexpected body with --conflict %s: %q", conflict, body)
+		}
+	}
+}
+
+func TestImportEnexCmdSameTitle(t *testing.T) {
+	dir := "test-tmp-dir-import-enex-same-title"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	enex := filepath.Join(cfg.HomePath, "notes.enex")
+	writeTestEnex(enex, "Foo", "foo")
+
+	var buf bytes.Buffer
+	cmd := &ImportEnexCmd{Config: cfg, File: enex, Category: "memo", Conflict: "overwrite", Out: &buf}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Imported \"Foo\" to memo/foo.md\n",
+		"Imported \"foo\" to memo/foo-1.md\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not contained in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestEnexFileName(t *testing.T) {
+	dir := "test-tmp-dir-import-enex-file-name"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	for _, tc := range []struct {
+		title string
+		want  string
+	}{
+		{"Meeting Memo", "meeting-memo.md"},
+		{"  Hello, World!!  ", "hello-world.md"},
+		{"日本語 メモ", "日本語-メモ.md"},
+		{"???", "untitled.md"},
+	} {
+		enex := filepath.Join(cfg.HomePath, "notes.enex")
+		writeTestEnex(enex, tc.title)
+
+		var buf bytes.Buffer
+		cmd := &ImportEnexCmd{Config: cfg, File: enex, Category: "memo", Out: &buf}
+		if err := cmd.Do(); err != nil {
+			t.Fatal(err)
+		}
+		if out := buf.String(); !strings.Contains(out, " to memo/"+tc.want+"\n") {
+			t.Errorf("Wanted %q for %q but got output %q", tc.want, tc.title, out)
+		}
+		if _, err := os.Stat(filepath.Join(cfg.HomePath, "memo", tc.want)); err != nil {
+			t.Errorf("Note for %q was not created: %s", tc.title, err)
+		}
+	}
+}
+
+func TestImportEnexCmdNoFile(t *testing.T) {
+	cmd := &ImportEnexCmd{
+		Config: &Config{HomePath: "."},
+		File:   "/path/to/not/existing.enex",
+		Out:    &bytes.Buffer{},
+	}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Cannot open ENEX file") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
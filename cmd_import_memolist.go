@@ -0,0 +1,197 @@
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	// memolist.vim prefixes file names with dates like "2018-10-30-foo.md" by default
+	reMemolistFile = regexp.MustCompile(`^\d+-\d+-\d+-(.+\.md)$`)
+	// memolist.vim writes tags and categories like "tags: [foo, bar]"
+	reMemolistList = regexp.MustCompile(`^\[(.*)\]$`)
+)
+
+// memolistMemo is a memo of memolist.vim parsed from its front matter
+type memolistMemo struct {
+	file       string
+	title      string
+	date       time.Time
+	tags       []string
+	categories []string
+	body       string
+}
+
+func parseMemolistList(line, key string) ([]string, error) {
+	if !strings.HasPrefix(line, key+":") {
+		return nil, errors.Errorf("'%s:' is expected but got %q", key, line)
+	}
+	v := strings.TrimSpace(line[len(key)+1:])
+	if m := reMemolistList.FindStringSubmatch(v); m != nil {
+		v = m[1]
+	}
+	ret := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			ret = append(ret, s)
+		}
+	}
+	return ret, nil
+}
+
+// parseMemolistMemo parses a memo of memolist.vim. Its format is like:
+//
+//	title: Foo
+//	==========
+//	date: 2018-10-30 11:37
+//	tags: [foo,bar]
+//	categories: [memo]
+//	- - -
+//	body...
+func parseMemolistMemo(path string) (*memolistMemo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot open memo")
+	}
+	defer f.Close()
+
+	m := &memolistMemo{file: filepath.Base(path)}
+	if match := reMemolistFile.FindStringSubmatch(m.file); match != nil {
+		m.file = match[1]
+	}
+
+	r := bufio.NewReader(f)
+	next := func() string {
+		l, _ := r.ReadString('\n')
+		return strings.TrimRight(l, "\r\n")
+	}
+
+	l := next()
+	if !strings.HasPrefix(l, "title:") {
+		return nil, errors.Errorf("'title:' is expected at first line but got %q", l)
+	}
+	m.title = strings.TrimSpace(l[6:])
+
+	if l := next(); !reTitleBar.MatchString(l) {
+		return nil, errors.Errorf("'====' bar is expected after title but got %q", l)
+	}
+
+	l = next()
+	if !strings.HasPrefix(l, "date:") {
+		return nil, errors.Errorf("'date:' is expected but got %q", l)
+	}
+	d := strings.TrimSpace(l[5:])
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, d, time.Local); err == nil {
+			m.date = t
+			break
+		}
+	}
+	if m.date.IsZero() {
+		return nil, errors.Errorf("Cannot parse date %q", d)
+	}
+
+	if m.tags, err = parseMemolistList(next(), "tags"); err != nil {
+		return nil, err
+	}
+	if m.categories, err = parseMemolistList(next(), "categories"); err != nil {
+		return nil, err
+	}
+
+	if l := next(); !reHorizontalRule.MatchString(l) {
+		return nil, errors.Errorf("Horizontal rule is expected after front matter but got %q", l)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read body of memo")
+	}
+	m.body = strings.TrimSpace(string(b))
+
+	return m, nil
+}
+
+// ImportMemolistCmd represents `notes import memolist` command. Each public fields represent options of the
+// command. Out field represents where this command should output.
+type ImportMemolistCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Dir is a path to directory of memolist.vim (g:memolist_path)
+	Dir string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *ImportMemolistCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = importCommand(app).Command("memolist", "Import memos of memolist.vim as notes. A memo which has one category is imported to the category. Other memos are imported to 'imported' category and their categories are added to tags")
+	cmd.cli.Arg("dir", "Directory of memolist.vim (g:memolist_path)").Required().StringVar(&cmd.Dir)
+}
+
+func (cmd *ImportMemolistCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *ImportMemolistCmd) importMemo(m *memolistMemo) (*Note, error) {
+	cat, tags := importedCategory, m.tags
+	if len(m.categories) == 1 {
+		cat = strings.ToLower(m.categories[0])
+	} else {
+		tags = append(tags, m.categories...)
+	}
+
+	note, err := NewNote(cat, strings.Join(tags, ","), m.file, m.title, cmd.Config)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the original date of the memo
+	note.Created = m.date
+
+	if err := createImportedNote(note, m.body); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// Do runs `notes import memolist` command and returns an error if occurs
+func (cmd *ImportMemolistCmd) Do() error {
+	if s, err := os.Stat(cmd.Dir); err != nil || !s.IsDir() {
+		return errors.Errorf("Directory of memolist '%s' does not exist", canonPath(cmd.Dir))
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cmd.Dir, "*.md"))
+	if err != nil {
+		return errors.Wrap(err, "Cannot list memos of memolist")
+	}
+	sort.Strings(paths)
+
+	imported, skipped := 0, 0
+	for _, p := range paths {
+		name := filepath.Base(p)
+
+		m, err := parseMemolistMemo(p)
+		if err == nil {
+			var n *Note
+			if n, err = cmd.importMemo(m); err == nil {
+				fmt.Fprintf(cmd.Out, "Imported %s to %s\n", name, filepath.ToSlash(n.RelFilePath()))
+				imported++
+				continue
+			}
+		}
+
+		fmt.Fprintf(cmd.Out, "Skipped %s: %s\n", name, err)
+		skipped++
+	}
+
+	fmt.Fprintf(cmd.Out, "Imported %d memos to %s (%d skipped)\n", imported, canonPath(cmd.Config.HomePath), skipped)
+	return nil
+}
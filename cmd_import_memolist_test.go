@@ -0,0 +1,169 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportMemolistCmd(t *testing.T) {
+	dir := "test-tmp-dir-import-memolist"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	var buf bytes.Buffer
+	cmd := &ImportMemolistCmd{
+		Config: cfg,
+		Dir:    filepath.Join("testdata", "import", "memolist"),
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Imported 2018-10-30-foo.md to memo/foo.md\n",
+		"Imported 2018-11-01-multi.md to imported/multi.md\n",
+		"Imported 2018-11-02-nocat.md to imported/nocat.md\n",
+		"Skipped 2018-11-03-hidden.md: Invalid category part '.hidden' as directory name",
+		"Skipped broken.md: 'title:' is expected at first line",
+		"Imported 3 memos to ",
+		"(2 skipped)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not contained in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "notes.txt") {
+		t.Error("Non-markdown file should be ignored:", out)
+	}
+
+	for _, tc := range []struct {
+		path     string
+		category string
+		tags     []string
+		created  string
+		body     string
+	}{
+		{
+			path:     "memo/foo.md",
+			category: "memo",
+			tags:     []string{"vim", "go"},
+			created:  "2018-10-30 11:37",
+			body:     "Hello from memolist\n\n- item\n",
+		},
+		{
+			path:     "imported/multi.md",
+			category: "imported",
+			tags:     []string{"a", "b"},
+			created:  "2018-11-01 09:00",
+			body:     "body of multi\n",
+		},
+		{
+			path:     "imported/nocat.md",
+			category: "imported",
+			tags:     []string{"misc"},
+			created:  "2018-11-02 10:30",
+			body:     "",
+		},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			n, err := LoadNote(filepath.Join(cfg.HomePath, filepath.FromSlash(tc.path)), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Category != tc.category {
+				t.Error("Unexpected category:", n.Category)
+			}
+			if strings.Join(n.Tags, ",") != strings.Join(tc.tags, ",") {
+				t.Error("Unexpected tags:", n.Tags)
+			}
+			want, err := time.ParseInLocation("2006-01-02 15:04", tc.created, time.Local)
+			panicIfErr(err)
+			if !n.Created.Equal(want) {
+				t.Error("Original date was not kept:", n.Created)
+			}
+			body, err := n.ReadBody()
+			panicIfErr(err)
+			if body != tc.body {
+				t.Errorf("Unexpected body: %q", body)
+			}
+		})
+	}
+
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "memo", "foo.md"), cfg)
+	panicIfErr(err)
+	if n.Title != "This is foo" {
+		t.Error("Unexpected title:", n.Title)
+	}
+
+	// Existing notes are not overwritten
+	buf.Reset()
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Skipped 2018-10-30-foo.md: Cannot create new note since file") || !strings.Contains(out, "Imported 0 memos") {
+		t.Fatal("Unexpected output at second import:", out)
+	}
+}
+
+func TestImportMemolistCmdNoDir(t *testing.T) {
+	cmd := &ImportMemolistCmd{
+		Config: &Config{HomePath: "."},
+		Dir:    "/path/to/not/existing/dir",
+		Out:    &bytes.Buffer{},
+	}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Directory of memolist") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestImportMemolistCmdIgnoreTemplate(t *testing.T) {
+	dir := "test-tmp-dir-import-memolist-template"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+	panicIfErr(os.MkdirAll(filepath.Join(cfg.HomePath, "memo"), 0755))
+	panicIfErr(os.WriteFile(filepath.Join(cfg.HomePath, ".template.md"), []byte("TEMPLATE CONTENT\n"), 0644))
+	panicIfErr(os.WriteFile(filepath.Join(cfg.HomePath, "memo", ".template.md"), []byte("-->\nTEMPLATE CONTENT\n"), 0644))
+
+	var buf bytes.Buffer
+	cmd := &ImportMemolistCmd{
+		Config: cfg,
+		Dir:    filepath.Join("testdata", "import", "memolist"),
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"memo/foo.md", "imported/multi.md"} {
+		b, err := os.ReadFile(filepath.Join(cfg.HomePath, filepath.FromSlash(p)))
+		panicIfErr(err)
+		s := string(b)
+		if strings.Contains(s, "TEMPLATE CONTENT") || strings.Contains(s, "<!--") {
+			t.Errorf("Template was inserted to imported note %s:\n%s", p, s)
+		}
+	}
+
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "memo", "foo.md"), cfg)
+	panicIfErr(err)
+	body, err := n.ReadBody()
+	panicIfErr(err)
+	if body != "Hello from memolist\n\n- item\n" {
+		t.Errorf("Unexpected body: %q", body)
+	}
+}
@@ -0,0 +1,135 @@
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// LinksCmd represents `notes links` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type LinksCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Note is a note to show its links. It is an absolute path or a relative path from home such as
+	// "category/file.md". It can be empty only when Broken is true
+	Note string
+	// Broken is a flag to show links which refer no note. This value is equivalent to --broken option
+	Broken bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *LinksCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("links", "Show notes linked from the note with [[category/file]] or [[Title]] style links. Each path of note is output per line")
+	cmd.cli.Arg("note", "Note to show links. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted. It can be omitted with --broken to check all notes").StringVar(&cmd.Note)
+	cmd.cli.Flag("broken", "Show links which refer no note instead with 'category/file.md:line: [[link]]' format").Short('b').BoolVar(&cmd.Broken)
+}
+
+func (cmd *LinksCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes links` command and returns an error if occurs
+func (cmd *LinksCmd) Do() error {
+	if cmd.Note == "" && !cmd.Broken {
+		return errors.New("Note must be specified unless --broken is given")
+	}
+
+	idx, err := collectNoteLinkIndex(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	notes := idx.notes
+	if cmd.Note != "" {
+		n, err := idx.note(cmd.Config.HomePath, cmd.Note)
+		if err != nil {
+			return err
+		}
+		notes = []*Note{n}
+	}
+
+	out := bufio.NewWriter(cmd.Out)
+	for _, n := range notes {
+		links, err := n.links()
+		if err != nil {
+			return err
+		}
+		seen := map[*Note]struct{}{}
+		for _, l := range links {
+			dest := idx.resolve(l.Target)
+			if cmd.Broken {
+				if dest == nil {
+					fmt.Fprintf(out, "%s:%d: [[%s]]\n", filepath.ToSlash(n.RelFilePath()), l.Line, l.Target)
+				}
+				continue
+			}
+			if dest == nil {
+				continue
+			}
+			if _, ok := seen[dest]; ok {
+				continue
+			}
+			seen[dest] = struct{}{}
+			fmt.Fprintln(out, filepath.ToSlash(dest.RelFilePath()))
+		}
+	}
+	return out.Flush()
+}
+
+// BacklinksCmd represents `notes backlinks` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type BacklinksCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Note is a note to show notes referring it. It is an absolute path or a relative path from home such
+	// as "category/file.md"
+	Note string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *BacklinksCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("backlinks", "Show notes which refer the note with [[category/file]] or [[Title]] style links. Each path of note is output per line")
+	cmd.cli.Arg("note", "Note to show backlinks. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted").Required().StringVar(&cmd.Note)
+}
+
+func (cmd *BacklinksCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes backlinks` command and returns an error if occurs
+func (cmd *BacklinksCmd) Do() error {
+	idx, err := collectNoteLinkIndex(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	target, err := idx.note(cmd.Config.HomePath, cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(cmd.Out)
+	for _, n := range idx.notes {
+		if n == target {
+			continue
+		}
+		links, err := n.links()
+		if err != nil {
+			return err
+		}
+		for _, l := range links {
+			if idx.resolve(l.Target) == target {
+				fmt.Fprintln(out, filepath.ToSlash(n.RelFilePath()))
+				break
+			}
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,118 @@
+package notes
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinksCmd(t *testing.T) {
+	for _, tc := range []struct {
+		what   string
+		note   string
+		broken bool
+		want   string
+	}{
+		{
+			what: "outgoing links",
+			note: "memo/a.md",
+			want: "memo/b.md\nblog/c.md\n",
+		},
+		{
+			what: "title link",
+			note: "memo/b",
+			want: "memo/a.md\n",
+		},
+		{
+			what: "no link",
+			note: "blog/c",
+			want: "",
+		},
+		{
+			what:   "broken links in all notes",
+			broken: true,
+			want:   "memo/a.md:8: [[Missing]]\nmemo/b.md:11: [[blog/missing]]\n",
+		},
+		{
+			what:   "broken links in note",
+			note:   "memo/b.md",
+			broken: true,
+			want:   "memo/b.md:11: [[blog/missing]]\n",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &LinksCmd{Config: testLinksConfig(), Note: tc.note, Broken: tc.broken, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have := filepath.ToSlash(buf.String()); have != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestLinksCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		what string
+		note string
+		want string
+	}{
+		{
+			what: "no note",
+			note: "",
+			want: "Note must be specified unless --broken is given",
+		},
+		{
+			what: "note not found",
+			note: "memo/unknown.md",
+			want: "Note 'memo/unknown.md' does not exist",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			cmd := &LinksCmd{Config: testLinksConfig(), Note: tc.note, Out: &bytes.Buffer{}}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestBacklinksCmd(t *testing.T) {
+	for _, tc := range []struct {
+		note string
+		want string
+	}{
+		{"memo/a.md", "memo/b.md\n"},
+		{"memo/b", "memo/a.md\n"},
+		{"blog/c.md", "memo/a.md\n"},
+	} {
+		t.Run(tc.note, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &BacklinksCmd{Config: testLinksConfig(), Note: tc.note, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have := filepath.ToSlash(buf.String()); have != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestBacklinksCmdNoteNotFound(t *testing.T) {
+	cmd := &BacklinksCmd{Config: testLinksConfig(), Note: "blog/missing", Out: &bytes.Buffer{}}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Note 'blog/missing.md' does not exist") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -41,6 +42,8 @@ type ListCmd struct {
 	SortBy string
 	// Edit is a flag equivalent to --edit
 	Edit bool
+	// AllNotebooks is a flag equivalent to --all-notebooks
+	AllNotebooks bool
 	// Out is a writer to write output of this command. Kind of stdout is expected
 	Out io.Writer
 }
@@ -50,9 +53,21 @@ func (cmd *ListCmd) defineListCLI(c *kingpin.CmdClause) {
 	c.Flag("category", "Filter list by category name with regular expression").Short('c').StringVar(&cmd.Category)
 	c.Flag("tag", "Filter list by tag name with regular expression").Short('t').StringVar(&cmd.Tag)
 	c.Flag("relative", "Show relative paths from $NOTES_CLI_HOME directory").Short('r').BoolVar(&cmd.Relative)
-	c.Flag("oneline", "Show oneline information of note (relative path, category, tags, title) instead of file path").Short('o').BoolVar(&cmd.Oneline)
-	c.Flag("sort", "Sort list by 'modified', 'created', 'filename' or 'category'. Default is 'created'").Short('s').EnumVar(&cmd.SortBy, "modified", "created", "filename", "category")
+	oneline := c.Flag("oneline", "Show oneline information of note (relative path, category, tags, title) instead of file path").Short('o')
+	sortBy := c.Flag("sort", "Sort list by 'modified', 'created', 'filename' or 'category'. Default is 'created'").Short('s')
+	if cmd.Config != nil {
+		// Default values can be configured in [list] section of config file
+		if cmd.Config.List.Oneline {
+			oneline.Default("true")
+		}
+		if cmd.Config.List.SortBy != "" {
+			sortBy.Default(cmd.Config.List.SortBy)
+		}
+	}
+	oneline.BoolVar(&cmd.Oneline)
+	sortBy.EnumVar(&cmd.SortBy, "modified", "created", "filename", "category")
 	c.Flag("edit", "Open listed notes with your favorite editor. $NOTES_CLI_EDITOR must be set. Paths of listed notes are passed to the editor command's arguments").Short('e').BoolVar(&cmd.Edit)
+	c.Flag("all-notebooks", "List notes in all notebooks configured in [notebooks] section of config file. Notebook name is shown with --oneline and --relative").Short('a').BoolVar(&cmd.AllNotebooks)
 }
 
 func (cmd *ListCmd) defineCLI(app *kingpin.Application) {
@@ -100,13 +115,14 @@ func (cmd *ListCmd) printNoteFullTo(out *bufio.Writer, note *Note) {
 }
 
 func (cmd *ListCmd) printOnelineNotes(notes []*Note) error {
-	tw := make([][2]int, len(notes))
-	max := [2]int{}
+	tw := make([][3]int, len(notes))
+	max := [3]int{}
 
 	for i, note := range notes {
 		tw[i][0] = runewidth.StringWidth(note.Category+note.File) + 1 // + 1 for separator
 		tw[i][1] = runewidth.StringWidth(strings.Join(note.Tags, ","))
-		for j := 0; j < 2; j++ {
+		tw[i][2] = runewidth.StringWidth(note.Config.Notebook)
+		for j := 0; j < 3; j++ {
 			if tw[i][j] > max[j] {
 				max[j] = tw[i][j]
 			}
@@ -115,6 +131,12 @@ func (cmd *ListCmd) printOnelineNotes(notes []*Note) error {
 
 	out := bufio.NewWriter(cmd.out)
 	for i, note := range notes {
+		if cmd.AllNotebooks {
+			// Notebook column is necessary only when listing multiple notebooks
+			out.WriteString(note.Config.Notebook)
+			out.WriteString(strings.Repeat(" ", max[2]-tw[i][2]+1)) // +1 for separator
+		}
+
 		pad := strings.Repeat(" ", max[0]-tw[i][0]+1) // +1 for separator
 		green.Fprint(out, filepath.FromSlash(note.Category))
 		out.WriteRune(filepath.Separator)
@@ -133,17 +155,8 @@ func (cmd *ListCmd) printOnelineNotes(notes []*Note) error {
 }
 
 func (cmd *ListCmd) printNotes(notes []*Note) error {
-	switch strings.ToLower(cmd.SortBy) {
-	case "filename":
-		sortByFilename(notes)
-	case "category":
-		sortByCategory(notes)
-	case "modified":
-		if err := sortByModified(notes); err != nil {
-			return err
-		}
-	default:
-		sortByCreated(notes)
+	if err := sortNotes(notes, cmd.SortBy); err != nil {
+		return err
 	}
 
 	if cmd.Full {
@@ -163,12 +176,19 @@ func (cmd *ListCmd) printNotes(notes []*Note) error {
 		for _, n := range notes {
 			args = append(args, n.FilePath())
 		}
-		return openEditor(cmd.Config, args...)
+		snapshot := takeNotesSnapshot(args...)
+		if err := openEditor(cmd.Config, args...); err != nil {
+			return err
+		}
+		return cmd.autoSave(notes, snapshot.changed())
 	}
 
 	var b bytes.Buffer
 	if cmd.Relative {
 		for _, note := range notes {
+			if cmd.AllNotebooks {
+				b.WriteString(note.Config.Notebook + ":")
+			}
 			b.WriteString(note.RelFilePath())
 			b.WriteRune('\n')
 		}
@@ -183,18 +203,43 @@ func (cmd *ListCmd) printNotes(notes []*Note) error {
 	return err
 }
 
-// Do runs `notes list` command and returns an error if occurs
-func (cmd *ListCmd) Do() error {
-	cats, err := CollectCategories(cmd.Config, 0)
-	if err != nil {
-		return err
+// autoSave saves notes changed in an editor when auto-save is enabled. changed is a list of paths of
+// the changed notes. Notes in different notebooks are saved to their own repositories
+func (cmd *ListCmd) autoSave(notes []*Note, changed []string) error {
+	isChanged := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		isChanged[p] = true
 	}
 
-	var catReg *regexp.Regexp
-	if cmd.Category != "" {
-		if catReg, err = regexp.Compile(cmd.Category); err != nil {
-			return errors.Wrap(err, "Regular expression for filtering categories is invalid")
+	homes := []string{}
+	cfgs := map[string]*Config{}
+	paths := map[string][]string{}
+	for _, n := range notes {
+		if !isChanged[n.FilePath()] {
+			continue
 		}
+		h := n.Config.HomePath
+		if _, ok := cfgs[h]; !ok {
+			homes = append(homes, h)
+			cfgs[h] = n.Config
+		}
+		paths[h] = append(paths[h], n.FilePath())
+	}
+
+	for _, h := range homes {
+		if err := autoSave(cfgs[h], paths[h]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectNotes collects notes in home filtered by regular expressions of category and tag. Nil regular
+// expression means no filter
+func collectNotes(cfg *Config, catReg, tagReg *regexp.Regexp) ([]*Note, error) {
+	cats, err := CollectCategories(cfg, 0)
+	if err != nil {
+		return nil, err
 	}
 
 	numNotes := 0
@@ -206,19 +251,12 @@ func (cmd *ListCmd) Do() error {
 		numNotes += len(c.NotePaths)
 	}
 
-	var tagReg *regexp.Regexp
-	if cmd.Tag != "" {
-		if tagReg, err = regexp.Compile(cmd.Tag); err != nil {
-			return errors.Wrap(err, "Regular expression for filtering tags is invalid")
-		}
-	}
-
 	notes := make([]*Note, 0, numNotes)
 	for _, cat := range cats {
 		for _, p := range cat.NotePaths {
-			note, err := LoadNote(p, cmd.Config)
+			note, err := LoadNote(p, cfg)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			if tagReg == nil {
 				notes = append(notes, note)
@@ -234,6 +272,63 @@ func (cmd *ListCmd) Do() error {
 		}
 	}
 
+	return notes, nil
+}
+
+// compileFilters compiles regular expressions to filter notes by category and tag. Empty string means
+// no filter and nil is returned for it
+func compileFilters(category, tag string) (*regexp.Regexp, *regexp.Regexp, error) {
+	var catReg, tagReg *regexp.Regexp
+	var err error
+
+	if category != "" {
+		if catReg, err = regexp.Compile(category); err != nil {
+			return nil, nil, errors.Wrap(err, "Regular expression for filtering categories is invalid")
+		}
+	}
+
+	if tag != "" {
+		if tagReg, err = regexp.Compile(tag); err != nil {
+			return nil, nil, errors.Wrap(err, "Regular expression for filtering tags is invalid")
+		}
+	}
+
+	return catReg, tagReg, nil
+}
+
+// Do runs `notes list` command and returns an error if occurs
+func (cmd *ListCmd) Do() error {
+	catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+	if err != nil {
+		return err
+	}
+
+	cfgs := []*Config{cmd.Config}
+	if cmd.AllNotebooks {
+		if len(cmd.Config.Notebooks) == 0 {
+			return errors.New("No notebook is configured. Please configure notebooks in [notebooks] section of config file")
+		}
+		names := make([]string, 0, len(cmd.Config.Notebooks))
+		for n := range cmd.Config.Notebooks {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		cfgs = make([]*Config, 0, len(names))
+		for _, n := range names {
+			c, _ := cmd.Config.ForNotebook(n)
+			cfgs = append(cfgs, c)
+		}
+	}
+
+	var notes []*Note
+	for _, c := range cfgs {
+		ns, err := collectNotes(c, catReg, tagReg)
+		if err != nil {
+			return err
+		}
+		notes = append(notes, ns...)
+	}
+
 	if len(notes) == 0 {
 		return nil
 	}
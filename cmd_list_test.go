@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -889,3 +891,137 @@ func TestListPagingError(t *testing.T) {
 		})
 	}
 }
+
+func TestListAllNotebooks(t *testing.T) {
+	old := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = old }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{
+		HomePath: filepath.Join(cwd, "testdata", "list", "normal"),
+		Notebooks: map[string]string{
+			"normal": filepath.Join(cwd, "testdata", "list", "normal"),
+			"nest":   filepath.Join(cwd, "testdata", "list", "nested"),
+		},
+		Notebook: "normal",
+	}
+
+	for _, tc := range []struct {
+		what string
+		cmd  *ListCmd
+		want string
+	}{
+		{
+			what: "oneline",
+			cmd: &ListCmd{
+				Oneline:  true,
+				SortBy:   "category",
+				Category: "^a$",
+			},
+			want: `normal a/1.md foo,bar this is title
+normal a/4.md bar     this is title this is title this is title this is title this is title this is title this is title this is title
+nest   a/5.md a,foo   this is title
+`,
+		},
+		{
+			what: "relative",
+			cmd: &ListCmd{
+				Relative: true,
+				SortBy:   "category",
+				Category: "^(a|c)$",
+			},
+			want: `normal:a/1.md
+normal:a/4.md
+nest:a/5.md
+normal:c/3.md
+normal:c/5.md
+nest:c/6.md
+`,
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.cmd.Config = cfg
+			tc.cmd.AllNotebooks = true
+			tc.cmd.Out = &buf
+			if err := tc.cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.FromSlash(tc.want)
+			if have := buf.String(); have != want {
+				t.Fatalf("Wanted %q but have %q", want, have)
+			}
+		})
+	}
+}
+
+func TestListAllNotebooksNoNotebook(t *testing.T) {
+	cmd := &ListCmd{
+		Config:       testNewConfigForListCmd("normal"),
+		AllNotebooks: true,
+	}
+	err := cmd.Do()
+	if err == nil || !strings.Contains(err.Error(), "No notebook is configured") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestListCmdEditAutoSave(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("'sh' is used as editor")
+	}
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{
+		GitPath:  "git",
+		HomePath: filepath.Join(cwd, "test-tmp-dir-list-auto-save"),
+		// Append a line to only the first file
+		EditorCmd: `sh -c 'echo edited >> "$1"' sh`,
+		Save:      SaveConfig{Auto: true},
+	}
+	panicIfErr(os.MkdirAll(cfg.HomePath, 0755))
+	defer os.RemoveAll(cfg.HomePath)
+
+	g := NewGit(cfg)
+	prepareGitRepoForTestNewCmd(g)
+	writeTestNote(cfg, "memo/foo.md", "foo\n===\n- Category: memo\n- Tags:\n- Created: 2018-10-30T11:37:45+09:00\n\n")
+	writeTestNote(cfg, "memo/bar.md", "bar\n===\n- Category: memo\n- Tags:\n- Created: 2018-10-29T11:37:45+09:00\n\n")
+	writeTestNote(cfg, "blog/post.md", "post\n===\n- Category: blog\n- Tags:\n- Created: 2018-10-28T11:37:45+09:00\n\n")
+	panicIfErr(g.AddAll())
+	panicIfErr(g.Commit("initial"))
+
+	// Changed but not opened in the editor
+	writeTestNote(cfg, "blog/post.md", "post\n===\n- Category: blog\n- Tags:\n- Created: 2018-10-28T11:37:45+09:00\n\nchanged\n")
+	// Opened in the editor but changed before opening it
+	writeTestNote(cfg, "memo/bar.md", "bar\n===\n- Category: memo\n- Tags:\n- Created: 2018-10-29T11:37:45+09:00\n\nchanged\n")
+
+	cmd := &ListCmd{
+		Config:   cfg,
+		Out:      io.Discard,
+		Edit:     true,
+		Category: "^memo$",
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := g.Exec("log", "--format=%s")
+	panicIfErr(err)
+	if out != "Update memo/foo.md\ninitial" {
+		t.Fatal("Unexpected commits:", out)
+	}
+
+	changes, err := g.Changes()
+	panicIfErr(err)
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	sort.Strings(paths)
+	if !reflect.DeepEqual(paths, []string{"blog/post.md", "memo/bar.md"}) {
+		t.Fatal("Notes which were not changed in the editor should not be committed:", paths)
+	}
+}
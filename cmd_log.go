@@ -0,0 +1,60 @@
+package notes
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// LogCmd represents `notes log` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type LogCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Note is a note to show its history. It is an absolute path or a relative path from home such as
+	// "category/file.md"
+	Note string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *LogCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("log", "Show commits which touched the note with their hashes, dates and messages. Git repository at home is used")
+	cmd.cli.Arg("note", "Note to show history. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted").Required().StringVar(&cmd.Note)
+}
+
+func (cmd *LogCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes log` command and returns an error if occurs
+func (cmd *LogCmd) Do() error {
+	git, err := newGitForCmd("log", cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	path, err := noteRelPath(cmd.Config.HomePath, cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	commits, err := git.Log(path)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return errors.Errorf("No history of note '%s' was found. Please save the note with `notes save` at first", path)
+	}
+
+	out := bufio.NewWriter(cmd.Out)
+	for _, c := range commits {
+		yellow.Fprint(out, c.Hash)
+		out.WriteRune(' ')
+		green.Fprint(out, c.Date.Format("2006-01-02 15:04"))
+		out.WriteString(" " + c.Subject + "\n")
+	}
+	return out.Flush()
+}
@@ -0,0 +1,138 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// testNewHistoryRepo creates a Git repository which has history of notes. memo/foo.md is created,
+// updated and memo/bar.md is created and deleted. It returns revisions of each commit from older one
+func testNewHistoryRepo(dir string) (*Config, []string) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{GitPath: "git", HomePath: filepath.Join(cwd, dir)}
+	panicIfErr(os.MkdirAll(cfg.HomePath, 0755))
+
+	g := NewGit(cfg)
+	prepareGitRepoForTestNewCmd(g)
+
+	revs := []string{}
+	commit := func(msg string) {
+		panicIfErr(g.AddAll())
+		panicIfErr(g.Commit(msg))
+		rev, err := g.Exec("rev-parse", "--short", "HEAD")
+		panicIfErr(err)
+		revs = append(revs, rev)
+	}
+
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"first\n")
+	commit("first commit")
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"second\n")
+	writeTestNote(cfg, "memo/bar.md", "bar\n===\n- Category: memo\n- Tags: dog, cat\n- Created: 2019-01-01T00:00:00+09:00\n\nThis is a note completely different from foo\n")
+	commit("second commit")
+	panicIfErr(os.Remove(filepath.Join(cfg.HomePath, "memo", "bar.md")))
+	commit("third commit")
+
+	return cfg, revs
+}
+
+func TestLogCmd(t *testing.T) {
+	old := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = old }()
+
+	cfg, revs := testNewHistoryRepo("test-tmp-dir-log")
+	defer os.RemoveAll(cfg.HomePath)
+
+	for _, tc := range []struct {
+		note string
+		want []string
+	}{
+		{
+			note: "memo/foo.md",
+			want: []string{revs[1] + " second commit", revs[0] + " first commit"},
+		},
+		{
+			note: "memo/foo",
+			want: []string{revs[1] + " second commit", revs[0] + " first commit"},
+		},
+		{
+			note: filepath.Join(cfg.HomePath, "memo", "foo.md"),
+			want: []string{revs[1] + " second commit", revs[0] + " first commit"},
+		},
+		{
+			note: "memo/bar.md",
+			want: []string{revs[2] + " third commit", revs[1] + " second commit"},
+		},
+	} {
+		t.Run(tc.note, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &LogCmd{Config: cfg, Note: tc.note, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Date part is dropped since it depends on when the test runs
+			reDate := regexp.MustCompile(` \d{4}-\d\d-\d\d \d\d:\d\d `)
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != len(tc.want) {
+				t.Fatal("Unexpected number of lines:", lines)
+			}
+			for i, l := range lines {
+				if !reDate.MatchString(l) {
+					t.Fatal("Date is not included:", l)
+				}
+				have := reDate.ReplaceAllString(l, " ")
+				if have != tc.want[i] {
+					t.Errorf("Wanted '%s' but have '%s'", tc.want[i], have)
+				}
+			}
+		})
+	}
+}
+
+func TestLogCmdError(t *testing.T) {
+	cfg, _ := testNewHistoryRepo("test-tmp-dir-log-error")
+	defer os.RemoveAll(cfg.HomePath)
+
+	for _, tc := range []struct {
+		note string
+		want string
+	}{
+		{
+			note: "memo/unknown.md",
+			want: "No history of note 'memo/unknown.md' was found",
+		},
+		{
+			note: "../outside.md",
+			want: "is not in home",
+		},
+	} {
+		t.Run(tc.note, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &LogCmd{Config: cfg, Note: tc.note, Out: &buf}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestLogCmdNoGit(t *testing.T) {
+	cfg := &Config{HomePath: "."}
+	cmd := &LogCmd{Config: cfg, Note: "memo/foo.md", Out: os.Stdout}
+	err := cmd.Do()
+	if err == nil || !strings.Contains(err.Error(), "'log' command cannot work without Git") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,160 @@
+package notes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// MvCmd represents `notes mv` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type MvCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Note is a note to move. It is an absolute path or a relative path from home such as "category/file.md"
+	Note string
+	// Dest is a destination of the note. It is "category/file.md" or a category name to keep file name
+	Dest string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *MvCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("mv", "Move or rename the note. Category in its metadata and [[category/file]] style links referring the note in other notes are updated")
+	cmd.cli.Arg("note", "Note to move. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted").Required().StringVar(&cmd.Note)
+	cmd.cli.Arg("dest", "Destination like 'category/file.md'. When it does not end with '.md', it is a category and file name is kept").Required().StringVar(&cmd.Dest)
+}
+
+func (cmd *MvCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// destination returns category and file name of the destination
+func (cmd *MvCmd) destination(note *Note) (string, string, error) {
+	dest := strings.Trim(filepath.ToSlash(cmd.Dest), "/")
+	cat, file := dest, note.File
+	if strings.HasSuffix(dest, ".md") {
+		i := strings.LastIndexByte(dest, '/')
+		if i < 0 {
+			return "", "", errors.Errorf("Category is missing in destination '%s'. Please specify it with 'category/file.md'", cmd.Dest)
+		}
+		cat, file = dest[:i], dest[i+1:]
+	}
+
+	for _, part := range strings.Split(cat, "/") {
+		if err := validateDirname(part); err != nil {
+			return "", "", errors.Wrapf(err, "Invalid category part '%s' of destination as directory name", part)
+		}
+	}
+	if strings.HasPrefix(file, ".") {
+		return "", "", errors.Errorf("File name of destination '%s' cannot start with '.'", cmd.Dest)
+	}
+	return cat, file, nil
+}
+
+// move moves the note file and updates its category metadata
+func (cmd *MvCmd) move(note *Note, cat, file string) error {
+	header, body, err := note.readContent()
+	if err != nil {
+		return err
+	}
+
+	from := note.RelFilePath()
+	to := &Note{Config: cmd.Config, Category: cat, File: file}
+	if _, err := os.Stat(to.FilePath()); err == nil {
+		return errors.Errorf("Cannot move note '%s' since '%s' already exists", from, to.RelFilePath())
+	}
+	if err := os.MkdirAll(to.DirPath(), 0755); err != nil {
+		return errors.Wrapf(err, "Could not create category directory '%s'", canonPath(to.DirPath()))
+	}
+	if err := os.Rename(note.FilePath(), to.FilePath()); err != nil {
+		return errors.Wrapf(err, "Cannot move note '%s' to '%s'", from, to.RelFilePath())
+	}
+
+	note.Category, note.File = cat, file
+	lines := strings.SplitAfter(header, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "- Category: ") {
+			lines[i] = "- Category: " + cat + "\n"
+			break
+		}
+	}
+	return errors.Wrapf(os.WriteFile(note.FilePath(), []byte(strings.Join(lines, "")+body), 0644), "Cannot write note '%s'", note.RelFilePath())
+}
+
+// updateLinks rewrites [[category/file]] style links referring the moved note. It returns true when the
+// note was updated
+func updateLinks(note *Note, from, to string) (bool, error) {
+	header, body, err := note.readContent()
+	if err != nil {
+		return false, err
+	}
+
+	updated := mapWikiLinks(body, func(_ int, target string) string {
+		if strings.TrimSuffix(target, ".md") != from {
+			return target
+		}
+		if strings.HasSuffix(target, ".md") {
+			return to + ".md"
+		}
+		return to
+	})
+	if updated == body {
+		return false, nil
+	}
+
+	if err := os.WriteFile(note.FilePath(), []byte(header+updated), 0644); err != nil {
+		return false, errors.Wrapf(err, "Cannot write note '%s'", note.RelFilePath())
+	}
+	return true, nil
+}
+
+// Do runs `notes mv` command and returns an error if occurs
+func (cmd *MvCmd) Do() error {
+	idx, err := collectNoteLinkIndex(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	note, err := idx.note(cmd.Config.HomePath, cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	cat, file, err := cmd.destination(note)
+	if err != nil {
+		return err
+	}
+	if cat == note.Category && file == note.File {
+		return errors.Errorf("Destination of note '%s' is the same as the note", note.RelFilePath())
+	}
+
+	oldPath, oldRel, from := note.FilePath(), note.RelFilePath(), linkPathOf(note)
+	if err := cmd.move(note, cat, file); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Out, "Moved %s to %s\n", filepath.ToSlash(oldRel), filepath.ToSlash(note.RelFilePath()))
+
+	saved := []string{oldPath, note.FilePath()}
+	to := linkPathOf(note)
+	for _, n := range idx.notes {
+		ok, err := updateLinks(n, from, to)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(cmd.Out, "Updated links in %s\n", filepath.ToSlash(n.RelFilePath()))
+		if n != note {
+			saved = append(saved, n.FilePath())
+		}
+	}
+
+	return autoSave(cmd.Config, saved...)
+}
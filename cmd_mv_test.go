@@ -0,0 +1,196 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func prepareHomeForMvTest(home string) *Config {
+	src := testLinksConfig()
+	cfg := &Config{HomePath: home, GitPath: "git"}
+	for _, rel := range []string{"memo/a.md", "memo/b.md", "blog/c.md"} {
+		b, err := os.ReadFile(filepath.Join(src.HomePath, filepath.FromSlash(rel)))
+		panicIfErr(err)
+		writeTestNote(cfg, rel, string(b))
+	}
+	return cfg
+}
+
+func readNoteForMvTest(cfg *Config, rel string) string {
+	b, err := os.ReadFile(filepath.Join(cfg.HomePath, filepath.FromSlash(rel)))
+	panicIfErr(err)
+	return string(b)
+}
+
+func TestMvCmd(t *testing.T) {
+	for _, tc := range []struct {
+		what     string
+		note     string
+		dest     string
+		moved    string
+		category string
+		output   string
+		links    string
+	}{
+		{
+			what:     "rename and change category",
+			note:     "memo/b.md",
+			dest:     "blog/renamed.md",
+			moved:    "blog/renamed.md",
+			category: "blog",
+			output:   "Moved memo/b.md to blog/renamed.md\nUpdated links in memo/a.md\n",
+			links:    "See [[blog/renamed]] and [[Note C]].\nAlso [[blog/renamed.md#Section|section of B]] and [[Missing]].\n",
+		},
+		{
+			what:     "move to category",
+			note:     "memo/b",
+			dest:     "blog/tech",
+			moved:    "blog/tech/b.md",
+			category: "blog/tech",
+			output:   "Moved memo/b.md to blog/tech/b.md\nUpdated links in memo/a.md\n",
+			links:    "See [[blog/tech/b]] and [[Note C]].\nAlso [[blog/tech/b.md#Section|section of B]] and [[Missing]].\n",
+		},
+		{
+			what:     "title links are not updated",
+			note:     "memo/a.md",
+			dest:     "archive",
+			moved:    "archive/a.md",
+			category: "archive",
+			output:   "Moved memo/a.md to archive/a.md\n",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			home := filepath.Join("test-tmp-dir-mv", "home")
+			defer func() { panicIfErr(os.RemoveAll("test-tmp-dir-mv")) }()
+			cwd, err := os.Getwd()
+			panicIfErr(err)
+			cfg := prepareHomeForMvTest(filepath.Join(cwd, home))
+
+			var buf bytes.Buffer
+			cmd := &MvCmd{Config: cfg, Note: tc.note, Dest: tc.dest, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			if have := filepath.ToSlash(buf.String()); have != tc.output {
+				t.Fatalf("Wanted output %q but have %q", tc.output, have)
+			}
+
+			n, err := LoadNote(filepath.Join(cfg.HomePath, filepath.FromSlash(tc.moved)), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Category != tc.category {
+				t.Fatal("Category was not updated:", n.Category)
+			}
+			if _, err := os.Stat(filepath.Join(cfg.HomePath, filepath.FromSlash(tc.note))); err == nil && strings.HasSuffix(tc.note, ".md") {
+				t.Fatal("Original note still exists:", tc.note)
+			}
+
+			if tc.links != "" {
+				if a := readNoteForMvTest(cfg, "memo/a.md"); !strings.Contains(a, tc.links) {
+					t.Fatalf("Links were not updated: %q", a)
+				}
+			}
+			if b := readNoteForMvTest(cfg, tc.moved); tc.note == "memo/a.md" && !strings.Contains(b, "[[memo/b]]") {
+				t.Fatalf("Links in moved note were changed: %q", b)
+			}
+		})
+	}
+}
+
+func TestMvCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		what string
+		note string
+		dest string
+		want string
+	}{
+		{
+			what: "note not found",
+			note: "memo/unknown.md",
+			dest: "blog",
+			want: "Note 'memo/unknown.md' does not exist",
+		},
+		{
+			what: "destination exists",
+			note: "memo/a.md",
+			dest: "memo/b.md",
+			want: "Cannot move note 'memo/a.md' since 'memo/b.md' already exists",
+		},
+		{
+			what: "same destination",
+			note: "memo/a",
+			dest: "memo",
+			want: "Destination of note 'memo/a.md' is the same as the note",
+		},
+		{
+			what: "no category",
+			note: "memo/a.md",
+			dest: "a.md",
+			want: "Category is missing in destination 'a.md'",
+		},
+		{
+			what: "invalid category",
+			note: "memo/a.md",
+			dest: ".hidden/a.md",
+			want: "Invalid category part '.hidden'",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			defer func() { panicIfErr(os.RemoveAll("test-tmp-dir-mv")) }()
+			cwd, err := os.Getwd()
+			panicIfErr(err)
+			cfg := prepareHomeForMvTest(filepath.Join(cwd, "test-tmp-dir-mv", "home"))
+
+			cmd := &MvCmd{Config: cfg, Note: tc.note, Dest: tc.dest, Out: &bytes.Buffer{}}
+			err = cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(filepath.ToSlash(err.Error()), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestMvCmdAutoSave(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-mv-save")
+	defer repos.cleanup()
+	cfg := repos.clone("home")
+	prepareHomeForMvTest(cfg.HomePath)
+	git := NewGit(cfg)
+	if err := git.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.Commit("first commit"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Save.Auto = true
+	cmd := &MvCmd{Config: cfg, Note: "memo/b.md", Dest: "blog", Out: &bytes.Buffer{}}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := git.Exec("status", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "" {
+		t.Fatal("Changes were not committed:", out)
+	}
+	out, err = git.Exec("show", "--name-status", "--format=", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"memo/a.md", "blog/b.md", "memo/b.md"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is not in the last commit: %q", want, out)
+		}
+	}
+}
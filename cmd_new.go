@@ -69,6 +69,8 @@ func (cmd *NewCmd) Do() error {
 		return err
 	}
 
+	// Note does not exist yet so the created note is always saved by auto-save
+	snapshot := takeNotesSnapshot(note.FilePath())
 	if err := note.Create(); err != nil {
 		return err
 	}
@@ -90,11 +92,15 @@ func (cmd *NewCmd) Do() error {
 			fmt.Fprintf(os.Stderr, "Note: %s\n", err)
 		}
 		if !cmd.NoInline {
-			return cmd.fallbackInput(note)
+			if err := cmd.fallbackInput(note); err != nil {
+				return err
+			}
+			return autoSave(cmd.Config, snapshot.changed()...)
 		}
 		// Final fallback is only showing the path to the note. Then users can open it by themselves.
 		fmt.Println(note.FilePath())
+		return nil
 	}
 
-	return nil
+	return autoSave(cmd.Config, snapshot.changed()...)
 }
@@ -1,6 +1,7 @@
 package notes
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -94,6 +95,7 @@ func TestNewCmdNewNoteWithNoInlineInput(t *testing.T) {
 
 	cfg := testNewConfigForNewCmd("empty")
 	defer os.RemoveAll(filepath.Join(cfg.HomePath, ".git"))
+	defer os.Remove(filepath.Join(cfg.HomePath, ".gitignore"))
 
 	for _, tc := range []struct {
 		cat   string
@@ -244,3 +246,45 @@ func TestNewCmdNoteInvalidInput(t *testing.T) {
 		t.Fatal("Unexpected error:", err)
 	}
 }
+
+func TestNewCmdAutoSave(t *testing.T) {
+	for _, auto := range []bool{true, false} {
+		t.Run(fmt.Sprintf("auto=%v", auto), func(t *testing.T) {
+			cwd, err := os.Getwd()
+			panicIfErr(err)
+			cfg := &Config{
+				GitPath:   "git",
+				HomePath:  filepath.Join(cwd, "test-tmp-dir-new-auto-save"),
+				EditorCmd: "echo",
+				Save:      SaveConfig{Auto: auto},
+			}
+			panicIfErr(os.MkdirAll(cfg.HomePath, 0755))
+			defer os.RemoveAll(cfg.HomePath)
+
+			g := NewGit(cfg)
+			prepareGitRepoForTestNewCmd(g)
+
+			fake := fakeio.Stdout()
+			defer fake.Restore()
+
+			cmd := &NewCmd{Config: cfg, Category: "memo", Filename: "foo"}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := g.Exec("log", "--format=%s")
+			if !auto {
+				if err == nil {
+					t.Fatal("Commit was created though auto-save is disabled:", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err, out)
+			}
+			if out != "Add memo/foo.md" {
+				t.Fatal("Unexpected commit:", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package notes
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// NotebooksCmd represents `notes notebooks` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type NotebooksCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// NameOnly is a flag equivalent to --name-only
+	NameOnly bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *NotebooksCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("notebooks", "List all notebooks configured in [notebooks] section of config file with their home directories. Current notebook is marked with '*'")
+	cmd.cli.Flag("name-only", "Show only names of notebooks").BoolVar(&cmd.NameOnly)
+}
+
+func (cmd *NotebooksCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes notebooks` command and returns an error if occurs
+func (cmd *NotebooksCmd) Do() error {
+	names := make([]string, 0, len(cmd.Config.Notebooks))
+	max := 0
+	for n := range cmd.Config.Notebooks {
+		names = append(names, n)
+		if w := runewidth.StringWidth(n); w > max {
+			max = w
+		}
+	}
+	sort.Strings(names)
+
+	out := bufio.NewWriter(cmd.Out)
+	for _, n := range names {
+		if cmd.NameOnly {
+			out.WriteString(n + "\n")
+			continue
+		}
+		if n == cmd.Config.Notebook {
+			green.Fprint(out, "* ")
+			green.Fprint(out, n)
+		} else {
+			out.WriteString("  " + n)
+		}
+		out.WriteString(strings.Repeat(" ", max-runewidth.StringWidth(n)+1))
+		out.WriteString(cmd.Config.Notebooks[n] + "\n")
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,69 @@
+package notes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestNotebooksCmd(t *testing.T) {
+	old := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = old }()
+
+	cfg := &Config{
+		HomePath: "/path/to/work",
+		Notebooks: map[string]string{
+			"work":     "/path/to/work",
+			"personal": "/path/to/personal",
+			"ノート":      "/path/to/multibyte",
+		},
+		Notebook: "work",
+	}
+
+	for _, tc := range []struct {
+		what     string
+		nameOnly bool
+		want     string
+	}{
+		{
+			what: "default",
+			want: "  personal /path/to/personal\n* work     /path/to/work\n  ノート   /path/to/multibyte\n",
+		},
+		{
+			what:     "name only",
+			nameOnly: true,
+			want:     "personal\nwork\nノート\n",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &NotebooksCmd{
+				Config:   cfg,
+				NameOnly: tc.nameOnly,
+				Out:      &buf,
+			}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have := buf.String(); have != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestNotebooksCmdNoNotebook(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &NotebooksCmd{
+		Config: &Config{HomePath: "/path/to/home"},
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("Unexpected output:", buf.String())
+	}
+}
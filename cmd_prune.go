@@ -0,0 +1,89 @@
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// PruneCmd represents `notes prune` command. Each public fields represent options of the command.
+// In and Out fields represent where this command should input and output.
+type PruneCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Yes is a flag equivalent to --yes
+	Yes bool
+	// In is a reader to read answer of confirmation. Kind of stdin is expected
+	In io.Reader
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *PruneCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("prune", "Remove empty category directories which contain no note. Removed directories are listed and confirmed before removing them")
+	cmd.cli.Flag("yes", "Remove empty directories without confirmation").Short('y').BoolVar(&cmd.Yes)
+}
+
+func (cmd *PruneCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *PruneCmd) confirm(num int) (bool, error) {
+	fmt.Fprintf(cmd.Out, "Remove %d empty directories? [y/N]: ", num)
+	s := bufio.NewScanner(cmd.In)
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return false, errors.Wrap(err, "Cannot read answer of confirmation")
+		}
+		// EOF means 'no'
+		fmt.Fprintln(cmd.Out)
+		return false, nil
+	}
+	a := strings.ToLower(strings.TrimSpace(s.Text()))
+	return a == "y" || a == "yes", nil
+}
+
+// Do runs `notes prune` command and returns an error if occurs
+func (cmd *PruneCmd) Do() error {
+	orphans, err := CollectOrphans(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans.EmptyDirs) == 0 {
+		fmt.Fprintln(cmd.Out, "No empty directory was found")
+		return nil
+	}
+
+	for _, d := range orphans.EmptyDirs {
+		rel, err := filepath.Rel(cmd.Config.HomePath, d)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.Out, filepath.ToSlash(rel)+"/")
+	}
+
+	if !cmd.Yes {
+		ok, err := cmd.confirm(len(orphans.EmptyDirs))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	for _, d := range orphans.EmptyDirs {
+		if err := os.RemoveAll(d); err != nil {
+			return errors.Wrapf(err, "Cannot remove empty directory '%s'", canonPath(d))
+		}
+	}
+
+	return nil
+}
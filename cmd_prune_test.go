@@ -0,0 +1,120 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func prepareHomeForPruneTest(t *testing.T) *Config {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	home := filepath.Join(cwd, "test-tmp-dir-prune")
+	for _, d := range []string{"a/b", "c", "d/.hidden", "e", "f"} {
+		panicIfErr(os.MkdirAll(filepath.Join(home, filepath.FromSlash(d)), 0755))
+	}
+	for _, f := range []string{"c/.gitkeep", "e/image.png"} {
+		panicIfErr(os.WriteFile(filepath.Join(home, filepath.FromSlash(f)), []byte{}, 0644))
+	}
+	t.Cleanup(func() { panicIfErr(os.RemoveAll(home)) })
+	return &Config{HomePath: home}
+}
+
+func TestPruneCmd(t *testing.T) {
+	for _, tc := range []struct {
+		what    string
+		yes     bool
+		input   string
+		removed bool
+	}{
+		{
+			what:    "confirmed",
+			input:   "y\n",
+			removed: true,
+		},
+		{
+			what:    "denied",
+			input:   "n\n",
+			removed: false,
+		},
+		{
+			what:    "EOF",
+			input:   "",
+			removed: false,
+		},
+		{
+			what:    "--yes",
+			yes:     true,
+			removed: true,
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			cfg := prepareHomeForPruneTest(t)
+
+			var buf bytes.Buffer
+			cmd := &PruneCmd{
+				Config: cfg,
+				Yes:    tc.yes,
+				In:     strings.NewReader(tc.input),
+				Out:    &buf,
+			}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			out := buf.String()
+			if !strings.HasPrefix(out, "a/\nf/\n") {
+				t.Fatal("Empty directories are not listed:", out)
+			}
+			if tc.yes == strings.Contains(out, "Remove 2 empty directories?") {
+				t.Fatal("Confirmation is unexpected:", out)
+			}
+
+			for _, d := range []string{"a", "f"} {
+				_, err := os.Stat(filepath.Join(cfg.HomePath, d))
+				if tc.removed != os.IsNotExist(err) {
+					t.Fatal("Directory", d, "should be removed:", tc.removed, "but stat result was", err)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(cfg.HomePath, "e", "image.png")); err != nil {
+				t.Fatal("Non-note file must not be removed:", err)
+			}
+			for _, p := range []string{"c/.gitkeep", "d/.hidden"} {
+				if _, err := os.Stat(filepath.Join(cfg.HomePath, filepath.FromSlash(p))); err != nil {
+					t.Fatal("Hidden file must not be removed:", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneCmdNothingToRemove(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+
+	var buf bytes.Buffer
+	cmd := &PruneCmd{
+		Config: &Config{HomePath: filepath.Join(cwd, "testdata", "category", "normal")},
+		In:     strings.NewReader(""),
+		Out:    &buf,
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if have := buf.String(); have != "No empty directory was found\n" {
+		t.Fatal("Unexpected output:", have)
+	}
+}
+
+func TestPruneCmdError(t *testing.T) {
+	cmd := &PruneCmd{
+		Config: &Config{HomePath: "/path/to/somewhere/unknown"},
+	}
+	err := cmd.Do()
+	if err == nil || !strings.Contains(err.Error(), "Cannot read home") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
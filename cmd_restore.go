@@ -0,0 +1,64 @@
+package notes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// RestoreCmd represents `notes restore` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type RestoreCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Note is a note to restore. It is an absolute path or a relative path from home such as
+	// "category/file.md"
+	Note string
+	// Revision is a Git revision to restore the note from
+	Revision string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *RestoreCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("restore", "Restore the note to older version at the revision using Git. Deleted note can also be restored. Restored note is not committed until `notes save`")
+	cmd.cli.Arg("note", "Note to restore. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted").Required().StringVar(&cmd.Note)
+	cmd.cli.Arg("rev", "Git revision to restore the note from such as commit hash shown by `notes log`").Required().StringVar(&cmd.Revision)
+}
+
+func (cmd *RestoreCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes restore` command and returns an error if occurs
+func (cmd *RestoreCmd) Do() error {
+	git, err := newGitForCmd("restore", cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	path, err := noteRelPath(cmd.Config.HomePath, cmd.Note)
+	if err != nil {
+		return err
+	}
+
+	content, err := git.FileAt(path, cmd.Revision)
+	if err != nil {
+		return err
+	}
+
+	p := filepath.Join(cmd.Config.HomePath, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return errors.Wrapf(err, "Cannot create category directory for '%s'", canonPath(p))
+	}
+	if err := os.WriteFile(p, content, 0644); err != nil {
+		return errors.Wrapf(err, "Cannot write note '%s'", canonPath(p))
+	}
+
+	fmt.Fprintf(cmd.Out, "Restored %s from %s\n", path, cmd.Revision)
+	return nil
+}
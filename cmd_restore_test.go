@@ -0,0 +1,99 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestoreCmd(t *testing.T) {
+	cfg, revs := testNewHistoryRepo("test-tmp-dir-restore")
+	defer os.RemoveAll(cfg.HomePath)
+
+	for _, tc := range []struct {
+		what string
+		note string
+		rev  string
+		want string
+	}{
+		{
+			what: "older version",
+			note: "memo/foo.md",
+			rev:  revs[0],
+			want: "first\n",
+		},
+		{
+			what: "deleted note",
+			note: "memo/bar",
+			rev:  revs[1],
+			want: "This is a note completely different from foo\n",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &RestoreCmd{Config: cfg, Note: tc.note, Revision: tc.rev, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			path := tc.note
+			if !strings.HasSuffix(path, ".md") {
+				path += ".md"
+			}
+			if want := "Restored " + path + " from " + tc.rev + "\n"; buf.String() != want {
+				t.Fatalf("Wanted output '%s' but have '%s'", want, buf.String())
+			}
+
+			b, err := os.ReadFile(filepath.Join(cfg.HomePath, filepath.FromSlash(path)))
+			panicIfErr(err)
+			if !strings.HasSuffix(string(b), tc.want) {
+				t.Fatal("Note was not restored:", string(b))
+			}
+		})
+	}
+}
+
+func TestRestoreCmdError(t *testing.T) {
+	cfg, revs := testNewHistoryRepo("test-tmp-dir-restore-error")
+	defer os.RemoveAll(cfg.HomePath)
+
+	for _, tc := range []struct {
+		what string
+		note string
+		rev  string
+		want string
+	}{
+		{
+			what: "note did not exist at the revision",
+			note: "memo/bar.md",
+			rev:  revs[0],
+			want: "Cannot retrieve 'memo/bar.md' at revision '" + revs[0] + "'",
+		},
+		{
+			what: "unknown revision",
+			note: "memo/foo.md",
+			rev:  "unknown-revision",
+			want: "Cannot retrieve 'memo/foo.md' at revision 'unknown-revision'",
+		},
+		{
+			what: "outside home",
+			note: "../foo.md",
+			rev:  revs[0],
+			want: "is not in home",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &RestoreCmd{Config: cfg, Note: tc.note, Revision: tc.rev, Out: &buf}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
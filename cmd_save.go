@@ -1,14 +1,56 @@
 package notes
 
 import (
+	"bytes"
+	"crypto/sha1"
 	"fmt"
 	"github.com/pkg/errors"
 	"gopkg.in/alecthomas/kingpin.v2"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
 	"time"
 )
 
+// defaultSaveMessageTemplate is a template of commit message used when no template is configured.
+// It generates a message like "Add blog/tech/foo.md (Intro to X); Update memo/tasks.md"
+const defaultSaveMessageTemplate = `{{range $i, $c := .Changes}}{{if $i}}; {{end}}{{$c.Action}} {{if $c.OldPath}}{{$c.OldPath}} to {{end}}{{$c.Path}}{{if $c.Title}} ({{$c.Title}}){{end}}{{end}}`
+
+func parseSaveMessageTemplate(src string) (*template.Template, error) {
+	t, err := template.New("save.message_template").Parse(src)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot parse template of commit message")
+	}
+	return t, nil
+}
+
+// saveChange represents a change of file in commit. This is passed to template of commit message
+type saveChange struct {
+	// Action is one of "Add", "Update", "Delete" or "Rename"
+	Action string
+	// Path is a slash-separated relative path of the file from home
+	Path string
+	// OldPath is a slash-separated relative path of the file before renamed. It is empty if not renamed
+	OldPath string
+	// Category is a category of the note
+	Category string
+	// Title is a title of the note. It is empty when the file is not a note, was deleted or its title
+	// is the same as its file name
+	Title string
+}
+
+// saveMessageData is data passed to template of commit message
+type saveMessageData struct {
+	// Changes is a list of changes in the commit
+	Changes []*saveChange
+	// Time is the time when the commit is created
+	Time time.Time
+}
+
 // SaveCmd represents `notes save` command. Each public fields represent options of the command
 type SaveCmd struct {
 	cli    *kingpin.CmdClause
@@ -16,46 +58,354 @@ type SaveCmd struct {
 	// Message is a message of Git commit which will be created to save notes. If this value is empty,
 	// automatically generated message will be used.
 	Message string
+	// Paths is a list of notes to save. Each note is an absolute path or a relative path from home such as
+	// "category/file.md". When Paths, Category and Tag are all empty, all changes in home are saved
+	Paths []string
+	// Category is a regular expression to select notes to save by their categories
+	Category string
+	// Tag is a regular expression to select notes to save by their tags
+	Tag string
+	// DryRun is a flag equivalent to --dry-run
+	DryRun bool
+	// NoPush is a flag equivalent to --no-push
+	NoPush bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
 }
 
 func (cmd *SaveCmd) defineCLI(app *kingpin.Application) {
-	cmd.cli = app.Command("save", "Save notes using Git. It adds all notes and creates a commit to Git repository at home directory")
-	cmd.cli.Flag("message", "Commit message on save. If omitted, an automatic message will be used").Short('m').StringVar(&cmd.Message)
+	cmd.cli = app.Command("save", "Save notes using Git. It adds all notes and creates a commit to Git repository at home directory. When current branch tracks a remote branch or 'save.remote' is configured, the commit is pushed to the remote")
+	cmd.cli.Flag("message", "Commit message on save. If omitted, an automatic message will be generated from changes of notes").Short('m').StringVar(&cmd.Message)
+	cmd.cli.Flag("category", "Save only notes whose categories match to the regular expression").Short('c').StringVar(&cmd.Category)
+	cmd.cli.Flag("tag", "Save only notes which have a tag matching to the regular expression").Short('t').StringVar(&cmd.Tag)
+	cmd.cli.Flag("dry-run", "Show changes which would be committed without committing them").BoolVar(&cmd.DryRun)
+	cmd.cli.Flag("no-push", "Only commit notes. Do not push them to remote even if 'save.remote' is configured or current branch tracks a remote branch").BoolVar(&cmd.NoPush)
+	cmd.cli.Arg("paths", "Notes to save. Path relative to home like 'category/file.md' or absolute path. '.md' can be omitted. If omitted, all changes are saved").StringsVar(&cmd.Paths)
 }
 
 func (cmd *SaveCmd) matchesCmdline(cmdline string) bool {
 	return cmd.cli.FullCommand() == cmdline
 }
 
-// Do runs `notes save` command and returns an error if occurs
-func (cmd *SaveCmd) Do() error {
-	git := NewGit(cmd.Config)
-	if git == nil {
-		return errors.New("'save' command cannot work without Git. Please check Git command listed in output of 'config' command is available")
+func (cmd *SaveCmd) changeOf(c *GitChange) *saveChange {
+	ch := &saveChange{
+		Path:     c.Path,
+		Category: path.Dir(c.Path),
+	}
+
+	switch c.Status {
+	case 'A', 'C':
+		ch.Action = "Add"
+	case 'D':
+		ch.Action = "Delete"
+	case 'R':
+		ch.Action = "Rename"
+		ch.OldPath = c.OldPath
+	default:
+		ch.Action = "Update"
 	}
 
-	if _, err := os.Stat(filepath.Join(cmd.Config.HomePath, ".git")); err != nil {
-		return errors.New("'.git' directory does not exist in home. Please create a new note with `notes new` at first")
+	if c.Status == 'D' || !strings.HasSuffix(c.Path, ".md") {
+		return ch
 	}
 
-	if err := git.AddAll(); err != nil {
+	// Note: LoadNote returns a note even if its category mismatches to its path
+	note, _ := LoadNote(filepath.Join(cmd.Config.HomePath, filepath.FromSlash(c.Path)), cmd.Config)
+	if note == nil {
+		return ch
+	}
+	ch.Category = note.Category
+	if note.Title != strings.TrimSuffix(note.File, ".md") {
+		ch.Title = note.Title
+	}
+
+	return ch
+}
+
+func (cmd *SaveCmd) generateMessage(git *Git, paths ...string) (string, error) {
+	src := cmd.Config.Save.MessageTemplate
+	if src == "" {
+		src = defaultSaveMessageTemplate
+	}
+	tmpl, err := parseSaveMessageTemplate(src)
+	if err != nil {
+		return "", err
+	}
+
+	changes, err := git.StagedChanges(paths...)
+	if err != nil {
+		return "", err
+	}
+
+	data := &saveMessageData{
+		Changes: make([]*saveChange, 0, len(changes)),
+		Time:    time.Now(),
+	}
+	for _, c := range changes {
+		data.Changes = append(data.Changes, cmd.changeOf(c))
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", errors.Wrap(err, "Cannot generate commit message from template")
+	}
+
+	msg := strings.TrimSpace(b.String())
+	if msg == "" {
+		// Fallback when no change is staged or template generates nothing
+		msg = fmt.Sprintf("Saved by notes CLI at %s", data.Time.Format(time.RFC3339))
+	}
+	return msg, nil
+}
+
+// selectedPaths returns slash-separated relative paths of notes selected by arguments and filters. It
+// returns nil when no note is selected explicitly, which means all changes should be saved
+func (cmd *SaveCmd) selectedPaths() ([]string, error) {
+	if len(cmd.Paths) == 0 && cmd.Category == "" && cmd.Tag == "" {
+		return nil, nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, p := range cmd.Paths {
+		rel, err := noteRelPath(cmd.Config.HomePath, p)
+		if err != nil {
+			return nil, err
+		}
+		seen[rel] = struct{}{}
+	}
+
+	if cmd.Category != "" || cmd.Tag != "" {
+		catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+		if err != nil {
+			return nil, err
+		}
+
+		notes, err := collectNotes(cmd.Config, catReg, tagReg)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			seen[filepath.ToSlash(n.RelFilePath())] = struct{}{}
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (cmd *SaveCmd) printChanges(git *Git, paths []string) error {
+	changes, err := git.Changes(paths...)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(cmd.Out, "Nothing to commit")
+		return err
+	}
+
+	var b strings.Builder
+	for _, c := range changes {
+		ch := cmd.changeOf(c)
+		b.WriteString(ch.Action + " ")
+		if ch.OldPath != "" {
+			b.WriteString(ch.OldPath + " to ")
+		}
+		b.WriteString(ch.Path + "\n")
+	}
+	_, err = io.WriteString(cmd.Out, b.String())
+	return err
+}
+
+func (cmd *SaveCmd) commit(git *Git, paths []string) error {
+	var err error
+	if paths == nil {
+		err = git.AddAll()
+	} else {
+		err = git.Add(paths...)
+	}
+	if err != nil {
 		return err
 	}
 
 	msg := cmd.Message
 	if msg == "" {
-		// TODO: More helpful commit message for future git-grep
-		msg = fmt.Sprintf("Saved by notes CLI at %s", time.Now().Format(time.RFC3339))
+		m, err := cmd.generateMessage(git, paths...)
+		if err != nil {
+			return err
+		}
+		msg = m
 	}
-	if err := git.Commit(msg); err != nil {
-		return err
+	return git.Commit(msg, paths...)
+}
+
+// pushTarget returns remote name and branch name to push notes. When no remote is found, it returns
+// empty strings
+func (cmd *SaveCmd) pushTarget(git *Git) (string, string, error) {
+	remote, branch := cmd.Config.Save.Remote, cmd.Config.Save.Branch
+	if remote != "" && branch != "" {
+		return remote, branch, nil
 	}
 
-	if remote, branch, err := git.TrackingRemote(); err == nil && remote == "origin" {
-		if err := git.Push(remote, branch); err != nil {
-			return errors.Wrap(err, "Cannot push to 'origin' remote")
+	if r, b, err := git.TrackingRemote(); err == nil {
+		if remote == "" {
+			remote = r
+		}
+		if branch == "" {
+			branch = b
 		}
+		return remote, branch, nil
 	}
 
+	if remote == "" {
+		// Notes are not pushed when current branch tracks no branch and no remote is configured
+		return "", "", nil
+	}
+
+	b, err := git.CurrentBranch()
+	if err != nil {
+		return "", "", err
+	}
+	return remote, b, nil
+}
+
+func (cmd *SaveCmd) push(git *Git) error {
+	if cmd.NoPush {
+		return nil
+	}
+
+	remote, branch, err := cmd.pushTarget(git)
+	if err != nil {
+		return err
+	}
+	if remote == "" {
+		return nil
+	}
+
+	if err := git.Push(remote, branch); err != nil {
+		return errors.Wrapf(err, "Cannot push to '%s' remote", remote)
+	}
 	return nil
 }
+
+// Do runs `notes save` command and returns an error if occurs
+func (cmd *SaveCmd) Do() error {
+	git, err := newGitForCmd("save", cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	paths, err := cmd.selectedPaths()
+	if err != nil {
+		return err
+	}
+	if paths != nil && len(paths) == 0 {
+		return errors.New("No note matched to --category and --tag")
+	}
+
+	if cmd.DryRun {
+		return cmd.printChanges(git, paths)
+	}
+
+	if err := cmd.commit(git, paths); err != nil {
+		return err
+	}
+
+	return cmd.push(git)
+}
+
+// autoSave commits changes of given notes when auto-save is enabled with 'save.auto' config. Notes
+// which were not changed are ignored. When 'save.auto_push' config is also enabled, the commit is pushed
+// as `notes save` does. paths are absolute paths of notes
+func autoSave(cfg *Config, paths ...string) error {
+	if !cfg.Save.Auto || len(paths) == 0 {
+		return nil
+	}
+
+	git, err := newGitForCmd("save", cfg)
+	if err != nil {
+		// Auto-save is skipped silently when Git repository is not available
+		return nil
+	}
+
+	return errors.Wrap(commitNotes(git, cfg, cfg.Save.AutoPush, paths...), "Cannot save notes automatically")
+}
+
+// notesSnapshot records hashes of contents of notes taken before opening an editor. It is used to know
+// which notes were actually changed in the editor so that changes made before are not auto-saved
+type notesSnapshot struct {
+	paths  []string
+	hashes map[string][sha1.Size]byte
+}
+
+// takeNotesSnapshot takes a snapshot of given notes. Notes which do not exist yet are recorded as missing
+func takeNotesSnapshot(paths ...string) *notesSnapshot {
+	s := &notesSnapshot{paths: paths, hashes: make(map[string][sha1.Size]byte, len(paths))}
+	for _, p := range paths {
+		if b, err := os.ReadFile(p); err == nil {
+			s.hashes[p] = sha1.Sum(b)
+		}
+	}
+	return s
+}
+
+// changed returns paths of notes whose contents are different from the snapshot. Created and removed
+// notes are also considered as changed
+func (s *notesSnapshot) changed() []string {
+	changed := []string{}
+	for _, p := range s.paths {
+		prev, existed := s.hashes[p]
+		b, err := os.ReadFile(p)
+		if err != nil {
+			if existed {
+				changed = append(changed, p)
+			}
+			continue
+		}
+		if !existed || sha1.Sum(b) != prev {
+			changed = append(changed, p)
+		}
+	}
+	return changed
+}
+
+// commitNotes commits changes of given notes with a message generated from the changes. Notes which have
+// no change are ignored. When push is true, the commit is pushed to the remote
+func commitNotes(git *Git, cfg *Config, push bool, paths ...string) error {
+	rels := make([]string, 0, len(paths))
+	for _, p := range paths {
+		rel, err := noteRelPath(cfg.HomePath, p)
+		if err != nil {
+			return err
+		}
+		rels = append(rels, rel)
+	}
+
+	changes, err := git.Changes(rels...)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	changed := make([]string, 0, len(changes))
+	for _, c := range changes {
+		changed = append(changed, c.Path)
+	}
+
+	cmd := &SaveCmd{Config: cfg}
+	if err := cmd.commit(git, changed); err != nil {
+		return err
+	}
+
+	if !push {
+		return nil
+	}
+
+	return cmd.push(git)
+}
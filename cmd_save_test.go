@@ -1,9 +1,10 @@
 package notes
 
 import (
-	"github.com/rhysd/go-tmpenv"
+	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -18,8 +19,12 @@ func testNewConfigForSaveCmd(subdir string) *Config {
 }
 
 func prepareGitRepoForTestNewCmd(g *Git) {
-	panicIfErr(g.Init())
-	_, err := g.Exec("config", "user.name", "You")
+	// Note: Git.Init() is not used since it writes .gitignore into testdata directory
+	out, err := g.Exec("init")
+	if err != nil {
+		panic(out)
+	}
+	_, err = g.Exec("config", "user.name", "You")
 	panicIfErr(err)
 	_, err = g.Exec("config", "user.email", "you@example.com")
 	panicIfErr(err)
@@ -49,7 +54,7 @@ func TestSaveCmd(t *testing.T) {
 		{
 			what: "no message",
 			msg:  "",
-			want: "Saved by notes CLI at",
+			want: "Add cat/1.md (this is title)",
 		},
 		{
 			what: "with message",
@@ -117,41 +122,417 @@ func TestSaveCmdAddNothing(t *testing.T) {
 }
 
 func TestSaveCmdCannotPush(t *testing.T) {
-	if _, ok := os.LookupEnv("APPVEYOR"); ok {
-		t.Skip("Pushing to not permitted repository hangs on Appveyor")
+	repos := newTestSyncRepos("test-tmp-dir-save-cannot-push")
+	defer repos.cleanup()
+
+	cfg := repos.clone("a")
+	g := NewGit(cfg)
+	writeTestNote(cfg, "memo/foo.md", testSyncNote)
+	panicIfErr(g.AddAll())
+	panicIfErr(g.Commit("hello"))
+	b, err := g.CurrentBranch()
+	panicIfErr(err)
+	panicIfErr(g.Push("origin", b))
+
+	// Remote repository is lost
+	panicIfErr(os.RemoveAll(repos.remote))
+
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"updated\n")
+	cmd := &SaveCmd{
+		Config: cfg,
+	}
+
+	err = cmd.Do()
+	if err == nil {
+		t.Fatal("No error occurred")
+	}
+	if !strings.Contains(err.Error(), "Cannot push to 'origin' remote") {
+		t.Fatal("Unexpected output:", err)
+	}
+}
+
+func TestSaveCmdPush(t *testing.T) {
+	for _, tc := range []struct {
+		what   string
+		noPush bool
+		remote string
+		branch string
+		want   string
+	}{
+		{
+			what: "tracking branch of non-origin remote",
+			want: "feature/notes",
+		},
+		{
+			what:   "configured branch",
+			branch: "backup/notes",
+			want:   "backup/notes",
+		},
+		{
+			what:   "configured remote",
+			remote: "other",
+			want:   "feature/notes",
+		},
+		{
+			what:   "no push",
+			noPush: true,
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			repos := newTestSyncRepos("test-tmp-dir-save-push")
+			defer repos.cleanup()
+
+			other := filepath.Join(repos.root, "other.git")
+			out, err := (&Git{bin: "git", root: repos.root}).Exec("init", "--bare", other)
+			if err != nil {
+				t.Fatal(err, out)
+			}
+
+			cfg := repos.clone("a")
+			g := NewGit(cfg)
+			for _, args := range [][]string{
+				{"remote", "rename", "origin", "my-remote"},
+				{"remote", "add", "other", other},
+				{"checkout", "-b", "feature/notes"},
+			} {
+				if out, err := g.Exec(args[0], args[1:]...); err != nil {
+					t.Fatal(err, out)
+				}
+			}
+			writeTestNote(cfg, "memo/foo.md", testSyncNote)
+			panicIfErr(g.AddAll())
+			panicIfErr(g.Commit("hello"))
+			panicIfErr(g.Push("my-remote", "feature/notes"))
+
+			writeTestNote(cfg, "memo/foo.md", testSyncNote+"updated\n")
+			cfg.Save.Remote = tc.remote
+			cfg.Save.Branch = tc.branch
+			cmd := &SaveCmd{Config: cfg, NoPush: tc.noPush}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			remote := repos.remote
+			if tc.remote == "other" {
+				remote = other
+			}
+			out, err = (&Git{bin: "git", root: remote}).Exec("log", "--format=%s", "--all")
+			panicIfErr(err)
+			pushed := strings.HasPrefix(out, "Update memo/foo.md")
+			if tc.want == "" {
+				if pushed {
+					t.Fatal("Commit was pushed with --no-push:", out)
+				}
+				return
+			}
+			if !pushed {
+				t.Fatal("Commit was not pushed:", out)
+			}
+
+			out, err = (&Git{bin: "git", root: remote}).Exec("log", "--format=%s", "-1", tc.want)
+			if err != nil {
+				t.Fatal("Branch", tc.want, "was not pushed:", out)
+			}
+			if out != "Update memo/foo.md" {
+				t.Fatal("Unexpected commit in", tc.want, out)
+			}
+
+			// Upstream is not changed by pushing to other remote or branch
+			re, br, err := g.TrackingRemote()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if re != "my-remote" || br != "feature/notes" {
+				t.Fatal("Upstream was changed:", re, br)
+			}
+		})
 	}
+}
 
-	tmp := tmpenv.New()
-	defer func() { panicIfErr(tmp.Restore()) }()
-	panicIfErr(tmp.Setenv("GIT_TERMINAL_PROMPT", "0"))
+func TestSaveCmdNoRemoteToPush(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-save-no-remote")
+	defer repos.cleanup()
+
+	cfg := &Config{GitPath: "git", HomePath: filepath.Join(repos.root, "a")}
+	panicIfErr(os.MkdirAll(cfg.HomePath, 0755))
+	g := NewGit(cfg)
+	prepareGitRepoForTestNewCmd(g)
+	if out, err := g.Exec("remote", "add", "origin", repos.remote); err != nil {
+		t.Fatal(err, out)
+	}
+	writeTestNote(cfg, "memo/foo.md", testSyncNote)
+
+	// Current branch tracks nothing so notes are only committed
+	cmd := &SaveCmd{Config: cfg}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
+	}
 
+	out, err := (&Git{bin: "git", root: repos.remote}).Exec("log", "--all", "--format=%s")
+	panicIfErr(err)
+	if out != "" {
+		t.Fatal("Notes were pushed:", out)
+	}
+}
+
+func TestSaveCmdMessageTemplate(t *testing.T) {
+	cfg := testNewConfigForSaveCmd("normal")
+	g := NewGit(cfg)
+	for _, tc := range []struct {
+		what string
+		tmpl string
+		want string
+	}{
+		{
+			what: "custom template",
+			tmpl: `notes: {{range .Changes}}[{{.Category}}] {{.Title}}{{end}}`,
+			want: "notes: [cat] this is title",
+		},
+		{
+			what: "template generates empty message",
+			tmpl: `{{if false}}never{{end}}`,
+			want: "Saved by notes CLI at",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			prepareGitRepoForTestNewCmd(g)
+			defer os.RemoveAll(filepath.Join(cfg.HomePath, ".git"))
+
+			c := *cfg
+			c.Save.MessageTemplate = tc.tmpl
+			cmd := &SaveCmd{Config: &c}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			log, err := g.Exec("log", "--format=%s")
+			panicIfErr(err)
+			if !strings.HasPrefix(log, tc.want) {
+				t.Fatal("Unexpected log", log)
+			}
+		})
+	}
+}
+
+func TestSaveCmdBrokenMessageTemplate(t *testing.T) {
 	cfg := testNewConfigForSaveCmd("normal")
 	g := NewGit(cfg)
 	prepareGitRepoForTestNewCmd(g)
 	defer os.RemoveAll(filepath.Join(cfg.HomePath, ".git"))
 
-	if out, err := g.Exec("remote", "add", "origin", "https://github.com/rhysd-test/empty.git"); err != nil {
-		t.Fatal(err, out)
+	cfg.Save.MessageTemplate = "{{.Unknown}"
+	cmd := &SaveCmd{Config: cfg}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
 	}
-	if out, err := g.Exec("fetch"); err != nil {
-		t.Fatal(err, out)
+	if !strings.Contains(err.Error(), "Cannot parse template of commit message") {
+		t.Fatal("Unexpected error:", err)
 	}
-	if out, err := g.Exec("commit", "--allow-empty", "--allow-empty-message", "-m", ""); err != nil {
-		t.Fatal(err, out)
+}
+
+func testNewRepoForSaveSelected(dir string) *Config {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{GitPath: "git", HomePath: filepath.Join(cwd, dir)}
+	panicIfErr(os.MkdirAll(cfg.HomePath, 0755))
+	prepareGitRepoForTestNewCmd(NewGit(cfg))
+
+	writeTestNote(cfg, "memo/foo.md", "foo\n===\n- Category: memo\n- Tags: dog\n- Created: 2018-10-30T11:37:45+09:00\n\n")
+	writeTestNote(cfg, "memo/draft.md", "draft\n===\n- Category: memo\n- Tags: cat\n- Created: 2018-10-30T11:37:45+09:00\n\n")
+	writeTestNote(cfg, "blog/post.md", "post\n===\n- Category: blog\n- Tags: dog, cat\n- Created: 2018-10-30T11:37:45+09:00\n\n")
+	return cfg
+}
+
+func TestSaveCmdSelectedNotes(t *testing.T) {
+	for _, tc := range []struct {
+		what      string
+		paths     []string
+		category  string
+		tag       string
+		committed []string
+	}{
+		{
+			what:      "paths",
+			paths:     []string{"memo/foo", "blog/post.md"},
+			committed: []string{"blog/post.md", "memo/foo.md"},
+		},
+		{
+			what:      "category",
+			category:  "^memo$",
+			committed: []string{"memo/draft.md", "memo/foo.md"},
+		},
+		{
+			what:      "tag",
+			tag:       "^dog$",
+			committed: []string{"blog/post.md", "memo/foo.md"},
+		},
+		{
+			what:      "category and tag",
+			category:  "^memo$",
+			tag:       "^cat$",
+			committed: []string{"memo/draft.md"},
+		},
+		{
+			what:      "paths and category",
+			paths:     []string{"memo/draft.md"},
+			category:  "^blog$",
+			committed: []string{"blog/post.md", "memo/draft.md"},
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			cfg := testNewRepoForSaveSelected("test-tmp-dir-save-selected")
+			defer os.RemoveAll(cfg.HomePath)
+
+			var buf bytes.Buffer
+			cmd := &SaveCmd{
+				Config:   cfg,
+				Paths:    tc.paths,
+				Category: tc.category,
+				Tag:      tc.tag,
+				Out:      &buf,
+			}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := NewGit(cfg).Exec("show", "--name-only", "--format=", "HEAD")
+			panicIfErr(err)
+			have := strings.Split(out, "\n")
+			if !reflect.DeepEqual(have, tc.committed) {
+				t.Fatal("Unexpected committed files:", have)
+			}
+		})
 	}
-	if out, err := g.Exec("branch", "--set-upstream-to", "origin/master", "master"); err != nil {
-		t.Fatal(err, out)
+}
+
+func TestSaveCmdSelectedNotesKeepOtherStagedChanges(t *testing.T) {
+	cfg := testNewRepoForSaveSelected("test-tmp-dir-save-staged")
+	defer os.RemoveAll(cfg.HomePath)
+
+	g := NewGit(cfg)
+	panicIfErr(g.Add("memo/draft.md"))
+
+	cmd := &SaveCmd{Config: cfg, Paths: []string{"memo/foo.md"}}
+	if err := cmd.Do(); err != nil {
+		t.Fatal(err)
 	}
 
-	cmd := &SaveCmd{
-		Config: cfg,
+	out, err := g.Exec("log", "--format=%s")
+	panicIfErr(err)
+	if out != "Add memo/foo.md" {
+		t.Fatal("Unexpected commit message:", out)
 	}
 
-	err := cmd.Do()
-	if err == nil {
-		t.Fatal("No error occurred")
+	staged, err := g.StagedChanges()
+	panicIfErr(err)
+	if len(staged) != 1 || staged[0].Path != "memo/draft.md" {
+		t.Fatal("Staged change of other note should be kept:", staged)
 	}
-	if !strings.Contains(err.Error(), "Cannot push to 'origin' remote") {
-		t.Fatal("Unexpected output:", err)
+}
+
+func TestSaveCmdDryRun(t *testing.T) {
+	cfg := testNewRepoForSaveSelected("test-tmp-dir-save-dry-run")
+	defer os.RemoveAll(cfg.HomePath)
+
+	g := NewGit(cfg)
+	panicIfErr(g.AddAll())
+	panicIfErr(g.Commit("initial"))
+
+	writeTestNote(cfg, "memo/foo.md", "foo\n===\n- Category: memo\n- Tags: dog\n- Created: 2018-10-30T11:37:45+09:00\n\nupdated\n")
+	writeTestNote(cfg, "memo/new.md", "new\n===\n- Category: memo\n- Tags:\n- Created: 2018-10-30T11:37:45+09:00\n\n")
+	panicIfErr(os.Remove(filepath.Join(cfg.HomePath, "blog", "post.md")))
+
+	for _, tc := range []struct {
+		what  string
+		paths []string
+		want  string
+	}{
+		{
+			what: "all",
+			want: "Delete blog/post.md\nUpdate memo/foo.md\nAdd memo/new.md\n",
+		},
+		{
+			what:  "paths",
+			paths: []string{"memo/new.md", "blog/post.md"},
+			want:  "Delete blog/post.md\nAdd memo/new.md\n",
+		},
+		{
+			what:  "no change",
+			paths: []string{"memo/draft.md"},
+			want:  "Nothing to commit\n",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &SaveCmd{Config: cfg, Paths: tc.paths, DryRun: true, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, buf.String())
+			}
+
+			out, err := g.Exec("log", "--format=%s")
+			panicIfErr(err)
+			if out != "initial" {
+				t.Fatal("Commit was created on dry run:", out)
+			}
+		})
+	}
+}
+
+func TestSaveCmdSelectedNotesError(t *testing.T) {
+	cfg := testNewRepoForSaveSelected("test-tmp-dir-save-selected-error")
+	defer os.RemoveAll(cfg.HomePath)
+
+	for _, tc := range []struct {
+		what     string
+		paths    []string
+		category string
+		tag      string
+		want     string
+	}{
+		{
+			what:     "no note matched",
+			category: "^unknown$",
+			want:     "No note matched to --category and --tag",
+		},
+		{
+			what:     "broken category regex",
+			category: "(foo",
+			want:     "Regular expression for filtering categories is invalid",
+		},
+		{
+			what: "broken tag regex",
+			tag:  "(foo",
+			want: "Regular expression for filtering tags is invalid",
+		},
+		{
+			what:  "unknown note",
+			paths: []string{"memo/unknown.md"},
+			want:  "Cannot add files to index tree",
+		},
+		{
+			what:  "outside home",
+			paths: []string{"../foo.md"},
+			want:  "is not in home",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			cmd := &SaveCmd{
+				Config:   cfg,
+				Paths:    tc.paths,
+				Category: tc.category,
+				Tag:      tc.tag,
+			}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
 	}
 }
@@ -0,0 +1,583 @@
+package notes
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var serveSortKinds = []string{"created", "modified", "filename", "category"}
+
+// serveNote is a JSON representation of note in API responses. Body is omitted in list of notes
+type serveNote struct {
+	Path     string    `json:"path"`
+	Category string    `json:"category"`
+	File     string    `json:"file"`
+	Title    string    `json:"title"`
+	Tags     []string  `json:"tags"`
+	Created  time.Time `json:"created"`
+	Body     *string   `json:"body,omitempty"`
+}
+
+// serveNoteRequest is a JSON request body to create or update a note. Only Body is used on update
+type serveNoteRequest struct {
+	Category string   `json:"category"`
+	File     string   `json:"file"`
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags"`
+	Body     *string  `json:"body"`
+}
+
+// serveMaxRequestBytes is a max size of request body of write API
+const serveMaxRequestBytes = 10 * 1024 * 1024
+
+func newServeNote(note *Note) *serveNote {
+	tags := note.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &serveNote{
+		Path:     filepath.ToSlash(note.RelFilePath()),
+		Category: note.Category,
+		File:     note.File,
+		Title:    note.Title,
+		Tags:     tags,
+		Created:  note.Created,
+	}
+}
+
+// ServeCmd represents `notes serve` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type ServeCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Addr is an address to listen. This value is equivalent to --addr option
+	Addr string
+	// Writable is a flag to enable write API. This value is equivalent to --writable option
+	Writable bool
+	// Token is a token required for requests to write API. Empty means no authentication. This value is
+	// equivalent to --token option
+	Token string
+	// Commit is a flag to commit each change by write API with Git. This value is equivalent to --commit
+	// option
+	Commit bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+
+	renderer *htmlRenderer
+	git      *Git
+	// mu serializes changes to notes and Git operations by write API
+	mu sync.Mutex
+}
+
+func (cmd *ServeCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("serve", "Serve web UI to browse and search notes, and JSON API (/api/notes, /api/notes/{category}/{file}) over HTTP. It is read-only unless --writable is given")
+	cmd.cli.Flag("addr", "Address to listen").Short('a').Default("127.0.0.1:8080").StringVar(&cmd.Addr)
+	cmd.cli.Flag("writable", "Enable write API to create (POST /api/notes), update (PUT /api/notes/{category}/{file}) and delete (DELETE /api/notes/{category}/{file}) notes. Deleted notes are moved to .trash directory").Short('w').BoolVar(&cmd.Writable)
+	cmd.cli.Flag("token", "Token required for write API as 'Authorization: Bearer {token}' header").Envar("NOTES_CLI_SERVE_TOKEN").StringVar(&cmd.Token)
+	cmd.cli.Flag("commit", "Commit each change by write API with Git. The commit is pushed when 'save.auto_push' is configured").BoolVar(&cmd.Commit)
+}
+
+func (cmd *ServeCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *ServeCmd) defaultSort() string {
+	if cmd.Config.List.SortBy != "" {
+		return cmd.Config.List.SortBy
+	}
+	return "created"
+}
+
+// filterNotes collects notes filtered and sorted in the same way as `notes list`
+func (cmd *ServeCmd) filterNotes(category, tag, sortBy string) ([]*Note, error) {
+	catReg, tagReg, err := compileFilters(category, tag)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := collectNotes(cmd.Config, catReg, tagReg)
+	if err != nil {
+		return nil, err
+	}
+	if sortBy == "" {
+		sortBy = cmd.defaultSort()
+	}
+	if err := sortNotes(notes, sortBy); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// resolvePath converts slash-separated path in URL into file path in home. It returns false when the
+// path is not safe to serve such as hidden files or paths outside home
+func (cmd *ServeCmd) resolvePath(rel string) (string, bool) {
+	if rel == "" || path.Clean("/"+rel) != "/"+rel {
+		return "", false
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	return filepath.Join(cmd.Config.HomePath, filepath.FromSlash(rel)), true
+}
+
+// loadNote loads a note at slash-separated relative path from home. It returns nil when the note does not
+// exist
+func (cmd *ServeCmd) loadNote(rel string) (*Note, error) {
+	p, ok := cmd.resolvePath(rel)
+	if !ok || !strings.Contains(rel, "/") || !strings.HasSuffix(rel, ".md") {
+		return nil, nil
+	}
+	if s, err := os.Stat(p); err != nil || s.IsDir() {
+		return nil, nil
+	}
+	return LoadNote(p, cmd.Config)
+}
+
+func (cmd *ServeCmd) writePage(w http.ResponseWriter, status int, kind string, page *exportHTMLPage) {
+	if page.Root == "" {
+		page.Root = "/"
+	}
+	if page.Search == nil {
+		page.Search = &exportHTMLSearchForm{Sort: cmd.defaultSort(), Sorts: serveSortKinds}
+	}
+
+	var b bytes.Buffer
+	if err := cmd.renderer.render(&b, kind, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(b.Bytes())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		status = http.StatusInternalServerError
+		b = []byte(fmt.Sprintf(`{"error": %q}`, err.Error()))
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(b)
+	w.Write([]byte{'\n'})
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (cmd *ServeCmd) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "/index.html" {
+		http.NotFound(w, r)
+		return
+	}
+	site, err := collectHTMLSite(cmd.Config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cmd.writePage(w, http.StatusOK, "index", site.index)
+}
+
+func (cmd *ServeCmd) handleTag(w http.ResponseWriter, r *http.Request) {
+	file := strings.TrimPrefix(r.URL.Path, "/tags/")
+	site, err := collectHTMLSite(cmd.Config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, t := range site.tags {
+		if exportHTMLTagFile(t) == file {
+			cmd.writePage(w, http.StatusOK, "tag", tagPage(t, site.tagged[t], "/"))
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (cmd *ServeCmd) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	form := &exportHTMLSearchForm{
+		Category: q.Get("category"),
+		Tag:      q.Get("tag"),
+		Sort:     q.Get("sort"),
+		Sorts:    serveSortKinds,
+	}
+	if form.Sort == "" {
+		form.Sort = cmd.defaultSort()
+	}
+	page := &exportHTMLPage{Title: "Search", Search: form}
+
+	notes, err := cmd.filterNotes(form.Category, form.Tag, form.Sort)
+	if err != nil {
+		page.Error = err.Error()
+		cmd.writePage(w, http.StatusBadRequest, "search", page)
+		return
+	}
+	page.Notes = make([]exportHTMLLink, 0, len(notes))
+	for _, n := range notes {
+		page.Notes = append(page.Notes, exportHTMLLinkOf(n, "/"))
+	}
+	cmd.writePage(w, http.StatusOK, "search", page)
+}
+
+// handleNotes serves a page of note for path ending with .html. Other paths are served as local files in
+// home such as images linked from notes
+func (cmd *ServeCmd) handleNotes(w http.ResponseWriter, r *http.Request) {
+	rel := strings.TrimPrefix(r.URL.Path, "/notes/")
+
+	if !strings.HasSuffix(rel, ".html") {
+		p, ok := cmd.resolvePath(rel)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if s, err := os.Stat(p); err != nil || s.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, p)
+		return
+	}
+
+	note, err := cmd.loadNote(strings.TrimSuffix(rel, ".html") + ".md")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if note == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	page, err := cmd.renderer.notePage(note, "/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cmd.writePage(w, http.StatusOK, "note", page)
+}
+
+func (cmd *ServeCmd) handleAPINotes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sortBy := q.Get("sort")
+	if sortBy != "" {
+		valid := false
+		for _, k := range serveSortKinds {
+			valid = valid || k == sortBy
+		}
+		if !valid {
+			writeJSONError(w, http.StatusBadRequest, errors.Errorf("Unknown sort kind '%s'. It must be one of %s", sortBy, strings.Join(serveSortKinds, ", ")))
+			return
+		}
+	}
+
+	notes, err := cmd.filterNotes(q.Get("category"), q.Get("tag"), sortBy)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ret := make([]*serveNote, 0, len(notes))
+	for _, n := range notes {
+		ret = append(ret, newServeNote(n))
+	}
+	writeJSON(w, http.StatusOK, ret)
+}
+
+func (cmd *ServeCmd) handleAPINote(w http.ResponseWriter, r *http.Request) {
+	rel := strings.TrimPrefix(r.URL.Path, "/api/notes/")
+	note, err := cmd.loadNote(rel)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if note == nil {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("Note '%s' is not found", rel))
+		return
+	}
+
+	cmd.writeNote(w, http.StatusOK, note)
+}
+
+// decodeNoteRequest decodes JSON request body of write API
+func decodeNoteRequest(w http.ResponseWriter, r *http.Request) (*serveNoteRequest, error) {
+	var req serveNoteRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, serveMaxRequestBytes)).Decode(&req); err != nil {
+		return nil, errors.Wrap(err, "Cannot parse request body as JSON")
+	}
+	return &req, nil
+}
+
+// save commits changes of the note when --commit is enabled
+func (cmd *ServeCmd) save(note *Note) error {
+	if cmd.git == nil {
+		return nil
+	}
+	return commitNotes(cmd.git, cmd.Config, cmd.Config.Save.AutoPush, note.FilePath())
+}
+
+func (cmd *ServeCmd) writeNote(w http.ResponseWriter, status int, note *Note) {
+	body, err := note.ReadBody()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	n := newServeNote(note)
+	n.Body = &body
+	writeJSON(w, status, n)
+}
+
+func (cmd *ServeCmd) handleAPICreate(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeNoteRequest(w, r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	file := req.File
+	if file == "" {
+		if strings.TrimSpace(req.Title) == "" {
+			writeJSONError(w, http.StatusBadRequest, errors.New("'file' or 'title' is required to create a note"))
+			return
+		}
+		file = fileNameOfTitle(req.Title)
+	} else if strings.ContainsAny(file, `/\`) || strings.Contains(file, "..") {
+		writeJSONError(w, http.StatusBadRequest, errors.Errorf("'file' cannot contain '/', '\\' nor '..' but got '%s'", file))
+		return
+	}
+
+	note, err := NewNote(req.Category, strings.Join(req.Tags, ","), file, req.Title, cmd.Config)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !isInDir(cmd.Config.HomePath, note.FilePath()) {
+		writeJSONError(w, http.StatusBadRequest, errors.Errorf("Note '%s' is not in home", filepath.ToSlash(note.RelFilePath())))
+		return
+	}
+	if _, err := os.Stat(note.FilePath()); err == nil {
+		writeJSONError(w, http.StatusConflict, errors.Errorf("Note '%s' already exists", filepath.ToSlash(note.RelFilePath())))
+		return
+	}
+
+	body := ""
+	if req.Body != nil {
+		body = *req.Body
+	}
+	if err := createNoteWithBody(note, body); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := cmd.save(note); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrap(err, "Note was created but could not be committed"))
+		return
+	}
+
+	w.Header().Set("Location", "/api/notes/"+urlOfPath(filepath.ToSlash(note.RelFilePath())))
+	cmd.writeNote(w, http.StatusCreated, note)
+}
+
+func (cmd *ServeCmd) handleAPIUpdate(w http.ResponseWriter, r *http.Request) {
+	rel := strings.TrimPrefix(r.URL.Path, "/api/notes/")
+	note, err := cmd.loadNote(rel)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if note == nil {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("Note '%s' is not found", rel))
+		return
+	}
+
+	req, err := decodeNoteRequest(w, r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Body == nil {
+		writeJSONError(w, http.StatusBadRequest, errors.New("'body' is required to update a note"))
+		return
+	}
+
+	if err := note.WriteBody(*req.Body); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := cmd.save(note); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrap(err, "Note was updated but could not be committed"))
+		return
+	}
+
+	cmd.writeNote(w, http.StatusOK, note)
+}
+
+// trashPath returns a path where the note is moved on deletion. When a file already exists at the path,
+// timestamp is added to the file name
+func (cmd *ServeCmd) trashPath(rel string) string {
+	p := filepath.Join(cmd.Config.HomePath, ".trash", filepath.FromSlash(rel))
+	if _, err := os.Stat(p); err != nil {
+		return p
+	}
+	ext := filepath.Ext(p)
+	return strings.TrimSuffix(p, ext) + "-" + time.Now().Format("20060102150405") + ext
+}
+
+func (cmd *ServeCmd) handleAPIDelete(w http.ResponseWriter, r *http.Request) {
+	rel := strings.TrimPrefix(r.URL.Path, "/api/notes/")
+	note, err := cmd.loadNote(rel)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if note == nil {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("Note '%s' is not found", rel))
+		return
+	}
+
+	trash := cmd.trashPath(rel)
+	if err := os.MkdirAll(filepath.Dir(trash), 0755); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrap(err, "Cannot create trash directory"))
+		return
+	}
+	if err := os.Rename(note.FilePath(), trash); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrapf(err, "Cannot move note '%s' to trash", rel))
+		return
+	}
+	if err := cmd.save(note); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errors.Wrap(err, "Note was moved to trash but could not be committed"))
+		return
+	}
+
+	t, _ := filepath.Rel(cmd.Config.HomePath, trash)
+	writeJSON(w, http.StatusOK, map[string]string{"path": rel, "trash": filepath.ToSlash(t)})
+}
+
+// authorized checks token of the request for write API
+func (cmd *ServeCmd) authorized(r *http.Request) bool {
+	if cmd.Token == "" {
+		return true
+	}
+	// Only "Bearer" scheme is accepted. Token without the scheme is rejected
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+cmd.Token)) == 1
+}
+
+// isJSONRequest returns true when Content-Type of the request is JSON. Requiring it prevents cross-site
+// requests from forms since browsers cannot send JSON without preflight
+func isJSONRequest(r *http.Request) bool {
+	t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && t == "application/json"
+}
+
+// writer wraps a handler of write API. It checks authentication and content type, and serializes changes
+func (cmd *ServeCmd) writer(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !cmd.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSONError(w, http.StatusUnauthorized, errors.New("Valid token is required in 'Authorization: Bearer {token}' header"))
+			return
+		}
+		if (r.Method == http.MethodPost || r.Method == http.MethodPut) && !isJSONRequest(r) {
+			writeJSONError(w, http.StatusUnsupportedMediaType, errors.New("Content-Type of request must be 'application/json'"))
+			return
+		}
+		cmd.mu.Lock()
+		defer cmd.mu.Unlock()
+		h(w, r)
+	}
+}
+
+// methods dispatches requests to handlers by their methods. HEAD requests are handled by GET handler.
+// Write API handlers are registered only when --writable is enabled
+func (cmd *ServeCmd) methods(get http.HandlerFunc, writes map[string]http.HandlerFunc) http.HandlerFunc {
+	hs := map[string]http.HandlerFunc{http.MethodGet: get, http.MethodHead: get}
+	if cmd.Writable {
+		for m, h := range writes {
+			hs[m] = cmd.writer(h)
+		}
+	}
+
+	allowed := make([]string, 0, len(hs))
+	for m := range hs {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		h, ok := hs[r.Method]
+		if !ok {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (cmd *ServeCmd) handler() (http.Handler, error) {
+	r, err := newHTMLRenderer(cmd.Config, "")
+	if err != nil {
+		return nil, err
+	}
+	cmd.renderer = r
+
+	if cmd.Commit {
+		if !cmd.Writable {
+			return nil, errors.New("--commit is available only when write API is enabled with --writable")
+		}
+		if cmd.git, err = newGitForCmd("serve", cmd.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", cmd.methods(cmd.handleIndex, nil))
+	mux.HandleFunc("/search", cmd.methods(cmd.handleSearch, nil))
+	mux.HandleFunc("/tags/", cmd.methods(cmd.handleTag, nil))
+	mux.HandleFunc("/notes/", cmd.methods(cmd.handleNotes, nil))
+	mux.HandleFunc("/api/notes", cmd.methods(cmd.handleAPINotes, map[string]http.HandlerFunc{
+		http.MethodPost: cmd.handleAPICreate,
+	}))
+	mux.HandleFunc("/api/notes/", cmd.methods(cmd.handleAPINote, map[string]http.HandlerFunc{
+		http.MethodPut:    cmd.handleAPIUpdate,
+		http.MethodDelete: cmd.handleAPIDelete,
+	}))
+	return mux, nil
+}
+
+// Do runs `notes serve` command and returns an error if occurs. It blocks until the server stops
+func (cmd *ServeCmd) Do() error {
+	h, err := cmd.handler()
+	if err != nil {
+		return err
+	}
+
+	api := "read-only"
+	if cmd.Writable {
+		api = "writable"
+	}
+	fmt.Fprintf(cmd.Out, "Serving notes in %s at http://%s (%s)\n", canonPath(cmd.Config.HomePath), cmd.Addr, api)
+	s := &http.Server{
+		Addr:              cmd.Addr,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return errors.Wrapf(s.ListenAndServe(), "Cannot serve notes at '%s'", cmd.Addr)
+}
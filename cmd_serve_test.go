@@ -0,0 +1,406 @@
+package notes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testServe(t *testing.T) *httptest.Server {
+	cmd := &ServeCmd{Config: testExportConfig("normal")}
+	h, err := cmd.handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(h)
+}
+
+func testServeGet(t *testing.T, url string) (int, string, string) {
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, res.Header.Get("Content-Type"), string(b)
+}
+
+func TestServeCmdPages(t *testing.T) {
+	s := testServe(t)
+	defer s.Close()
+
+	for _, tc := range []struct {
+		path     string
+		status   int
+		contains []string
+	}{
+		{
+			path:   "/",
+			status: http.StatusOK,
+			contains: []string{
+				`<h2 id="blog/tech">blog/tech</h2>`,
+				`<a href="notes/blog/tech/intro.html">Introduction to notes</a>`,
+				`<a class="tag" href="tags/go.html">go (2)</a>`,
+				`<form class="search" action="/search">`,
+				`<option selected>created</option>`,
+			},
+		},
+		{
+			path:   "/tags/go.html",
+			status: http.StatusOK,
+			contains: []string{
+				`<h1>Tag: go</h1>`,
+				`<a href="/notes/blog/tech/intro.html">Introduction to notes</a>`,
+				`<a href="/notes/memo/todo.html">Todo</a>`,
+			},
+		},
+		{
+			path:   "/notes/blog/tech/intro.html",
+			status: http.StatusOK,
+			contains: []string{
+				`<h1>Introduction to notes</h1>`,
+				`<a class="tag" href="/tags/go.html">go</a>`,
+				`<a href="../../memo/todo.html#today">my todo</a>`,
+				`<img src="img/logo.png" alt="logo">`,
+			},
+		},
+		{
+			path:   "/search?category=memo&sort=filename",
+			status: http.StatusOK,
+			contains: []string{
+				`<p>1 notes</p>`,
+				`<a href="/notes/memo/todo.html">Todo</a>`,
+				`<input name="category" placeholder="Category regex" value="memo">`,
+				`<option selected>filename</option>`,
+			},
+		},
+		{
+			path:     "/search?tag=(",
+			status:   http.StatusBadRequest,
+			contains: []string{"Regular expression for filtering tags is invalid"},
+		},
+		{path: "/tags/unknown.html", status: http.StatusNotFound},
+		{path: "/notes/memo/unknown.html", status: http.StatusNotFound},
+		{path: "/notes/memo/../../../cmd.go", status: http.StatusNotFound},
+		{path: "/unknown", status: http.StatusNotFound},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			status, _, body := testServeGet(t, s.URL+tc.path)
+			if status != tc.status {
+				t.Fatalf("Wanted status %d but got %d: %s", tc.status, status, body)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("%q is not contained in page:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServeCmdLocalFile(t *testing.T) {
+	s := testServe(t)
+	defer s.Close()
+
+	status, ctype, _ := testServeGet(t, s.URL+"/notes/blog/tech/img/logo.png")
+	if status != http.StatusOK {
+		t.Fatal("Unexpected status:", status)
+	}
+	if ctype != "image/png" {
+		t.Fatal("Unexpected content type:", ctype)
+	}
+}
+
+func TestServeCmdAPI(t *testing.T) {
+	s := testServe(t)
+	defer s.Close()
+
+	status, ctype, body := testServeGet(t, s.URL+"/api/notes?tag=^go$&sort=filename")
+	if status != http.StatusOK {
+		t.Fatal("Unexpected status:", status, body)
+	}
+	if !strings.HasPrefix(ctype, "application/json") {
+		t.Fatal("Unexpected content type:", ctype)
+	}
+
+	var notes []*serveNote
+	if err := json.Unmarshal([]byte(body), &notes); err != nil {
+		t.Fatal(err, body)
+	}
+	if len(notes) != 2 {
+		t.Fatal("Unexpected number of notes:", body)
+	}
+	if notes[0].Path != "blog/tech/intro.md" || notes[1].Path != "memo/todo.md" {
+		t.Fatal("Unexpected notes:", body)
+	}
+	if notes[1].Category != "memo" || notes[1].File != "todo.md" || notes[1].Title != "Todo" || strings.Join(notes[1].Tags, ",") != "go" {
+		t.Fatal("Unexpected metadata:", body)
+	}
+	if notes[1].Body != nil {
+		t.Fatal("Body should be omitted in list:", body)
+	}
+
+	status, _, body = testServeGet(t, s.URL+"/api/notes/memo/todo.md")
+	if status != http.StatusOK {
+		t.Fatal("Unexpected status:", status, body)
+	}
+	var note serveNote
+	if err := json.Unmarshal([]byte(body), &note); err != nil {
+		t.Fatal(err, body)
+	}
+	if note.Title != "Todo" {
+		t.Fatal("Unexpected title:", body)
+	}
+	if want := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC); !note.Created.Equal(want) {
+		t.Fatal("Unexpected created:", note.Created)
+	}
+	if note.Body == nil || *note.Body != "- [ ] Write a blog post about [intro](/blog/tech/intro.md)\n" {
+		t.Fatalf("Unexpected body: %s", body)
+	}
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		msg    string
+	}{
+		{"/api/notes?category=(", http.StatusBadRequest, "Regular expression for filtering categories is invalid"},
+		{"/api/notes?sort=foo", http.StatusBadRequest, "Unknown sort kind 'foo'"},
+		{"/api/notes/memo/unknown.md", http.StatusNotFound, "Note 'memo/unknown.md' is not found"},
+		{"/api/notes/memo/.hidden.md", http.StatusNotFound, "is not found"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			status, _, body := testServeGet(t, s.URL+tc.path)
+			if status != tc.status {
+				t.Fatalf("Wanted status %d but got %d: %s", tc.status, status, body)
+			}
+			var v map[string]string
+			if err := json.Unmarshal([]byte(body), &v); err != nil {
+				t.Fatal(err, body)
+			}
+			if !strings.Contains(v["error"], tc.msg) {
+				t.Fatalf("%q is not contained in error %q", tc.msg, v["error"])
+			}
+		})
+	}
+}
+
+func TestServeCmdReadOnly(t *testing.T) {
+	s := testServe(t)
+	defer s.Close()
+
+	res, err := http.Post(s.URL+"/api/notes", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatal("Unexpected status:", res.StatusCode)
+	}
+}
+
+func testServeRequest(t *testing.T, method, url, token, body string) (int, map[string]interface{}) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var v map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, v
+}
+
+func TestServeCmdWriteAPI(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-serve-write")
+	defer repos.cleanup()
+	cfg := repos.clone("home")
+	cfg.Save.AutoPush = true
+
+	cmd := &ServeCmd{Config: cfg, Writable: true, Token: "secret", Commit: true}
+	h, err := cmd.handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	// Create
+	status, v := testServeRequest(t, http.MethodPost, s.URL+"/api/notes", "secret", `{"category": "memo", "title": "Hello World", "tags": ["a", "b"], "body": "first body"}`)
+	if status != http.StatusCreated {
+		t.Fatal("Unexpected status:", status, v)
+	}
+	if v["path"] != "memo/hello-world.md" || v["title"] != "Hello World" || v["body"] != "first body\n" {
+		t.Fatal("Unexpected response:", v)
+	}
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "memo", "hello-world.md"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(n.Tags, ",") != "a,b" {
+		t.Fatal("Unexpected tags:", n.Tags)
+	}
+
+	status, v = testServeRequest(t, http.MethodPost, s.URL+"/api/notes", "secret", `{"category": "memo", "file": "hello-world.md"}`)
+	if status != http.StatusConflict {
+		t.Fatal("Unexpected status for existing note:", status, v)
+	}
+
+	// Update
+	status, v = testServeRequest(t, http.MethodPut, s.URL+"/api/notes/memo/hello-world.md", "secret", `{"body": "second body"}`)
+	if status != http.StatusOK {
+		t.Fatal("Unexpected status:", status, v)
+	}
+	if v["body"] != "second body\n" || v["title"] != "Hello World" {
+		t.Fatal("Unexpected response:", v)
+	}
+	n, err = LoadNote(filepath.Join(cfg.HomePath, "memo", "hello-world.md"), cfg)
+	panicIfErr(err)
+	if n.Title != "Hello World" || strings.Join(n.Tags, ",") != "a,b" {
+		t.Fatal("Metadata was not kept:", n.Title, n.Tags)
+	}
+
+	// Delete
+	status, v = testServeRequest(t, http.MethodDelete, s.URL+"/api/notes/memo/hello-world.md", "secret", "")
+	if status != http.StatusOK {
+		t.Fatal("Unexpected status:", status, v)
+	}
+	if v["trash"] != ".trash/memo/hello-world.md" {
+		t.Fatal("Unexpected response:", v)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.HomePath, "memo", "hello-world.md")); err == nil {
+		t.Fatal("Deleted note still exists")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.HomePath, ".trash", "memo", "hello-world.md")); err != nil {
+		t.Fatal("Deleted note was not moved to trash:", err)
+	}
+
+	// Each change was committed and pushed
+	log := repos.log()
+	for _, want := range []string{"Add memo/hello-world.md", "Update memo/hello-world.md", "Delete memo/hello-world.md"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("%q is not contained in log: %s", want, log)
+		}
+	}
+}
+
+func TestServeCmdWriteAPIErrors(t *testing.T) {
+	dir := "test-tmp-dir-serve-write-errors"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+	n, err := NewNote("memo", "", "foo.md", "Foo", cfg)
+	panicIfErr(err)
+	panicIfErr(createNoteWithBody(n, "body"))
+
+	cmd := &ServeCmd{Config: cfg, Writable: true, Token: "secret"}
+	h, err := cmd.handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	for _, tc := range []struct {
+		what   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		msg    string
+	}{
+		{"no token", http.MethodPost, "/api/notes", "", `{"category": "memo", "title": "x"}`, http.StatusUnauthorized, "Valid token is required"},
+		{"wrong token", http.MethodDelete, "/api/notes/memo/foo.md", "wrong", "", http.StatusUnauthorized, "Valid token is required"},
+		{"broken JSON", http.MethodPost, "/api/notes", "secret", `{`, http.StatusBadRequest, "Cannot parse request body as JSON"},
+		{"no file and title", http.MethodPost, "/api/notes", "secret", `{"category": "memo"}`, http.StatusBadRequest, "'file' or 'title' is required"},
+		{"invalid category", http.MethodPost, "/api/notes", "secret", `{"category": ".hidden", "title": "x"}`, http.StatusBadRequest, "Invalid category part '.hidden'"},
+		{"file outside home", http.MethodPost, "/api/notes", "secret", `{"category": "memo", "file": "x/../../../../pwned", "title": "t"}`, http.StatusBadRequest, "'file' cannot contain"},
+		{"file with backslash", http.MethodPost, "/api/notes", "secret", `{"category": "memo", "file": "..\\pwned", "title": "t"}`, http.StatusBadRequest, "'file' cannot contain"},
+		{"no body on update", http.MethodPut, "/api/notes/memo/foo.md", "secret", `{}`, http.StatusBadRequest, "'body' is required"},
+		{"update unknown note", http.MethodPut, "/api/notes/memo/unknown.md", "secret", `{"body": ""}`, http.StatusNotFound, "is not found"},
+		{"delete unknown note", http.MethodDelete, "/api/notes/memo/unknown.md", "secret", "", http.StatusNotFound, "is not found"},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			status, v := testServeRequest(t, tc.method, s.URL+tc.path, tc.token, tc.body)
+			if status != tc.status {
+				t.Fatalf("Wanted status %d but got %d: %v", tc.status, status, v)
+			}
+			if msg, _ := v["error"].(string); !strings.Contains(msg, tc.msg) {
+				t.Fatalf("%q is not contained in error %q", tc.msg, msg)
+			}
+		})
+	}
+
+	// Write API rejects requests which are not JSON such as cross-site form posts
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/api/notes", strings.NewReader(`{"category": "memo", "title": "x"}`))
+	panicIfErr(err)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Authorization", "Bearer secret")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatal("Unexpected status for non-JSON request:", res.StatusCode)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.HomePath, "memo", "x.md")); err == nil {
+		t.Fatal("Note was created by non-JSON request")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(cwd), "pwned.md")); err == nil {
+		t.Fatal("Note was created outside home")
+	}
+
+	// Token must be given with "Bearer" scheme
+	for _, auth := range []string{"secret", "Basic secret", "bearer secret", "Bearer  secret", "Bearer secretsecret"} {
+		req, err := http.NewRequest(http.MethodDelete, s.URL+"/api/notes/memo/foo.md", nil)
+		panicIfErr(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", auth)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Unexpected status for Authorization header %q: %d", auth, res.StatusCode)
+		}
+	}
+
+	// Note is not changed by failed requests
+	body, err := n.ReadBody()
+	panicIfErr(err)
+	if body != "body\n" {
+		t.Fatalf("Note was changed: %q", body)
+	}
+}
+
+func TestServeCmdCommitRequiresWritable(t *testing.T) {
+	cmd := &ServeCmd{Config: testExportConfig("normal"), Commit: true}
+	_, err := cmd.handler()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "--writable") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -0,0 +1,283 @@
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// SyncCmd represents `notes sync` command. Each public fields represent options of the command.
+// In and Out fields represent where this command should input and output.
+type SyncCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Remote is a remote name to sync with. When empty, 'sync.remote' config, tracking remote of current
+	// branch or "origin" is used in order
+	Remote string
+	// Branch is a branch name of the remote to sync with. When empty, 'sync.branch' config, tracking
+	// branch or current branch is used in order
+	Branch string
+	// Strategy is how to integrate changes from the remote. "rebase" or "merge". When empty,
+	// 'sync.strategy' config or "rebase" is used
+	Strategy string
+	// Message is a message of Git commit which will be created before syncing. If this value is empty,
+	// automatically generated message will be used as `notes save` does.
+	Message string
+	// In is a reader to read answer of confirmation. Kind of stdin is expected
+	In io.Reader
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+
+	scanner *bufio.Scanner
+}
+
+func (cmd *SyncCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("sync", "Sync notes with remote Git repository. It commits all changes, fetches the remote branch, rebases or merges it and pushes the result. When conflicts occur, conflicted notes are listed and can be opened with your editor")
+	cmd.cli.Flag("remote", "Remote name to sync with. Default is 'sync.remote' config, tracking remote or 'origin'").Short('r').StringVar(&cmd.Remote)
+	cmd.cli.Flag("branch", "Branch name of the remote to sync with. Default is 'sync.branch' config, tracking branch or current branch").Short('b').StringVar(&cmd.Branch)
+	cmd.cli.Flag("strategy", "How to integrate changes from the remote. 'rebase' or 'merge'. Default is 'sync.strategy' config or 'rebase'").EnumVar(&cmd.Strategy, "rebase", "merge")
+	cmd.cli.Flag("message", "Commit message for local changes. If omitted, an automatic message will be generated from changes of notes").Short('m').StringVar(&cmd.Message)
+}
+
+func (cmd *SyncCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+func (cmd *SyncCmd) confirm(prompt string) (bool, error) {
+	if cmd.scanner == nil {
+		cmd.scanner = bufio.NewScanner(cmd.In)
+	}
+	fmt.Fprint(cmd.Out, prompt)
+	if !cmd.scanner.Scan() {
+		if err := cmd.scanner.Err(); err != nil {
+			return false, errors.Wrap(err, "Cannot read answer of confirmation")
+		}
+		// EOF means 'no'
+		fmt.Fprintln(cmd.Out)
+		return false, nil
+	}
+	a := strings.ToLower(strings.TrimSpace(cmd.scanner.Text()))
+	return a == "y" || a == "yes", nil
+}
+
+func (cmd *SyncCmd) commit(git *Git) error {
+	if err := git.AddAll(); err != nil {
+		return err
+	}
+
+	changes, err := git.StagedChanges()
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	msg := cmd.Message
+	if msg == "" {
+		save := &SaveCmd{Config: cmd.Config}
+		m, err := save.generateMessage(git)
+		if err != nil {
+			return err
+		}
+		msg = m
+	}
+
+	return git.Commit(msg)
+}
+
+func (cmd *SyncCmd) target(git *Git) (string, string, error) {
+	remote, branch := cmd.Remote, cmd.Branch
+	if remote == "" {
+		remote = cmd.Config.Sync.Remote
+	}
+	if branch == "" {
+		branch = cmd.Config.Sync.Branch
+	}
+	if remote != "" && branch != "" {
+		return remote, branch, nil
+	}
+
+	if r, b, err := git.TrackingRemote(); err == nil {
+		if remote == "" {
+			remote = r
+		}
+		if branch == "" {
+			branch = b
+		}
+		return remote, branch, nil
+	}
+
+	if remote == "" {
+		remote = "origin"
+	}
+	if branch == "" {
+		b, err := git.CurrentBranch()
+		if err != nil {
+			return "", "", err
+		}
+		branch = b
+	}
+	return remote, branch, nil
+}
+
+func (cmd *SyncCmd) strategy() string {
+	if cmd.Strategy != "" {
+		return cmd.Strategy
+	}
+	if cmd.Config.Sync.Strategy != "" {
+		return cmd.Config.Sync.Strategy
+	}
+	return "rebase"
+}
+
+// unresolved returns paths of files which still contain conflict markers
+func unresolved(paths []string) ([]string, error) {
+	ret := []string{}
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Removing the file is one of ways to resolve the conflict
+				continue
+			}
+			return nil, errors.Wrapf(err, "Cannot read conflicted file '%s'", canonPath(p))
+		}
+		for _, l := range bytes.Split(b, []byte{'\n'}) {
+			if bytes.HasPrefix(l, []byte("<<<<<<< ")) || bytes.HasPrefix(l, []byte(">>>>>>> ")) {
+				ret = append(ret, p)
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+func (cmd *SyncCmd) resolveConflicts(git *Git, op string, cause error) error {
+	for {
+		files, err := git.ConflictedFiles()
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			// Failed due to other reason than conflicts
+			return cause
+		}
+
+		fmt.Fprintf(cmd.Out, "Conflicts occurred in following notes while %s:\n", op)
+		paths := make([]string, 0, len(files))
+		for _, f := range files {
+			fmt.Fprintln(cmd.Out, f)
+			paths = append(paths, filepath.Join(cmd.Config.HomePath, filepath.FromSlash(f)))
+		}
+
+		howto := fmt.Sprintf("Please resolve the conflicts, run 'git add' and 'git %s --continue' at '%s', then run 'notes sync' again. To cancel, run 'git %s --abort'", op, canonPath(cmd.Config.HomePath), op)
+
+		if cmd.Config.EditorCmd == "" {
+			return errors.Errorf("Conflicts occurred in %d notes. %s", len(files), howto)
+		}
+
+		ok, err := cmd.confirm(fmt.Sprintf("Open %d conflicted notes with editor? [y/N]: ", len(files)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Errorf("Conflicts occurred in %d notes. %s", len(files), howto)
+		}
+
+		if err := openEditor(cmd.Config, paths...); err != nil {
+			return err
+		}
+
+		remains, err := unresolved(paths)
+		if err != nil {
+			return err
+		}
+		if len(remains) > 0 {
+			for i, p := range remains {
+				remains[i] = canonPath(p)
+			}
+			return errors.Errorf("Conflict markers still remain in %s. %s", strings.Join(remains, ", "), howto)
+		}
+
+		if err := git.Add(files...); err != nil {
+			return err
+		}
+
+		if err := git.Continue(op); err != nil {
+			// Next commit may cause conflicts while rebase
+			cause = err
+			continue
+		}
+
+		return nil
+	}
+}
+
+// Do runs `notes sync` command and returns an error if occurs
+func (cmd *SyncCmd) Do() error {
+	git, err := newGitForCmd("sync", cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	if op := git.InProgress(); op != "" {
+		return errors.Errorf("%s is in progress at '%s'. Please finish it with 'git %s --continue' or cancel it with 'git %s --abort' at first", op, canonPath(cmd.Config.HomePath), op, op)
+	}
+
+	if err := cmd.commit(git); err != nil {
+		return err
+	}
+
+	remote, branch, err := cmd.target(git)
+	if err != nil {
+		return err
+	}
+
+	exists, err := git.RemoteBranchExists(remote, branch)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		if err := git.Fetch(remote, branch); err != nil {
+			return err
+		}
+
+		op := cmd.strategy()
+		if !git.HasCommit() {
+			// Rebase does not work on a branch which has no commit yet
+			op = "merge"
+		}
+
+		if op == "merge" {
+			err = git.Merge("FETCH_HEAD")
+		} else {
+			err = git.Rebase("FETCH_HEAD")
+		}
+		if err != nil {
+			if err := cmd.resolveConflicts(git, op, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !git.HasCommit() {
+		fmt.Fprintln(cmd.Out, "Nothing to sync")
+		return nil
+	}
+
+	if err := git.Push(remote, branch); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Out, "Synced with %s/%s\n", remote, branch)
+	return nil
+}
@@ -0,0 +1,318 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type testSyncRepos struct {
+	root   string
+	remote string
+}
+
+func newTestSyncRepos(name string) *testSyncRepos {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	root := filepath.Join(cwd, name)
+	panicIfErr(os.MkdirAll(root, 0755))
+	remote := filepath.Join(root, "remote.git")
+	out, err := (&Git{bin: "git", root: root}).Exec("init", "--bare", remote)
+	if err != nil {
+		panic(out)
+	}
+	return &testSyncRepos{root, remote}
+}
+
+func (r *testSyncRepos) clone(name string) *Config {
+	out, err := (&Git{bin: "git", root: r.root}).Exec("clone", r.remote, name)
+	if err != nil {
+		panic(out)
+	}
+	cfg := &Config{GitPath: "git", HomePath: filepath.Join(r.root, name)}
+	g := NewGit(cfg)
+	_, err = g.Exec("config", "user.name", "You")
+	panicIfErr(err)
+	_, err = g.Exec("config", "user.email", "you@example.com")
+	panicIfErr(err)
+	return cfg
+}
+
+func (r *testSyncRepos) log() string {
+	out, err := (&Git{bin: "git", root: r.remote}).Exec("log", "--format=%s", "--all")
+	if err != nil {
+		panic(out)
+	}
+	return out
+}
+
+func (r *testSyncRepos) cleanup() {
+	panicIfErr(os.RemoveAll(r.root))
+}
+
+func writeTestNote(cfg *Config, rel, content string) {
+	p := filepath.Join(cfg.HomePath, filepath.FromSlash(rel))
+	panicIfErr(os.MkdirAll(filepath.Dir(p), 0755))
+	panicIfErr(os.WriteFile(p, []byte(content), 0644))
+}
+
+func syncForTest(cfg *Config, in string) (string, error) {
+	var out bytes.Buffer
+	cmd := &SyncCmd{
+		Config: cfg,
+		In:     strings.NewReader(in),
+		Out:    &out,
+	}
+	err := cmd.Do()
+	return out.String(), err
+}
+
+const testSyncNote = `foo
+===
+- Category: memo
+- Tags:
+- Created: 2018-10-30T11:37:45+09:00
+
+`
+
+func TestSyncCmdPushToEmptyRemote(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-sync-empty")
+	defer repos.cleanup()
+
+	cfg := repos.clone("a")
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"hello\n")
+
+	out, err := syncForTest(cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Synced with origin/") {
+		t.Fatal("Unexpected output:", out)
+	}
+
+	if log := repos.log(); log != "Add memo/foo.md" {
+		t.Fatal("Unexpected log of remote:", log)
+	}
+
+	// Sync again without any change does nothing
+	if _, err := syncForTest(cfg, ""); err != nil {
+		t.Fatal(err)
+	}
+	if log := repos.log(); log != "Add memo/foo.md" {
+		t.Fatal("Unexpected log of remote:", log)
+	}
+}
+
+func TestSyncCmdNothingToSync(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-sync-nothing")
+	defer repos.cleanup()
+
+	cfg := repos.clone("a")
+	out, err := syncForTest(cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Nothing to sync\n" {
+		t.Fatal("Unexpected output:", out)
+	}
+}
+
+func TestSyncCmdIntegrateRemoteChanges(t *testing.T) {
+	for _, strategy := range []string{"rebase", "merge"} {
+		t.Run(strategy, func(t *testing.T) {
+			repos := newTestSyncRepos("test-tmp-dir-sync-" + strategy)
+			defer repos.cleanup()
+
+			a := repos.clone("a")
+			a.Sync.Strategy = strategy
+			writeTestNote(a, "memo/foo.md", testSyncNote+"hello\n")
+			if _, err := syncForTest(a, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			b := repos.clone("b")
+			writeTestNote(b, "memo/foo.md", testSyncNote+"hello from b\n")
+			if _, err := syncForTest(b, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			writeTestNote(a, "memo/bar.md", strings.Replace(testSyncNote, "foo", "bar", 1))
+			if _, err := syncForTest(a, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(a.HomePath, "memo", "foo.md"))
+			panicIfErr(err)
+			if !strings.HasSuffix(string(content), "hello from b\n") {
+				t.Fatal("Change in remote was not integrated:", string(content))
+			}
+
+			log := repos.log()
+			for _, want := range []string{"Add memo/bar.md", "Update memo/foo.md", "Add memo/foo.md"} {
+				if !strings.Contains(log, want) {
+					t.Fatal("Commit", want, "is not in log of remote:", log)
+				}
+			}
+
+			merges, err := NewGit(a).Exec("log", "--merges", "--oneline")
+			panicIfErr(err)
+			if strategy == "merge" && merges == "" {
+				t.Fatal("Merge commit was not created")
+			}
+			if strategy == "rebase" && merges != "" {
+				t.Fatal("Merge commit was created on rebase:", merges)
+			}
+		})
+	}
+}
+
+func prepareTestSyncConflict(repos *testSyncRepos) *Config {
+	a := repos.clone("a")
+	writeTestNote(a, "memo/foo.md", testSyncNote+"hello\n")
+	if _, err := syncForTest(a, ""); err != nil {
+		panic(err)
+	}
+
+	b := repos.clone("b")
+	writeTestNote(b, "memo/foo.md", testSyncNote+"hello from b\n")
+	if _, err := syncForTest(b, ""); err != nil {
+		panic(err)
+	}
+
+	writeTestNote(a, "memo/foo.md", testSyncNote+"hello from a\n")
+	return a
+}
+
+func TestSyncCmdConflictNotResolved(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-sync-conflict")
+	defer repos.cleanup()
+
+	a := prepareTestSyncConflict(repos)
+	a.EditorCmd = "echo"
+
+	out, err := syncForTest(a, "n\n")
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Conflicts occurred in 1 notes") {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(out, "Conflicts occurred in following notes while rebase:\nmemo/foo.md\n") {
+		t.Fatal("Conflicted notes are not listed:", out)
+	}
+
+	if op := NewGit(a).InProgress(); op != "rebase" {
+		t.Fatal("Rebase should be in progress but got", op)
+	}
+
+	_, err = syncForTest(a, "")
+	if err == nil || !strings.Contains(err.Error(), "rebase is in progress") {
+		t.Fatal("Unexpected error while rebase is in progress:", err)
+	}
+}
+
+func TestSyncCmdResolveConflictWithEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("'cp' command is used as editor")
+	}
+
+	repos := newTestSyncRepos("test-tmp-dir-sync-resolve")
+	defer repos.cleanup()
+
+	a := prepareTestSyncConflict(repos)
+
+	resolved := filepath.Join(repos.root, "resolved.md")
+	panicIfErr(os.WriteFile(resolved, []byte(testSyncNote+"hello from a and b\n"), 0644))
+	// Editor command receives a path to conflicted note as its last argument
+	a.EditorCmd = "cp " + resolved
+
+	out, err := syncForTest(a, "y\n")
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if !strings.Contains(out, "Open 1 conflicted notes with editor? [y/N]: ") {
+		t.Fatal("Confirmation was not shown:", out)
+	}
+
+	if op := NewGit(a).InProgress(); op != "" {
+		t.Fatal("Operation is still in progress:", op)
+	}
+
+	content, err := os.ReadFile(filepath.Join(a.HomePath, "memo", "foo.md"))
+	panicIfErr(err)
+	if !strings.HasSuffix(string(content), "hello from a and b\n") {
+		t.Fatal("Conflict was not resolved:", string(content))
+	}
+
+	if log := repos.log(); strings.Count(log, "Update memo/foo.md") != 2 {
+		t.Fatal("Both updates should be pushed:", log)
+	}
+}
+
+func TestSyncCmdConflictMarkersRemain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("'true' command is used as editor")
+	}
+
+	repos := newTestSyncRepos("test-tmp-dir-sync-markers")
+	defer repos.cleanup()
+
+	a := prepareTestSyncConflict(repos)
+	// Editor does not touch the file
+	a.EditorCmd = "true"
+
+	_, err := syncForTest(a, "y\n")
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Conflict markers still remain in") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestSyncCmdError(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-sync-error")
+	defer repos.cleanup()
+
+	noRepo := filepath.Join(repos.root, "no-repo")
+	panicIfErr(os.MkdirAll(noRepo, 0755))
+
+	for _, tc := range []struct {
+		what string
+		cfg  *Config
+		want string
+	}{
+		{
+			what: "no git",
+			cfg:  &Config{HomePath: noRepo},
+			want: "'sync' command cannot work without Git",
+		},
+		{
+			what: "no repository",
+			cfg:  &Config{GitPath: "git", HomePath: noRepo},
+			want: "'.git' directory does not exist in home",
+		},
+		{
+			what: "unknown remote",
+			cfg: func() *Config {
+				c := repos.clone("a")
+				c.Sync.Remote = "unknown-remote"
+				return c
+			}(),
+			want: "Cannot access to remote 'unknown-remote'",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			_, err := syncForTest(tc.cfg, "")
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/fatih/color"
@@ -40,6 +41,31 @@ func TestParseArgs(t *testing.T) {
 			SaveCmd{},
 			TagsCmd{},
 			SelfupdateCmd{},
+			PruneCmd{},
+			SyncCmd{},
+			GitSetupCmd{},
+			GitCmd{},
+			ExportHTMLCmd{},
+			ExportBundleCmd{},
+			ExportSiteCmd{},
+			ImportBundleCmd{},
+			ImportMemolistCmd{},
+			ImportDirCmd{},
+			ImportEnexCmd{},
+			ServeCmd{},
+			WatchCmd{},
+			LinksCmd{},
+			BacklinksCmd{},
+			MvCmd{},
+			GraphCmd{},
+			TodoCmd{},
+			TodoCheckCmd{},
+			AgendaCmd{},
+			LogCmd{},
+			DiffCmd{},
+			RestoreCmd{},
+			ConfigSetCmd{},
+			NotebooksCmd{},
 		),
 		cmpopts.IgnoreTypes(&Config{}),
 		cmpopts.IgnoreFields(ListCmd{}, "Out"),
@@ -47,6 +73,31 @@ func TestParseArgs(t *testing.T) {
 		cmpopts.IgnoreFields(TagsCmd{}, "Out"),
 		cmpopts.IgnoreFields(CategoriesCmd{}, "Out"),
 		cmpopts.IgnoreFields(SelfupdateCmd{}, "Out"),
+		cmpopts.IgnoreFields(PruneCmd{}, "In", "Out"),
+		cmpopts.IgnoreFields(SyncCmd{}, "In", "Out"),
+		cmpopts.IgnoreFields(GitSetupCmd{}, "Out"),
+		cmpopts.IgnoreFields(GitCmd{}, "In", "Out"),
+		cmpopts.IgnoreFields(ExportHTMLCmd{}, "Out"),
+		cmpopts.IgnoreFields(ExportBundleCmd{}, "Out"),
+		cmpopts.IgnoreFields(ExportSiteCmd{}, "Out"),
+		cmpopts.IgnoreFields(ImportBundleCmd{}, "In", "Out"),
+		cmpopts.IgnoreFields(ImportMemolistCmd{}, "Out"),
+		cmpopts.IgnoreFields(ImportDirCmd{}, "Out"),
+		cmpopts.IgnoreFields(ImportEnexCmd{}, "Out"),
+		cmpopts.IgnoreFields(ServeCmd{}, "Out"),
+		cmpopts.IgnoreFields(WatchCmd{}, "Out"),
+		cmpopts.IgnoreFields(LinksCmd{}, "Out"),
+		cmpopts.IgnoreFields(BacklinksCmd{}, "Out"),
+		cmpopts.IgnoreFields(MvCmd{}, "Out"),
+		cmpopts.IgnoreFields(GraphCmd{}, "Out"),
+		cmpopts.IgnoreFields(TodoCmd{}, "Out"),
+		cmpopts.IgnoreFields(TodoCheckCmd{}, "Out"),
+		cmpopts.IgnoreFields(AgendaCmd{}, "Out"),
+		cmpopts.IgnoreFields(SaveCmd{}, "Out"),
+		cmpopts.IgnoreFields(LogCmd{}, "Out"),
+		cmpopts.IgnoreFields(DiffCmd{}, "Out"),
+		cmpopts.IgnoreFields(RestoreCmd{}, "Out"),
+		cmpopts.IgnoreFields(NotebooksCmd{}, "Out"),
 	}
 
 	for _, tc := range []struct {
@@ -56,7 +107,30 @@ func TestParseArgs(t *testing.T) {
 		{
 			args: []string{"config", "home"},
 			want: &ConfigCmd{
-				Name: "home",
+				Name:   "home",
+				Format: "text",
+			},
+		},
+		{
+			args: []string{"config", "--format", "json", "--source"},
+			want: &ConfigCmd{
+				Format: "json",
+				Source: true,
+			},
+		},
+		{
+			args: []string{"config", "set", "editor", "vim", "--local"},
+			want: &ConfigSetCmd{
+				Key:   "editor",
+				Value: "vim",
+				Local: true,
+			},
+		},
+		{
+			args: []string{"config", "unset", "list.sort"},
+			want: &ConfigSetCmd{
+				Key:   "list.sort",
+				Unset: true,
 			},
 		},
 		{
@@ -65,6 +139,235 @@ func TestParseArgs(t *testing.T) {
 				Message: "hello",
 			},
 		},
+		{
+			args: []string{"sync", "--remote", "upstream", "--branch", "feature/notes", "--strategy", "merge", "-m", "hello"},
+			want: &SyncCmd{
+				Remote:   "upstream",
+				Branch:   "feature/notes",
+				Strategy: "merge",
+				Message:  "hello",
+			},
+		},
+		{
+			args: []string{"git-setup", "--attributes"},
+			want: &GitSetupCmd{
+				Attributes: true,
+			},
+		},
+		{
+			args: []string{"git", "log", "--oneline", "-n", "3"},
+			want: &GitCmd{
+				Args: []string{"log", "--oneline", "-n", "3"},
+			},
+		},
+		{
+			args: []string{"--no-color", "git", "--help"},
+			want: &GitCmd{
+				Args: []string{"--help"},
+			},
+		},
+		{
+			args: []string{"export", "html", "--out", "site"},
+			want: &ExportHTMLCmd{
+				Dir: "site",
+			},
+		},
+		{
+			args: []string{"export", "bundle", "-c", "blog", "-t", "go", "--sort", "filename", "--format", "json", "-o", "notes.json"},
+			want: &ExportBundleCmd{
+				Category: "blog",
+				Tag:      "go",
+				SortBy:   "filename",
+				Format:   "json",
+				File:     "notes.json",
+			},
+		},
+		{
+			args: []string{"export", "bundle"},
+			want: &ExportBundleCmd{
+				Format: "markdown",
+			},
+		},
+		{
+			args: []string{"export", "hugo", "--category", "blog", "--exclude-tag", "draft", "--out", "site/content"},
+			want: &ExportSiteCmd{
+				Generator:  "hugo",
+				Category:   "blog",
+				ExcludeTag: "draft",
+				Dir:        "site/content",
+			},
+		},
+		{
+			args: []string{"export", "jekyll", "-t", "go", "-o", "site"},
+			want: &ExportSiteCmd{
+				Generator: "jekyll",
+				Tag:       "go",
+				Dir:       "site",
+			},
+		},
+		{
+			args: []string{"import", "bundle", "notes.json"},
+			want: &ImportBundleCmd{
+				File: "notes.json",
+			},
+		},
+		{
+			args: []string{"import", "memolist", "/path/to/memolist"},
+			want: &ImportMemolistCmd{
+				Dir: "/path/to/memolist",
+			},
+		},
+		{
+			args: []string{"import", "dir", "--conflict", "rename", "--dry-run", "vault"},
+			want: &ImportDirCmd{
+				Dir:      "vault",
+				Category: "imported",
+				Conflict: "rename",
+				DryRun:   true,
+			},
+		},
+		{
+			args: []string{"import", "enex", "-c", "memo", "notes.enex"},
+			want: &ImportEnexCmd{
+				File:     "notes.enex",
+				Category: "memo",
+				Conflict: "skip",
+			},
+		},
+		{
+			args: []string{"serve"},
+			want: &ServeCmd{
+				Addr: "127.0.0.1:8080",
+			},
+		},
+		{
+			args: []string{"serve", "--addr", ":3000", "--writable", "--token", "secret", "--commit"},
+			want: &ServeCmd{
+				Addr:     ":3000",
+				Writable: true,
+				Token:    "secret",
+				Commit:   true,
+			},
+		},
+		{
+			args: []string{"watch"},
+			want: &WatchCmd{
+				Debounce: time.Second,
+			},
+		},
+		{
+			args: []string{"watch", "-e", "make index", "--save", "--debounce", "500ms"},
+			want: &WatchCmd{
+				Exec:     "make index",
+				Save:     true,
+				Debounce: 500 * time.Millisecond,
+			},
+		},
+		{
+			args: []string{"links", "memo/foo.md"},
+			want: &LinksCmd{
+				Note: "memo/foo.md",
+			},
+		},
+		{
+			args: []string{"links", "--broken"},
+			want: &LinksCmd{
+				Broken: true,
+			},
+		},
+		{
+			args: []string{"backlinks", "memo/foo"},
+			want: &BacklinksCmd{
+				Note: "memo/foo",
+			},
+		},
+		{
+			args: []string{"mv", "memo/foo.md", "blog/bar.md"},
+			want: &MvCmd{
+				Note: "memo/foo.md",
+				Dest: "blog/bar.md",
+			},
+		},
+		{
+			args: []string{"graph"},
+			want: &GraphCmd{
+				Format: "dot",
+			},
+		},
+		{
+			args: []string{"graph", "-c", "^blog", "-t", "go", "--format", "json"},
+			want: &GraphCmd{
+				Category: "^blog",
+				Tag:      "go",
+				Format:   "json",
+			},
+		},
+		{
+			args: []string{"todo"},
+			want: &TodoCmd{},
+		},
+		{
+			args: []string{"todo", "-c", "^work", "-t", "project", "--done"},
+			want: &TodoCmd{
+				Category: "^work",
+				Tag:      "project",
+				Done:     true,
+			},
+		},
+		{
+			args: []string{"todo", "list", "--category", "memo"},
+			want: &TodoCmd{
+				Category: "memo",
+			},
+		},
+		{
+			args: []string{"todo", "check", "memo/foo.md:12"},
+			want: &TodoCheckCmd{
+				Ref: "memo/foo.md:12",
+			},
+		},
+		{
+			args: []string{"agenda"},
+			want: &AgendaCmd{},
+		},
+		{
+			args: []string{"agenda", "-c", "^work", "-t", "project", "--ics", "notes.ics"},
+			want: &AgendaCmd{
+				Category: "^work",
+				Tag:      "project",
+				ICS:      "notes.ics",
+			},
+		},
+		{
+			args: []string{"log", "memo/foo.md"},
+			want: &LogCmd{
+				Note: "memo/foo.md",
+			},
+		},
+		{
+			args: []string{"diff", "memo/foo", "HEAD~1"},
+			want: &DiffCmd{
+				Note:     "memo/foo",
+				Revision: "HEAD~1",
+			},
+		},
+		{
+			args: []string{"restore", "memo/foo.md", "abcdef0"},
+			want: &RestoreCmd{
+				Note:     "memo/foo.md",
+				Revision: "abcdef0",
+			},
+		},
+		{
+			args: []string{"save", "--category", "^memo$", "--tag", "dog", "--dry-run", "--no-push", "memo/foo.md", "blog/bar"},
+			want: &SaveCmd{
+				Category: "^memo$",
+				Tag:      "dog",
+				DryRun:   true,
+				NoPush:   true,
+				Paths:    []string{"memo/foo.md", "blog/bar"},
+			},
+		},
 		{
 			args: []string{"tags", "dog"},
 			want: &TagsCmd{
@@ -79,6 +382,18 @@ func TestParseArgs(t *testing.T) {
 			args: []string{"cats"},
 			want: &CategoriesCmd{},
 		},
+		{
+			args: []string{"categories", "--empty"},
+			want: &CategoriesCmd{
+				Empty: true,
+			},
+		},
+		{
+			args: []string{"prune", "--yes"},
+			want: &PruneCmd{
+				Yes: true,
+			},
+		},
 		{
 			args: []string{"list", "--category", "dog", "--tag", "cat", "--oneline", "--edit"},
 			want: &ListCmd{
@@ -106,6 +421,19 @@ func TestParseArgs(t *testing.T) {
 				Filename: "filename",
 			},
 		},
+		{
+			args: []string{"notebooks", "--name-only"},
+			want: &NotebooksCmd{
+				NameOnly: true,
+			},
+		},
+		{
+			args: []string{"list", "--all-notebooks", "--oneline"},
+			want: &ListCmd{
+				AllNotebooks: true,
+				Oneline:      true,
+			},
+		},
 		{
 			args: []string{"selfupdate", "--dry"},
 			want: &SelfupdateCmd{
@@ -136,6 +464,188 @@ func TestParseArgs(t *testing.T) {
 	}
 }
 
+func TestParseArgsWithConfigFile(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	envs, err := tmpenv.Setenvs(map[string]string{
+		"NOTES_CLI_HOME":  filepath.Join(cwd, "testdata", "config", "home-with-file"),
+		"XDG_CONFIG_HOME": filepath.Join(cwd, "testdata", "config", "xdg"),
+	})
+	panicIfErr(err)
+	defer envs.Restore()
+
+	old := color.NoColor
+	defer func() {
+		color.NoColor = old
+	}()
+
+	for _, tc := range []struct {
+		args    []string
+		oneline bool
+		sort    string
+	}{
+		{
+			args:    []string{"list"},
+			oneline: true,
+			sort:    "filename",
+		},
+		{
+			args:    []string{"list", "--no-oneline", "--sort", "created"},
+			oneline: false,
+			sort:    "created",
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			color.NoColor = false
+			cmd, err := ParseCmd(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			list, ok := cmd.(*ListCmd)
+			if !ok {
+				t.Fatalf("Unexpected command: %#v", cmd)
+			}
+			if list.Oneline != tc.oneline {
+				t.Error("Unexpected --oneline:", list.Oneline)
+			}
+			if list.SortBy != tc.sort {
+				t.Error("Unexpected --sort:", list.SortBy)
+			}
+			if !color.NoColor {
+				t.Error("Color output was not disabled by config file")
+			}
+		})
+	}
+}
+
+func TestParseWatchSaveWithConfigFile(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	envs, err := tmpenv.Setenvs(map[string]string{
+		"NOTES_CLI_HOME":  filepath.Join(cwd, "testdata", "config", "home-with-file"),
+		"XDG_CONFIG_HOME": filepath.Join(cwd, "testdata", "config", "xdg"),
+	})
+	panicIfErr(err)
+	defer envs.Restore()
+
+	old := color.NoColor
+	defer func() {
+		color.NoColor = old
+	}()
+
+	for _, tc := range []struct {
+		args []string
+		save bool
+	}{
+		{
+			args: []string{"watch"},
+			save: true,
+		},
+		{
+			args: []string{"watch", "--no-save"},
+			save: false,
+		},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd, err := ParseCmd(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			watch, ok := cmd.(*WatchCmd)
+			if !ok {
+				t.Fatalf("Unexpected command: %#v", cmd)
+			}
+			if watch.Save != tc.save {
+				t.Error("Unexpected --save:", watch.Save)
+			}
+		})
+	}
+}
+
+func TestParseNotebookFlag(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	envs, err := tmpenv.Setenvs(map[string]string{
+		"NOTES_CLI_HOME":  filepath.Join(cwd, "testdata", "list", "normal"),
+		"XDG_CONFIG_HOME": filepath.Join(cwd, "testdata", "config", "notebooks"),
+	})
+	panicIfErr(err)
+	defer envs.Restore()
+
+	cmd, err := ParseCmd([]string{"--notebook", "nested", "categories"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cats, ok := cmd.(*CategoriesCmd)
+	if !ok {
+		t.Fatalf("Unexpected command: %#v", cmd)
+	}
+	if cats.Config.HomePath != filepath.Join("testdata", "list", "nested") || cats.Config.Notebook != "nested" {
+		t.Fatal("Home was not switched to notebook:", cats.Config.HomePath, cats.Config.Notebook)
+	}
+
+	_, err = ParseCmd([]string{"-n", "unknown", "categories"})
+	if err == nil || !strings.Contains(err.Error(), "Notebook 'unknown' is not configured") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestParseNotebookFlagWithConfigFileInNotebook(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	root := filepath.Join(cwd, "test-tmp-dir-parse-notebook")
+	defer func() { panicIfErr(os.RemoveAll(root)) }()
+
+	nb := filepath.Join(root, "notebook")
+	panicIfErr(os.MkdirAll(nb, 0755))
+	panicIfErr(os.WriteFile(filepath.Join(nb, ".notes.toml"), []byte("color = \"never\"\n[list]\nsort = \"filename\"\n"), 0644))
+	xdg := filepath.Join(root, "xdg")
+	panicIfErr(os.MkdirAll(filepath.Join(xdg, "notes-cli"), 0755))
+	panicIfErr(os.WriteFile(filepath.Join(xdg, "notes-cli", "config.toml"), []byte(fmt.Sprintf("[notebooks]\nwork = %q\n", filepath.ToSlash(nb))), 0644))
+
+	home := filepath.Join(root, "default-home")
+	envs, err := tmpenv.Setenvs(map[string]string{
+		"NOTES_CLI_HOME":  home,
+		"XDG_CONFIG_HOME": xdg,
+	})
+	panicIfErr(err)
+	defer envs.Restore()
+
+	old := color.NoColor
+	defer func() {
+		color.NoColor = old
+	}()
+	color.NoColor = false
+
+	cmd, err := ParseCmd([]string{"-A", "list", "-n", "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := cmd.(*ListCmd)
+	if !ok {
+		t.Fatalf("Unexpected command: %#v", cmd)
+	}
+	if list.Config.HomePath != nb {
+		t.Fatal("Home was not switched to notebook:", list.Config.HomePath)
+	}
+	if list.SortBy != "filename" {
+		t.Error("Default value in config file in notebook was not applied:", list.SortBy)
+	}
+	if color.NoColor {
+		t.Error("--color-always should be prioritized over config file")
+	}
+	if _, err := os.Stat(home); err == nil {
+		t.Error("Default home should not be created when notebook is specified")
+	}
+
+	if _, err := ParseCmd([]string{"list", "-n", "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if !color.NoColor {
+		t.Error("Color setting in config file in notebook was not applied")
+	}
+}
+
 func TestParseGlobalColorFlags(t *testing.T) {
 	old := color.NoColor
 	defer func() {
@@ -243,3 +753,46 @@ func TestParseExternalCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestParseExternalCommandWithNotebook(t *testing.T) {
+	bindir := testExternalCommandBinaryDir("test", t)
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	envs, err := tmpenv.Setenvs(map[string]string{
+		"PATH":            os.Getenv("PATH") + string(os.PathListSeparator) + bindir,
+		"NOTES_CLI_HOME":  filepath.Join(cwd, "testdata", "list", "normal"),
+		"XDG_CONFIG_HOME": filepath.Join(cwd, "testdata", "config", "notebooks"),
+	})
+	panicIfErr(err)
+	defer envs.Restore()
+
+	cmd, err := ParseCmd([]string{"-n", "nested", "external-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, ok := cmd.(*ExternalCmd)
+	if !ok {
+		t.Fatalf("Did not resolve to external command: %#v", cmd)
+	}
+
+	fake := fakeio.Stdout().Stderr()
+	defer fake.Restore()
+
+	if err := ext.Do(); err != nil {
+		t.Fatal(err)
+	}
+	output, err := fake.String()
+	panicIfErr(err)
+
+	// Notebook selected with --notebook and homes of all notebooks are passed to external command
+	nested, normal := filepath.Join("testdata", "list", "nested"), filepath.Join("testdata", "list", "normal")
+	for _, want := range []string{
+		fmt.Sprintf("%q", "NOTES_CLI_HOME="+nested),
+		fmt.Sprintf("%q", "NOTES_CLI_NOTEBOOK=nested"),
+		fmt.Sprintf("%q", "NOTES_CLI_NOTEBOOKS="+nested+"\n"+normal),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("%s is not contained in output: %s", want, output)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package notes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// todoCommand returns `notes todo` command which has `list` and `check` subcommands
+func todoCommand(app *kingpin.Application) *kingpin.CmdClause {
+	if c := app.GetCommand("todo"); c != nil {
+		return c
+	}
+	return app.Command("todo", "List and check task items like '- [ ] task' in notes")
+}
+
+// TodoCmd represents `notes todo list` command. It is run by `notes todo` as well. Each public fields
+// represent options of the command. Out field represents where this command should output.
+type TodoCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Category is a regex string equivalent to --cateogry
+	Category string
+	// Tag is a regex string equivalent to --tag
+	Tag string
+	// Done is a flag to show checked task items instead of unchecked ones. This value is equivalent to
+	// --done option
+	Done bool
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *TodoCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = todoCommand(app).Command("list", "List unchecked task items across notes with 'category/file.md:line: text' format. Notes can be filtered as 'list' command. This is the default subcommand of 'todo'").Default()
+	cmd.cli.Flag("category", "Filter notes by category name with regular expression").Short('c').StringVar(&cmd.Category)
+	cmd.cli.Flag("tag", "Filter notes by tag name with regular expression").Short('t').StringVar(&cmd.Tag)
+	cmd.cli.Flag("done", "Show checked task items instead").Short('d').BoolVar(&cmd.Done)
+}
+
+func (cmd *TodoCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// Do runs `notes todo list` command and returns an error if occurs
+func (cmd *TodoCmd) Do() error {
+	catReg, tagReg, err := compileFilters(cmd.Category, cmd.Tag)
+	if err != nil {
+		return err
+	}
+
+	notes, err := collectNotes(cmd.Config, catReg, tagReg)
+	if err != nil {
+		return err
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].RelFilePath() < notes[j].RelFilePath()
+	})
+
+	out := bufio.NewWriter(cmd.Out)
+	for _, n := range notes {
+		tasks, err := n.tasks()
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if t.Done == cmd.Done {
+				fmt.Fprintf(out, "%s:%d: %s\n", filepath.ToSlash(n.RelFilePath()), t.Line, t.Text)
+			}
+		}
+	}
+	return out.Flush()
+}
+
+// TodoCheckCmd represents `notes todo check` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type TodoCheckCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Ref is a reference to task item as 'category/file.md:line' which is output by `notes todo`
+	Ref string
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+}
+
+func (cmd *TodoCheckCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = todoCommand(app).Command("check", "Check the task item in the note. The note is modified in place")
+	cmd.cli.Arg("ref", "Task item to check as 'category/file.md:line' which is output by 'todo' command. '.md' can be omitted").Required().StringVar(&cmd.Ref)
+}
+
+func (cmd *TodoCheckCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// parseRef parses 'category/file.md:line' into relative path of note and line number
+func (cmd *TodoCheckCmd) parseRef() (string, int, error) {
+	i := strings.LastIndexByte(cmd.Ref, ':')
+	if i < 0 {
+		return "", 0, errors.Errorf("Line number is missing in task '%s'. Please specify it with 'category/file.md:line'", cmd.Ref)
+	}
+	line, err := strconv.Atoi(cmd.Ref[i+1:])
+	if err != nil || line <= 0 {
+		return "", 0, errors.Errorf("Invalid line number '%s' in task '%s'", cmd.Ref[i+1:], cmd.Ref)
+	}
+	rel, err := noteRelPath(cmd.Config.HomePath, cmd.Ref[:i])
+	if err != nil {
+		return "", 0, err
+	}
+	return rel, line, nil
+}
+
+// Do runs `notes todo check` command and returns an error if occurs
+func (cmd *TodoCheckCmd) Do() error {
+	rel, line, err := cmd.parseRef()
+	if err != nil {
+		return err
+	}
+
+	note, err := LoadNote(filepath.Join(cmd.Config.HomePath, filepath.FromSlash(rel)), cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := note.tasks()
+	if err != nil {
+		return err
+	}
+	var task *noteTask
+	for _, t := range tasks {
+		if t.Line == line {
+			task = t
+			break
+		}
+	}
+	if task == nil {
+		return errors.Errorf("No task item is at line %d of note '%s'", line, rel)
+	}
+	if task.Done {
+		return errors.Errorf("Task item at line %d of note '%s' is already checked: %s", line, rel, task.Text)
+	}
+
+	path := note.FilePath()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot read note '%s'", rel)
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	m := reTask.FindStringSubmatchIndex(lines[line-1])
+	lines[line-1] = lines[line-1][:m[4]] + "x" + lines[line-1][m[5]:]
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return errors.Wrapf(err, "Cannot write note '%s'", rel)
+	}
+
+	fmt.Fprintf(cmd.Out, "Checked %s:%d: %s\n", rel, line, task.Text)
+	return autoSave(cmd.Config, path)
+}
@@ -0,0 +1,192 @@
+package notes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testTodoConfig() *Config {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	return &Config{HomePath: filepath.Join(cwd, "testdata", "todo", "normal")}
+}
+
+func TestTodoCmd(t *testing.T) {
+	for _, tc := range []struct {
+		what     string
+		category string
+		tag      string
+		done     bool
+		want     string
+	}{
+		{
+			what: "unchecked",
+			want: "memo/shopping.md:7: milk\n" +
+				"memo/shopping.md:9: bread\n" +
+				"work/release.md:9: update changelog\n" +
+				"work/release.md:11: tag release\n",
+		},
+		{
+			what: "checked",
+			done: true,
+			want: "memo/shopping.md:8: eggs\n" +
+				"memo/shopping.md:10: butter\n" +
+				"work/release.md:10: run tests\n",
+		},
+		{
+			what:     "category",
+			category: "^work$",
+			want: "work/release.md:9: update changelog\n" +
+				"work/release.md:11: tag release\n",
+		},
+		{
+			what: "tag",
+			tag:  "private",
+			done: true,
+			want: "memo/shopping.md:8: eggs\n" +
+				"memo/shopping.md:10: butter\n",
+		},
+		{
+			what:     "no note",
+			category: "^unknown$",
+			want:     "",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &TodoCmd{Config: testTodoConfig(), Category: tc.category, Tag: tc.tag, Done: tc.done, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have := buf.String(); have != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestTodoCmdInvalidFilter(t *testing.T) {
+	cmd := &TodoCmd{Config: testTodoConfig(), Tag: "(", Out: &bytes.Buffer{}}
+	err := cmd.Do()
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "Regular expression for filtering tags is invalid") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func prepareHomeForTodoCheckTest(home string) *Config {
+	src := testTodoConfig()
+	cfg := &Config{HomePath: home, GitPath: "git"}
+	for _, rel := range []string{"memo/shopping.md", "work/release.md"} {
+		b, err := os.ReadFile(filepath.Join(src.HomePath, filepath.FromSlash(rel)))
+		panicIfErr(err)
+		writeTestNote(cfg, rel, string(b))
+	}
+	return cfg
+}
+
+func TestTodoCheckCmd(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	home := filepath.Join(cwd, "test-tmp-dir-todo-check")
+	defer func() { panicIfErr(os.RemoveAll(home)) }()
+	cfg := prepareHomeForTodoCheckTest(home)
+
+	for _, tc := range []struct {
+		ref  string
+		out  string
+		line string
+	}{
+		{"memo/shopping.md:7", "Checked memo/shopping.md:7: milk\n", "- [x] milk"},
+		{"memo/shopping:9", "Checked memo/shopping.md:9: bread\n", "* [x]  bread  "},
+		{"work/release.md:11", "Checked work/release.md:11: tag release\n", "3) [x] tag release"},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &TodoCheckCmd{Config: cfg, Ref: tc.ref, Out: &buf}
+			if err := cmd.Do(); err != nil {
+				t.Fatal(err)
+			}
+			if have := buf.String(); have != tc.out {
+				t.Fatalf("Wanted output %q but have %q", tc.out, have)
+			}
+
+			path := strings.SplitN(tc.ref, ":", 2)[0]
+			if !strings.HasSuffix(path, ".md") {
+				path += ".md"
+			}
+			b, err := os.ReadFile(filepath.Join(home, filepath.FromSlash(path)))
+			panicIfErr(err)
+			if !strings.Contains(string(b), "\n"+tc.line+"\n") {
+				t.Fatalf("Task was not checked: %q", string(b))
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := (&TodoCmd{Config: cfg, Out: &buf}).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if have := buf.String(); have != "work/release.md:9: update changelog\n" {
+		t.Fatalf("Unexpected unchecked tasks after checking: %q", have)
+	}
+}
+
+func TestTodoCheckCmdError(t *testing.T) {
+	for _, tc := range []struct {
+		ref  string
+		want string
+	}{
+		{"memo/shopping.md", "Line number is missing in task 'memo/shopping.md'"},
+		{"memo/shopping.md:foo", "Invalid line number 'foo'"},
+		{"memo/shopping.md:0", "Invalid line number '0'"},
+		{"memo/unknown.md:1", "Cannot open note file"},
+		{"memo/shopping.md:8", "Task item at line 8 of note 'memo/shopping.md' is already checked: eggs"},
+		{"memo/shopping.md:3", "No task item is at line 3 of note 'memo/shopping.md'"},
+		{"memo/shopping.md:13", "No task item is at line 13 of note 'memo/shopping.md'"},
+		{"memo/shopping.md:100", "No task item is at line 100 of note 'memo/shopping.md'"},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			cmd := &TodoCheckCmd{Config: testTodoConfig(), Ref: tc.ref, Out: &bytes.Buffer{}}
+			err := cmd.Do()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestTodoCheckCmdAutoSave(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-todo-save")
+	defer repos.cleanup()
+	cfg := repos.clone("home")
+	prepareHomeForTodoCheckTest(cfg.HomePath)
+	git := NewGit(cfg)
+	if err := git.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.Commit("first commit"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Save.Auto = true
+	if err := (&TodoCheckCmd{Config: cfg, Ref: "work/release.md:9", Out: &bytes.Buffer{}}).Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := git.Exec("status", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "" {
+		t.Fatal("Changes were not committed:", out)
+	}
+}
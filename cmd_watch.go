@@ -0,0 +1,310 @@
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// watchEvent is a JSON representation of a change of note output by `notes watch`. Metadata of note is
+// omitted when the note cannot be loaded such as deleted notes
+type watchEvent struct {
+	Op       string     `json:"op"`
+	Path     string     `json:"path"`
+	Category string     `json:"category"`
+	File     string     `json:"file"`
+	Title    string     `json:"title,omitempty"`
+	Tags     []string   `json:"tags,omitempty"`
+	Created  *time.Time `json:"created,omitempty"`
+	Time     time.Time  `json:"time"`
+}
+
+// WatchCmd represents `notes watch` command. Each public fields represent options of the command.
+// Out field represents where this command should output.
+type WatchCmd struct {
+	cli    *kingpin.CmdClause
+	Config *Config
+	// Exec is a command run after notes are changed. Empty means 'watch.command' config value. This value is
+	// equivalent to --exec option
+	Exec string
+	// Save is a flag to commit changed notes with Git. This value is equivalent to --save option and its
+	// default value is 'watch.save' config
+	Save bool
+	// Debounce is a duration to wait for following changes before running command or committing notes.
+	// This value is equivalent to --debounce option
+	Debounce time.Duration
+	// Out is a writer to write output of this command. Kind of stdout is expected
+	Out io.Writer
+
+	git *Git
+	// ready is closed when watching home started. This is used for testing
+	ready chan struct{}
+}
+
+func (cmd *WatchCmd) defineCLI(app *kingpin.Application) {
+	cmd.cli = app.Command("watch", "Watch changes of notes in home and output each change as JSON line. Command can be run or changed notes can be committed after changes settle down")
+	cmd.cli.Flag("exec", "Shell command run in home after notes are changed. Changed notes are passed as $NOTES_CLI_WATCH_CHANGES separated with newlines. 'watch.command' config is used by default").Short('e').StringVar(&cmd.Exec)
+	save := cmd.cli.Flag("save", "Commit changed notes with Git after notes are changed. The commit is pushed when 'save.auto_push' is configured. 'watch.save' config is used by default")
+	if cmd.Config != nil && cmd.Config.Watch.Save {
+		// Default value can be configured in [watch] section of config file. --no-save disables it
+		save.Default("true")
+	}
+	save.BoolVar(&cmd.Save)
+	cmd.cli.Flag("debounce", "Duration to wait for following changes before running command or committing notes").Default("1s").DurationVar(&cmd.Debounce)
+}
+
+func (cmd *WatchCmd) matchesCmdline(cmdline string) bool {
+	return cmd.cli.FullCommand() == cmdline
+}
+
+// watchDirs adds watches for the directory and all its subdirectories recursively. Hidden directories
+// such as .git are not watched
+func watchDirs(w *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// The directory may be removed while walking
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return errors.Wrapf(w.Add(path), "Cannot watch directory '%s'", canonPath(path))
+	})
+}
+
+// relNotePath returns a slash-separated relative path from home when the path is a note in some category.
+// Otherwise it returns false
+func (cmd *WatchCmd) relNotePath(path string) (string, bool) {
+	rel, err := filepath.Rel(cmd.Config.HomePath, path)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasSuffix(rel, ".md") || !strings.Contains(rel, "/") {
+		return "", false
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if part == ".." || strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	return rel, true
+}
+
+func (cmd *WatchCmd) emit(op, rel string) error {
+	dir, file := filepath.Split(filepath.FromSlash(rel))
+	ev := &watchEvent{
+		Op:       op,
+		Path:     rel,
+		Category: filepath.ToSlash(filepath.Clean(dir)),
+		File:     file,
+		Time:     time.Now(),
+	}
+	if op != "delete" && op != "rename" {
+		if note, err := LoadNote(filepath.Join(cmd.Config.HomePath, filepath.FromSlash(rel)), cmd.Config); err == nil {
+			ev.Title = note.Title
+			ev.Tags = note.Tags
+			ev.Created = &note.Created
+		}
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot encode change of note '%s' as JSON", rel)
+	}
+	b = append(b, '\n')
+	_, err = cmd.Out.Write(b)
+	return err
+}
+
+// handleEvent outputs the event when it is a change of note and returns changed notes. When a directory
+// is created, it is watched and notes already put in it are reported as created
+func (cmd *WatchCmd) handleEvent(w *fsnotify.Watcher, ev fsnotify.Event) ([]string, error) {
+	if ev.Op&fsnotify.Create != 0 {
+		if s, err := os.Stat(ev.Name); err == nil && s.IsDir() {
+			if strings.HasPrefix(filepath.Base(ev.Name), ".") {
+				return nil, nil
+			}
+			if err := watchDirs(w, ev.Name); err != nil {
+				return nil, err
+			}
+			changed := []string{}
+			err := filepath.Walk(ev.Name, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return nil
+				}
+				if rel, ok := cmd.relNotePath(path); ok {
+					changed = append(changed, rel)
+					return cmd.emit("create", rel)
+				}
+				return nil
+			})
+			return changed, err
+		}
+	}
+
+	rel, ok := cmd.relNotePath(ev.Name)
+	if !ok {
+		return nil, nil
+	}
+
+	var op string
+	switch {
+	case ev.Op&fsnotify.Create != 0:
+		op = "create"
+	case ev.Op&fsnotify.Write != 0:
+		op = "modify"
+	case ev.Op&fsnotify.Rename != 0:
+		op = "rename"
+	case ev.Op&fsnotify.Remove != 0:
+		op = "delete"
+	default:
+		// Ignore chmod
+		return nil, nil
+	}
+
+	return []string{rel}, cmd.emit(op, rel)
+}
+
+func (cmd *WatchCmd) command() string {
+	if cmd.Exec != "" {
+		return cmd.Exec
+	}
+	return cmd.Config.Watch.Command
+}
+
+func (cmd *WatchCmd) runCommand(changed []string) error {
+	cmdline, err := shellquote.Split(cmd.command())
+	if err != nil {
+		return errors.Wrapf(err, "Cannot parse command line '%s' run on changes", cmd.command())
+	}
+	if len(cmdline) == 0 {
+		return nil
+	}
+
+	c := exec.Command(cmdline[0], cmdline[1:]...)
+	c.Dir = cmd.Config.HomePath
+	// Paths are separated with newlines since file names of notes may contain spaces
+	c.Env = append(os.Environ(), "NOTES_CLI_WATCH_CHANGES="+strings.Join(changed, "\n"))
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	return errors.Wrapf(c.Run(), "Command '%s' run on changes did not exit successfully", cmd.command())
+}
+
+// settle is called after changes of notes settle down. It runs the command and commits the changed notes
+func (cmd *WatchCmd) settle(changed []string) error {
+	sort.Strings(changed)
+
+	if cmd.command() != "" {
+		if err := cmd.runCommand(changed); err != nil {
+			return err
+		}
+	}
+
+	if cmd.git == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(changed))
+	for _, rel := range changed {
+		paths = append(paths, filepath.Join(cmd.Config.HomePath, filepath.FromSlash(rel)))
+	}
+	return errors.Wrap(commitNotes(cmd.git, cmd.Config, cmd.Config.Save.AutoPush, paths...), "Cannot save changed notes")
+}
+
+// watch watches changes of notes until the context is done. Errors while watching are reported to stderr
+// and watching continues
+func (cmd *WatchCmd) watch(ctx context.Context) error {
+	if cmd.Save {
+		git, err := newGitForCmd("watch", cmd.Config)
+		if err != nil {
+			return err
+		}
+		cmd.git = git
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "Cannot start watching notes")
+	}
+	defer w.Close()
+
+	if err := watchDirs(w, cmd.Config.HomePath); err != nil {
+		return err
+	}
+	if cmd.ready != nil {
+		close(cmd.ready)
+	}
+
+	timer := time.NewTimer(cmd.Debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	pending := map[string]struct{}{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			changed, err := cmd.handleEvent(w, ev)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+			if len(changed) == 0 {
+				break
+			}
+			for _, rel := range changed {
+				pending[rel] = struct{}{}
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(cmd.Debounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, "Error: Cannot watch notes:", err)
+		case <-timer.C:
+			changed := make([]string, 0, len(pending))
+			for rel := range pending {
+				changed = append(changed, rel)
+			}
+			pending = map[string]struct{}{}
+			if err := cmd.settle(changed); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+		}
+	}
+}
+
+// Do runs `notes watch` command and returns an error if occurs. It watches notes until interrupted
+func (cmd *WatchCmd) Do() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return cmd.watch(ctx)
+}
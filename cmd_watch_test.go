@@ -0,0 +1,196 @@
+package notes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchTestBuffer is a buffer which can be written by watcher and read by test concurrently
+type watchTestBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *watchTestBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *watchTestBuffer) events() []*watchEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	evs := []*watchEvent{}
+	for _, l := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if l == "" {
+			continue
+		}
+		var ev watchEvent
+		if err := json.Unmarshal([]byte(l), &ev); err != nil {
+			panic(err)
+		}
+		evs = append(evs, &ev)
+	}
+	return evs
+}
+
+func startWatchForTest(t *testing.T, cmd *WatchCmd) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd.ready = make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.watch(ctx)
+	}()
+	select {
+	case <-cmd.ready:
+	case err := <-done:
+		t.Fatal(err)
+	}
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func waitForWatchTest(t *testing.T, what string, pred func() bool) {
+	for i := 0; i < 500; i++ {
+		if pred() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for", what)
+}
+
+func findWatchEvent(evs []*watchEvent, op, path string) *watchEvent {
+	for _, ev := range evs {
+		if ev.Op == op && ev.Path == path {
+			return ev
+		}
+	}
+	return nil
+}
+
+func TestWatchCmdEmitChanges(t *testing.T) {
+	repos := newTestSyncRepos("test-tmp-dir-watch-changes")
+	defer repos.cleanup()
+	cfg := repos.clone("home")
+	panicIfErr(os.MkdirAll(filepath.Join(cfg.HomePath, "memo"), 0755))
+
+	var buf watchTestBuffer
+	stop := startWatchForTest(t, &WatchCmd{Config: cfg, Debounce: 10 * time.Millisecond, Out: &buf})
+	defer stop()
+
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"hello\n")
+	waitForWatchTest(t, "create event", func() bool {
+		ev := findWatchEvent(buf.events(), "create", "memo/foo.md")
+		return ev != nil
+	})
+	waitForWatchTest(t, "metadata of note", func() bool {
+		// Metadata may not be available at create event since the file may be empty at the time
+		for _, ev := range buf.events() {
+			if ev.Path == "memo/foo.md" && ev.Title == "foo" {
+				return ev.Category == "memo" && ev.File == "foo.md" && ev.Created != nil
+			}
+		}
+		return false
+	})
+
+	writeTestNote(cfg, "memo/foo.md", testSyncNote+"hello\nworld\n")
+	waitForWatchTest(t, "modify event", func() bool {
+		return findWatchEvent(buf.events(), "modify", "memo/foo.md") != nil
+	})
+
+	// Notes in new directory are reported
+	writeTestNote(cfg, "blog/tech/bar.md", "bar\n===\n- Category: blog/tech\n- Tags: go\n- Created: 2018-10-30T11:37:45+09:00\n\n")
+	waitForWatchTest(t, "note in new directory", func() bool {
+		return findWatchEvent(buf.events(), "create", "blog/tech/bar.md") != nil
+	})
+
+	panicIfErr(os.Rename(filepath.Join(cfg.HomePath, "memo", "foo.md"), filepath.Join(cfg.HomePath, "memo", "renamed.md")))
+	waitForWatchTest(t, "rename event", func() bool {
+		evs := buf.events()
+		return findWatchEvent(evs, "rename", "memo/foo.md") != nil && findWatchEvent(evs, "create", "memo/renamed.md") != nil
+	})
+
+	panicIfErr(os.Remove(filepath.Join(cfg.HomePath, "memo", "renamed.md")))
+	waitForWatchTest(t, "delete event", func() bool {
+		ev := findWatchEvent(buf.events(), "delete", "memo/renamed.md")
+		return ev != nil && ev.Title == "" && ev.Category == "memo"
+	})
+
+	// Files which are not notes are ignored
+	writeTestNote(cfg, "README.md", "readme\n")
+	writeTestNote(cfg, "memo/image.png", "png\n")
+	writeTestNote(cfg, ".trash/memo/old.md", "old\n")
+	time.Sleep(100 * time.Millisecond)
+	for _, ev := range buf.events() {
+		switch ev.Path {
+		case "README.md", "memo/image.png", ".trash/memo/old.md":
+			t.Fatal("Unexpected event:", ev)
+		}
+	}
+}
+
+func TestWatchCmdExecAndSave(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available on Windows")
+	}
+
+	repos := newTestSyncRepos("test-tmp-dir-watch-save")
+	defer repos.cleanup()
+	cfg := repos.clone("home")
+	panicIfErr(os.MkdirAll(filepath.Join(cfg.HomePath, "memo"), 0755))
+
+	var buf watchTestBuffer
+	stop := startWatchForTest(t, &WatchCmd{
+		Config:   cfg,
+		Exec:     `sh -c 'echo "$NOTES_CLI_WATCH_CHANGES" > .changes'`,
+		Save:     true,
+		Debounce: 100 * time.Millisecond,
+		Out:      &buf,
+	})
+	defer stop()
+
+	writeTestNote(cfg, "memo/foo bar.md", testSyncNote+"hello\n")
+	writeTestNote(cfg, "memo/bar.md", testSyncNote+"world\n")
+
+	git := NewGit(cfg)
+	waitForWatchTest(t, "commit", func() bool {
+		out, err := git.Exec("log", "--format=%s")
+		return err == nil && strings.Contains(out, "bar.md") && strings.Contains(out, "foo bar.md")
+	})
+
+	b, err := os.ReadFile(filepath.Join(cfg.HomePath, ".changes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := strings.TrimSpace(string(b)); have != "memo/bar.md\nmemo/foo bar.md" {
+		t.Fatalf("Changes passed to command are unexpected: %q", have)
+	}
+
+	if out, err := git.Exec("status", "--porcelain", "--", "memo"); err != nil || out != "" {
+		t.Fatal("Changed notes were not committed:", out, err)
+	}
+}
+
+func TestWatchCmdSaveWithoutGitRepo(t *testing.T) {
+	cfg := &Config{GitPath: "git", HomePath: filepath.Join("testdata", "export", "normal")}
+	err := (&WatchCmd{Config: cfg, Save: true}).watch(context.Background())
+	if err == nil {
+		t.Fatal("Error did not occur")
+	}
+	if !strings.Contains(err.Error(), "'.git' directory does not exist") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -19,13 +19,23 @@ func init() {
 		"EDITOR",
 		"XDG_DATA_HOME",
 		"PAGER",
+		"APPDATA",
 	} {
 		os.Unsetenv(env)
 	}
+
+	// Do not read user's config file while running tests
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	os.Setenv("XDG_CONFIG_HOME", testNoConfigFileDir(cwd))
 }
 
 // Test utilities
 
+func testNoConfigFileDir(cwd string) string {
+	return filepath.Join(cwd, "testdata", "config", "no-config-file")
+}
+
 func panicIfErr(err error) {
 	if err != nil {
 		panic(err)
@@ -2,6 +2,7 @@
 complete -c notes -s h -l help -d "Show context-sensitive help."
 complete -c notes -s A -l color-always -d "Enable color output always"
 complete -c notes -l no-color -d "Disable color output"
+complete -c notes -s n -l notebook -xa '(notes notebooks --name-only)' -d "Name of notebook to use as home"
 complete -c notes -l version -d "Show application version."
 
 # Subcommands
@@ -12,7 +13,25 @@ complete -c notes -n '__fish_use_subcommand' -xa 'ls' -d "List notes with filter
 complete -c notes -n '__fish_use_subcommand' -xa 'categories' -d "List all categories to stdout (alias: cats)"
 complete -c notes -n '__fish_use_subcommand' -xa 'cats' -d "List all categories to stdout (alias: cats)"
 complete -c notes -n '__fish_use_subcommand' -xa 'tags' -d "List all tags"
-complete -c notes -n '__fish_use_subcommand' -xa 'save' -d "Save notes using Git. It adds all notes and creates a commit to Git repository at home directory"
+complete -c notes -n '__fish_use_subcommand' -xa 'notebooks' -d "List all notebooks configured in [notebooks] section of config file with their home directories. Current notebook is marked with '*'"
+complete -c notes -n '__fish_use_subcommand' -xa 'prune' -d "Remove empty category directories which contain no note. Removed directories are listed and confirmed before removing them"
+complete -c notes -n '__fish_use_subcommand' -xa 'save' -d "Save notes using Git. It adds all notes and creates a commit to Git repository at home directory. When current branch tracks a remote branch or 'save.remote' is configured, the commit is pushed to the remote"
+complete -c notes -n '__fish_use_subcommand' -xa 'sync' -d "Sync notes with remote Git repository. It commits all changes, fetches the remote branch, rebases or merges it and pushes the result"
+complete -c notes -n '__fish_use_subcommand' -xa 'git-setup' -d "Set up Git repository at home with default .gitignore and .gitattributes"
+complete -c notes -n '__fish_use_subcommand' -xa 'git' -d "Run Git command at home directory. All arguments are passed to Git as-is"
+complete -c notes -n '__fish_use_subcommand' -xa 'log' -d "Show commits which touched the note with their hashes, dates and messages"
+complete -c notes -n '__fish_use_subcommand' -xa 'diff' -d "Show changes of the note from the revision using Git"
+complete -c notes -n '__fish_use_subcommand' -xa 'restore' -d "Restore the note to older version at the revision using Git"
+complete -c notes -n '__fish_use_subcommand' -xa 'export' -d "Export notes to other formats"
+complete -c notes -n '__fish_use_subcommand' -xa 'import' -d "Import notes from other formats or tools"
+complete -c notes -n '__fish_use_subcommand' -xa 'serve' -d "Serve web UI to browse and search notes, and JSON API over HTTP. It is read-only unless --writable is given"
+complete -c notes -n '__fish_use_subcommand' -xa 'watch' -d "Watch changes of notes in home and output each change as JSON line"
+complete -c notes -n '__fish_use_subcommand' -xa 'links' -d "Show notes linked from the note with [[category/file]] or [[Title]] style links"
+complete -c notes -n '__fish_use_subcommand' -xa 'backlinks' -d "Show notes which refer the note with [[category/file]] or [[Title]] style links"
+complete -c notes -n '__fish_use_subcommand' -xa 'mv' -d "Move or rename the note and update links referring it"
+complete -c notes -n '__fish_use_subcommand' -xa 'graph' -d "Output graph of notes, categories, tags and links between notes in Graphviz DOT or JSON format"
+complete -c notes -n '__fish_use_subcommand' -xa 'todo' -d "List and check task items like '- [ ] task' in notes"
+complete -c notes -n '__fish_use_subcommand' -xa 'agenda' -d "Show overdue, today's and upcoming notes and task items with due dates grouped by category"
 complete -c notes -n '__fish_use_subcommand' -xa 'config' -d "Output config values to stdout. By default output all values with KEY=VALUE style"
 complete -c notes -n '__fish_use_subcommand' -xa 'selfupdate' -d "Update myself to the latest version. It downloads the latest version executable and replaces current executable with it"
 
@@ -29,8 +48,75 @@ complete -c notes -n '__fish_seen_subcommand_from ls list' -s r -l relative -d '
 complete -c notes -n '__fish_seen_subcommand_from ls list' -s o -l oneline -d "Show oneline information of note instead of path"
 complete -c notes -n '__fish_seen_subcommand_from ls list' -l sort -d "Sort results by 'modified', 'created', 'filename' or 'category'. 'created' is default"
 complete -c notes -n '__fish_seen_subcommand_from ls list' -s e -l edit -d 'Open listed notes with an editor. $NOTES_CLI_EDITOR must be set'
+complete -c notes -n '__fish_seen_subcommand_from ls list' -s a -l all-notebooks -d 'List notes in all notebooks'
+
+complete -c notes -n '__fish_seen_subcommand_from notebooks' -l name-only -d 'Show only names of notebooks'
+
+complete -c notes -n '__fish_seen_subcommand_from categories cats' -l empty -d "List empty category directories and non-note files instead"
+
+complete -c notes -n '__fish_seen_subcommand_from prune' -s y -l yes -d "Remove empty directories without confirmation"
 
 complete -c notes -n '__fish_seen_subcommand_from save' -l message -d "Commit message on save"
+complete -c notes -n '__fish_seen_subcommand_from save' -s c -l category -d "Save only notes whose categories match to the regular expression"
+complete -c notes -n '__fish_seen_subcommand_from save' -s t -l tag -d "Save only notes which have a tag matching to the regular expression"
+complete -c notes -n '__fish_seen_subcommand_from save' -l dry-run -d "Show changes which would be committed without committing them"
+complete -c notes -n '__fish_seen_subcommand_from save' -l no-push -d "Only commit notes without pushing them"
+
+complete -c notes -n '__fish_seen_subcommand_from sync' -s r -l remote -d "Remote name to sync with"
+complete -c notes -n '__fish_seen_subcommand_from sync' -s b -l branch -d "Branch name of the remote to sync with"
+complete -c notes -n '__fish_seen_subcommand_from sync' -l strategy -xa 'rebase merge' -d "How to integrate changes from the remote"
+complete -c notes -n '__fish_seen_subcommand_from sync' -s m -l message -d "Commit message for local changes"
+complete -c notes -n '__fish_seen_subcommand_from git-setup' -l attributes -d "Write .gitattributes even if 'gitattributes' config is not enabled"
+complete -c notes -n '__fish_seen_subcommand_from git' -xa '(complete -C "git "(string join " " -- (commandline -opc)[3..-1])" "(commandline -ct))'
+
+complete -c notes -n '__fish_seen_subcommand_from export; and not __fish_seen_subcommand_from html bundle hugo jekyll' -xa 'html' -d "Export all notes to static HTML site"
+complete -c notes -n '__fish_seen_subcommand_from export; and not __fish_seen_subcommand_from html bundle hugo jekyll' -xa 'bundle' -d "Export notes into one Markdown document or JSON archive"
+complete -c notes -n '__fish_seen_subcommand_from export; and not __fish_seen_subcommand_from html bundle hugo jekyll' -xa 'hugo' -d "Export notes to content directory of Hugo"
+complete -c notes -n '__fish_seen_subcommand_from export; and not __fish_seen_subcommand_from html bundle hugo jekyll' -xa 'jekyll' -d "Export notes as posts of Jekyll"
+complete -c notes -n '__fish_seen_subcommand_from html' -s o -l out -r -d "Directory to generate the site"
+complete -c notes -n '__fish_seen_subcommand_from bundle; and __fish_seen_subcommand_from export' -s c -l category -d "Filter notes by category name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from bundle; and __fish_seen_subcommand_from export' -s t -l tag -d "Filter notes by tag name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from bundle; and __fish_seen_subcommand_from export' -s s -l sort -xa 'modified created filename category' -d "Sort notes"
+complete -c notes -n '__fish_seen_subcommand_from bundle; and __fish_seen_subcommand_from export' -l format -xa 'markdown json' -d "Format of the bundle"
+complete -c notes -n '__fish_seen_subcommand_from bundle; and __fish_seen_subcommand_from export' -s o -l out -r -d "File to write the bundle"
+complete -c notes -n '__fish_seen_subcommand_from hugo jekyll' -s c -l category -d "Publish only notes whose categories match to the regular expression"
+complete -c notes -n '__fish_seen_subcommand_from hugo jekyll' -s t -l tag -d "Publish only notes which have a tag matching to the regular expression"
+complete -c notes -n '__fish_seen_subcommand_from hugo jekyll' -l exclude-tag -d "Do not publish notes which have a tag matching to the regular expression"
+complete -c notes -n '__fish_seen_subcommand_from hugo jekyll' -s o -l out -r -d "Content directory of the site"
+complete -c notes -n '__fish_seen_subcommand_from import; and not __fish_seen_subcommand_from bundle memolist dir enex' -xa 'bundle' -d "Import notes from JSON bundle"
+complete -c notes -n '__fish_seen_subcommand_from import; and not __fish_seen_subcommand_from bundle memolist dir enex' -xa 'memolist' -d "Import memos of memolist.vim"
+complete -c notes -n '__fish_seen_subcommand_from import; and not __fish_seen_subcommand_from bundle memolist dir enex' -xa 'dir' -d "Import Markdown files in directory"
+complete -c notes -n '__fish_seen_subcommand_from import; and not __fish_seen_subcommand_from bundle memolist dir enex' -xa 'enex' -d "Import notes in ENEX file exported by Evernote"
+complete -c notes -n '__fish_seen_subcommand_from dir; and __fish_seen_subcommand_from import' -s c -l category -d "Category for files put directly under the directory"
+complete -c notes -n '__fish_seen_subcommand_from enex; and __fish_seen_subcommand_from import' -s c -l category -d "Category of imported notes"
+complete -c notes -n '__fish_seen_subcommand_from dir enex; and __fish_seen_subcommand_from import' -l conflict -xa 'skip overwrite rename' -d "What to do when a note already exists"
+complete -c notes -n '__fish_seen_subcommand_from dir; and __fish_seen_subcommand_from import' -l dry-run -d "Show what would be imported"
+
+complete -c notes -n '__fish_seen_subcommand_from serve' -s a -l addr -x -d "Address to listen"
+complete -c notes -n '__fish_seen_subcommand_from serve' -s w -l writable -d "Enable write API to create, update and delete notes"
+complete -c notes -n '__fish_seen_subcommand_from serve' -l token -x -d "Token required for write API"
+complete -c notes -n '__fish_seen_subcommand_from serve' -l commit -d "Commit each change by write API with Git"
+complete -c notes -n '__fish_seen_subcommand_from watch' -s e -l exec -x -d "Shell command run in home after notes are changed"
+complete -c notes -n '__fish_seen_subcommand_from watch' -l save -d "Commit changed notes with Git after notes are changed"
+complete -c notes -n '__fish_seen_subcommand_from watch' -l debounce -x -d "Duration to wait for following changes"
+complete -c notes -n '__fish_seen_subcommand_from links' -s b -l broken -d "Show links which refer no note"
+complete -c notes -n '__fish_seen_subcommand_from graph' -s c -l category -x -d "Filter notes by category name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from graph' -s t -l tag -x -d "Filter notes by tag name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from graph' -s f -l format -xa 'dot json' -d "Format of the graph"
+complete -c notes -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from list check' -xa 'list' -d "List unchecked task items across notes"
+complete -c notes -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from list check' -xa 'check' -d "Check the task item in the note"
+complete -c notes -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from check' -s c -l category -x -d "Filter notes by category name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from check' -s t -l tag -x -d "Filter notes by tag name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from check' -s d -l done -d "Show checked task items instead"
+complete -c notes -n '__fish_seen_subcommand_from agenda' -s c -l category -x -d "Filter notes by category name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from agenda' -s t -l tag -x -d "Filter notes by tag name with regular expression"
+complete -c notes -n '__fish_seen_subcommand_from agenda' -l ics -r -d "Write all items to the iCalendar file"
+
+complete -c notes -n '__fish_seen_subcommand_from log diff restore save links backlinks mv' -xa '(notes list --relative)'
+
+complete -c notes -n '__fish_seen_subcommand_from config' -s s -l source -d "Show where each value came from"
+complete -c notes -n '__fish_seen_subcommand_from config' -l format -xa 'text json' -d "Output format"
+complete -c notes -n '__fish_seen_subcommand_from config' -l local -d "Write .notes.toml in home directory"
 
 complete -c notes -n '__fish_seen_subcommand_from selfupdate' -l dry -d 'Dry run update. Only check the newer version is available'
 
@@ -38,6 +124,24 @@ complete -c notes -n '__fish_seen_subcommand_from selfupdate' -l dry -d 'Dry run
 complete -c notes -n '__fish_seen_subcommand_from config' -xa 'home' -d "Home directory of notes-cli"
 complete -c notes -n '__fish_seen_subcommand_from config' -xa 'editor' -d "Editor command path to open note"
 complete -c notes -n '__fish_seen_subcommand_from config' -xa 'git' -d "Git command path to save notes"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'pager' -d "Pager command for paging output"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'color' -d "Default of color output"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'gitattributes' -d "Write .gitattributes on creating Git repository"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'list.sort' -d "Default value of --sort of list command"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'list.oneline' -d "Default value of --oneline of list command"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'save.message_template' -d "Template of commit message of save command"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'save.auto' -d "Commit notes automatically after editing them"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'save.auto_push' -d "Push notes committed automatically"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'save.remote' -d "Remote name to push notes on save"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'save.branch' -d "Branch name of the remote to push notes on save"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'sync.remote' -d "Remote name to sync with"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'sync.branch' -d "Branch name of the remote to sync with"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'sync.strategy' -d "How to integrate changes on sync"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'export.category' -d "Categories of notes to publish with export hugo/jekyll"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'export.tag' -d "Tags of notes to publish with export hugo/jekyll"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'export.exclude_tag' -d "Tags of notes not to publish with export hugo/jekyll"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'set' -d "Set config value to config file"
+complete -c notes -n '__fish_seen_subcommand_from config' -xa 'unset' -d "Remove config value from config file"
 
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'help' -d "Show help."
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'new' -d "Create a new note with given category and file name"
@@ -46,6 +150,8 @@ complete -c notes -n '__fish_seen_subcommand_from help' -xa 'ls' -d "List notes
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'categories' -d "List all categories to stdout (alias: cats)"
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'cats' -d "List all categories to stdout (alias: cats)"
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'tags' -d "List all tags"
+complete -c notes -n '__fish_seen_subcommand_from help' -xa 'notebooks' -d "List all notebooks configured in [notebooks] section of config file with their home directories. Current notebook is marked with '*'"
+complete -c notes -n '__fish_seen_subcommand_from help' -xa 'prune' -d "Remove empty category directories which contain no note. Removed directories are listed and confirmed before removing them"
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'save' -d "Save notes using Git. It adds all notes and creates a commit to Git repository at home directory"
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'config' -d "Output config values to stdout. By default output all values with KEY=VALUE style"
 complete -c notes -n '__fish_seen_subcommand_from help' -xa 'selfupdate' -d "Update myself to the latest version. It downloads the latest version executable and replaces current executable with it"
@@ -7,14 +7,78 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// Config represents user configuration of notes command
+// ListConfig represents default values of options of `notes list` command. They can be configured in
+// [list] section of config file
+type ListConfig struct {
+	// SortBy is a default value of --sort option. Empty means the default order (created)
+	SortBy string
+	// Oneline is a default value of --oneline option
+	Oneline bool
+}
+
+// SaveConfig represents configuration of `notes save` command. They can be configured in [save] section
+// of config file
+type SaveConfig struct {
+	// MessageTemplate is a Go template to generate commit message automatically. Empty means the default
+	// template
+	MessageTemplate string
+	// Auto is true when notes should be committed automatically after editing them with `notes new` or
+	// `notes list --edit`
+	Auto bool
+	// AutoPush is true when notes committed automatically should also be pushed to remote
+	AutoPush bool
+	// Remote is a remote name to push notes. Empty means the tracking remote of current branch. When
+	// current branch tracks no branch and this value is empty, notes are not pushed
+	Remote string
+	// Branch is a branch name of the remote to push notes. Empty means the tracking branch or current branch
+	Branch string
+}
+
+// SyncConfig represents configuration of `notes sync` command. They can be configured in [sync] section
+// of config file
+type SyncConfig struct {
+	// Remote is a remote name to sync with. Empty means the tracking remote of current branch or "origin"
+	Remote string
+	// Branch is a branch name of the remote to sync with. Empty means the tracking branch or current branch
+	Branch string
+	// Strategy is how to integrate changes from the remote. "rebase" or "merge". Empty means "rebase"
+	Strategy string
+}
+
+// ExportConfig represents rules to select notes published by `notes export hugo` and `notes export jekyll`.
+// They can be configured in [export] section of config file
+type ExportConfig struct {
+	// Category is a regular expression to select notes to publish by their categories. Empty means all
+	Category string
+	// Tag is a regular expression to select notes to publish by their tags. Empty means all
+	Tag string
+	// ExcludeTag is a regular expression to exclude notes which have a matching tag such as "draft".
+	// Empty means nothing is excluded
+	ExcludeTag string
+}
+
+// WatchConfig represents configuration of `notes watch` command. They can be configured in [watch]
+// section of config file
+type WatchConfig struct {
+	// Command is a shell command run after notes are changed. Empty means no command is run
+	Command string
+	// Save is true when changed notes should be committed automatically
+	Save bool
+}
+
+// Config represents user configuration of notes command. Each value can be configured with config file
+// at $XDG_CONFIG_HOME/notes-cli/config.toml or $NOTES_CLI_HOME/.notes.toml in TOML format (config.yaml and
+// .notes.yaml in YAML format are also available). The latter is prioritized. Environment variables are
+// always prioritized over config files.
 type Config struct {
 	// HomePath is a file path to directory of home of notes command. If $NOTES_CLI_HOME is set, it is used.
-	// Otherwise, notes-cli directory in XDG data directory is used. This directory is automatically created
-	// when config is created
+	// Otherwise, 'home' in config file or notes-cli directory in XDG data directory is used. This directory
+	// is automatically created when config is created
 	HomePath string
 	// GitPath is a file path to `git` executable. If $NOTES_CLI_GIT is set, it is used.
 	// Otherwise, `git` is used by default. This is optional and can be empty. When empty, some command
@@ -27,77 +91,274 @@ type Config struct {
 	EditorCmd string
 	// PagerCmd is a command for paging output from 'list' subcommand. If $NOTES_CLI_PAGER is set, it is used.
 	PagerCmd string
+	// GitAttributes is true when .gitattributes for Markdown diffs should be written to Git repository at
+	// home in addition to .gitignore
+	GitAttributes bool
+	// Color is a default of color output. One of "always", "never" or "auto". Empty means "auto".
+	// --color-always and --no-color flags are prioritized over this value
+	Color string
+	// List is default values of options of 'list' subcommand
+	List ListConfig
+	// Save is configuration of 'save' subcommand
+	Save SaveConfig
+	// Sync is configuration of 'sync' subcommand
+	Sync SyncConfig
+	// Export is configuration of 'export hugo' and 'export jekyll' subcommands
+	Export ExportConfig
+	// Watch is configuration of 'watch' subcommand
+	Watch WatchConfig
+	// Notebooks is a map from notebook name to its home directory. Notebooks can be configured in [notebooks]
+	// section of config file
+	Notebooks map[string]string
+	// Notebook is a name of the notebook whose home is HomePath. Empty when HomePath is not one of notebooks
+	Notebook string
+	// Sources is a map from config key (e.g. "home", "list.sort") to where the value came from. Value is
+	// an environment variable name like "$NOTES_CLI_HOME", a path to config file or "default"
+	Sources map[string]string
 }
 
-func homePath() (string, error) {
+func expandTilde(path, dir string) string {
+	if strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return filepath.Join(dir, path[2:])
+	}
+	return path
+}
+
+func notebookPaths(files configFiles) (map[string]string, error) {
+	ret := map[string]string{}
+	if len(files) == 0 {
+		return ret, nil
+	}
+
 	u, err := user.Current()
 	if err != nil {
-		return "", errors.Wrap(err, "Cannot locate home directory. Please set $NOTES_CLI_HOME")
+		return nil, errors.Wrap(err, "Cannot locate home directory to resolve notebooks")
 	}
 
-	if env := os.Getenv("NOTES_CLI_HOME"); env != "" {
-		if strings.HasPrefix(env, "~"+string(filepath.Separator)) {
-			env = filepath.Join(u.HomeDir, env[2:])
+	// Notebooks are configured only in user-wide config file
+	for name, path := range files[len(files)-1].Notebooks {
+		ret[name] = filepath.Clean(expandTilde(filepath.FromSlash(path), u.HomeDir))
+	}
+	return ret, nil
+}
+
+func homePath(files configFiles, notebook string, notebooks map[string]string) (string, string, error) {
+	if notebook != "" {
+		h, ok := notebooks[notebook]
+		if !ok {
+			ns := make([]string, 0, len(notebooks))
+			for n := range notebooks {
+				ns = append(ns, n)
+			}
+			sort.Strings(ns)
+			return "", "", errors.Errorf("Notebook '%s' is not configured. Configured notebooks are [%s]", notebook, strings.Join(ns, ", "))
 		}
-		return filepath.Clean(env), nil
+		return h, "--notebook", nil
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return "", "", errors.Wrap(err, "Cannot locate home directory. Please set $NOTES_CLI_HOME")
+	}
+
+	if env := os.Getenv("NOTES_CLI_HOME"); env != "" {
+		return filepath.Clean(expandTilde(env, u.HomeDir)), "$NOTES_CLI_HOME", nil
+	}
+
+	if h, src := files.lookup(func(f *configFile) string { return f.Home }); h != "" {
+		return filepath.Clean(expandTilde(filepath.FromSlash(h), u.HomeDir)), src, nil
 	}
 
 	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
-		return filepath.Join(xdg, "notes-cli"), nil
+		return filepath.Join(xdg, "notes-cli"), "$XDG_DATA_HOME", nil
 	}
 
 	if runtime.GOOS == "windows" {
 		if env := os.Getenv("APPLOCALDATA"); env != "" {
-			return filepath.Join(env, "notes-cli"), nil
+			return filepath.Join(env, "notes-cli"), "$APPLOCALDATA", nil
 		}
 	}
 
-	return filepath.Join(u.HomeDir, ".local", "share", "notes-cli"), nil
+	return filepath.Join(u.HomeDir, ".local", "share", "notes-cli"), "default", nil
 }
 
-func gitPath() string {
-	c := "git"
+func gitPath(files configFiles) (string, string) {
+	c, src := "git", "default"
 	if env, ok := os.LookupEnv("NOTES_CLI_GIT"); ok {
-		c = filepath.Clean(env)
+		c, src = filepath.Clean(env), "$NOTES_CLI_GIT"
+	} else if v, p := files.lookup(func(f *configFile) string { return f.Git }); v != "" {
+		c, src = v, p
 	}
 
 	exe, err := exec.LookPath(c)
 	if err != nil {
 		// Git is optional
-		return ""
+		return "", src
 	}
 
-	return exe
+	return exe, src
 }
 
-func editorCmd() string {
+func editorCmd(files configFiles) (string, string) {
 	if env, ok := os.LookupEnv("NOTES_CLI_EDITOR"); ok {
-		return env
+		return env, "$NOTES_CLI_EDITOR"
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Editor }); v != "" {
+		return v, p
 	}
 	if env, ok := os.LookupEnv("EDITOR"); ok {
-		return env
+		return env, "$EDITOR"
 	}
-	return ""
+	return "", "default"
 }
 
-func pagerCmd() string {
+func pagerCmd(files configFiles) (string, string) {
 	if env, ok := os.LookupEnv("NOTES_CLI_PAGER"); ok {
-		return env
+		return env, "$NOTES_CLI_PAGER"
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Pager }); v != "" {
+		return v, p
 	}
 	if env, ok := os.LookupEnv("PAGER"); ok {
-		return env
+		return env, "$PAGER"
 	}
 	if _, err := exec.LookPath("less"); err == nil {
-		return "less -R -F -X"
+		return "less -R -F -X", "default"
+	}
+	return "", "default"
+}
+
+// boolValue converts a getter of optional boolean value in config file into a getter for lookup
+func boolValue(get func(f *configFile) *bool) func(f *configFile) string {
+	return func(f *configFile) string {
+		b := get(f)
+		if b == nil {
+			return ""
+		}
+		return strconv.FormatBool(*b)
+	}
+}
+
+func loadConfigFileValues(c *Config, files configFiles) {
+	if v, p := files.lookup(func(f *configFile) string { return f.Color }); v != "" {
+		c.Color = v
+		c.Sources["color"] = p
+	}
+	if v, p := files.lookup(boolValue(func(f *configFile) *bool { return f.GitAttributes })); v != "" {
+		c.GitAttributes = v == "true"
+		c.Sources["gitattributes"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.List.Sort }); v != "" {
+		c.List.SortBy = v
+		c.Sources["list.sort"] = p
+	}
+	if v, p := files.lookup(boolValue(func(f *configFile) *bool { return f.List.Oneline })); v != "" {
+		c.List.Oneline = v == "true"
+		c.Sources["list.oneline"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Save.MessageTemplate }); v != "" {
+		c.Save.MessageTemplate = v
+		c.Sources["save.message_template"] = p
+	}
+	if v, p := files.lookup(boolValue(func(f *configFile) *bool { return f.Save.Auto })); v != "" {
+		c.Save.Auto = v == "true"
+		c.Sources["save.auto"] = p
+	}
+	if v, p := files.lookup(boolValue(func(f *configFile) *bool { return f.Save.AutoPush })); v != "" {
+		c.Save.AutoPush = v == "true"
+		c.Sources["save.auto_push"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Save.Remote }); v != "" {
+		c.Save.Remote = v
+		c.Sources["save.remote"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Save.Branch }); v != "" {
+		c.Save.Branch = v
+		c.Sources["save.branch"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Sync.Remote }); v != "" {
+		c.Sync.Remote = v
+		c.Sources["sync.remote"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Sync.Branch }); v != "" {
+		c.Sync.Branch = v
+		c.Sources["sync.branch"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Sync.Strategy }); v != "" {
+		c.Sync.Strategy = v
+		c.Sources["sync.strategy"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Export.Category }); v != "" {
+		c.Export.Category = v
+		c.Sources["export.category"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Export.Tag }); v != "" {
+		c.Export.Tag = v
+		c.Sources["export.tag"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Export.ExcludeTag }); v != "" {
+		c.Export.ExcludeTag = v
+		c.Sources["export.exclude_tag"] = p
+	}
+	if v, p := files.lookup(func(f *configFile) string { return f.Watch.Command }); v != "" {
+		c.Watch.Command = v
+		c.Sources["watch.command"] = p
+	}
+	if v, p := files.lookup(boolValue(func(f *configFile) *bool { return f.Watch.Save })); v != "" {
+		c.Watch.Save = v == "true"
+		c.Sources["watch.save"] = p
 	}
-	return ""
 }
 
-// NewConfig creates a new Config instance by looking the user's environment. GitPath and EditorPath
-// may be empty when proper configuration is not found. When home directory path cannot be located,
-// this function returns an error
+// NewConfig creates a new Config instance by looking the user's environment and config files. GitPath
+// and EditorPath may be empty when proper configuration is not found. When home directory path cannot
+// be located or config file is broken, this function returns an error
 func NewConfig() (*Config, error) {
-	h, err := homePath()
+	return newConfig("")
+}
+
+// NewNotebookConfig creates a new Config instance as NewConfig does. But its home is a home directory of
+// given notebook. When the notebook is not configured in [notebooks] section of config file, this function
+// returns an error
+func NewNotebookConfig(notebook string) (*Config, error) {
+	return newConfig(notebook)
+}
+
+// ForNotebook returns a copy of the config whose home is the given notebook's home. Note that config
+// file in the notebook's home is not loaded. When the notebook is not configured, it returns false
+// as 2nd return value
+func (c *Config) ForNotebook(name string) (*Config, bool) {
+	h, ok := c.Notebooks[name]
+	if !ok {
+		return nil, false
+	}
+	copied := *c
+	copied.HomePath = h
+	copied.Notebook = name
+	return &copied, true
+}
+
+func newConfig(notebook string) (*Config, error) {
+	files := configFiles{}
+
+	p, err := userConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+	uf, err := loadConfigFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if uf != nil {
+		files = append(files, uf)
+	}
+
+	notebooks, err := notebookPaths(files)
+	if err != nil {
+		return nil, err
+	}
+
+	h, hsrc, err := homePath(files, notebook, notebooks)
 	if err != nil {
 		return nil, err
 	}
@@ -107,5 +368,50 @@ func NewConfig() (*Config, error) {
 		return nil, errors.Wrapf(err, "Could not create home '%s'", h)
 	}
 
-	return &Config{h, gitPath(), editorCmd(), pagerCmd()}, nil
+	hf, err := loadConfigFile(homeConfigFilePath(h))
+	if err != nil {
+		return nil, err
+	}
+	if hf != nil {
+		if hf.Home != "" || len(hf.Notebooks) > 0 {
+			return nil, errors.Errorf("'home' and 'notebooks' cannot be configured in config file in home '%s'. Please configure them in '%s' instead", canonPath(hf.path), canonPath(p))
+		}
+		// Config file in home is prioritized over user-wide config file
+		files = append(configFiles{hf}, files...)
+	}
+
+	c := &Config{HomePath: h, Notebooks: notebooks, Notebook: notebook, Sources: map[string]string{"home": hsrc}}
+	c.Sources["notebook"] = "--notebook"
+	if notebook == "" {
+		c.Sources["notebook"] = "home"
+		for n, p := range notebooks {
+			if p == h {
+				c.Notebook = n
+				break
+			}
+		}
+	}
+	c.GitPath, c.Sources["git"] = gitPath(files)
+	c.EditorCmd, c.Sources["editor"] = editorCmd(files)
+	c.PagerCmd, c.Sources["pager"] = pagerCmd(files)
+	c.Sources["color"] = "default"
+	c.Sources["gitattributes"] = "default"
+	c.Sources["list.sort"] = "default"
+	c.Sources["list.oneline"] = "default"
+	c.Sources["save.message_template"] = "default"
+	c.Sources["save.auto"] = "default"
+	c.Sources["save.auto_push"] = "default"
+	c.Sources["save.remote"] = "default"
+	c.Sources["save.branch"] = "default"
+	c.Sources["sync.remote"] = "default"
+	c.Sources["sync.branch"] = "default"
+	c.Sources["sync.strategy"] = "default"
+	c.Sources["export.category"] = "default"
+	c.Sources["export.tag"] = "default"
+	c.Sources["export.exclude_tag"] = "default"
+	c.Sources["watch.command"] = "default"
+	c.Sources["watch.save"] = "default"
+	loadConfigFileValues(c, files)
+
+	return c, nil
 }
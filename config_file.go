@@ -0,0 +1,344 @@
+package notes
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile represents contents of a config file written in TOML or YAML. Empty values mean that they
+// are not configured in the file
+type configFile struct {
+	path          string
+	Home          string               `toml:"home,omitempty" yaml:"home,omitempty"`
+	Git           string               `toml:"git,omitempty" yaml:"git,omitempty"`
+	Editor        string               `toml:"editor,omitempty" yaml:"editor,omitempty"`
+	Pager         string               `toml:"pager,omitempty" yaml:"pager,omitempty"`
+	Color         string               `toml:"color,omitempty" yaml:"color,omitempty"`
+	GitAttributes *bool                `toml:"gitattributes,omitempty" yaml:"gitattributes,omitempty"`
+	List          configFileListSect   `toml:"list,omitempty" yaml:"list,omitempty"`
+	Save          configFileSaveSect   `toml:"save,omitempty" yaml:"save,omitempty"`
+	Sync          configFileSyncSect   `toml:"sync,omitempty" yaml:"sync,omitempty"`
+	Export        configFileExportSect `toml:"export,omitempty" yaml:"export,omitempty"`
+	Watch         configFileWatchSect  `toml:"watch,omitempty" yaml:"watch,omitempty"`
+	// Notebooks is a map from notebook name to its home directory
+	Notebooks map[string]string `toml:"notebooks,omitempty" yaml:"notebooks,omitempty"`
+}
+
+// configFileListSect represents [list] section of config file which configures default values of
+// options of `notes list`
+type configFileListSect struct {
+	Sort    string `toml:"sort,omitempty" yaml:"sort,omitempty"`
+	Oneline *bool  `toml:"oneline,omitempty" yaml:"oneline,omitempty"`
+}
+
+func (f *configFile) validate() error {
+	switch f.Color {
+	case "", "always", "never", "auto":
+	default:
+		return errors.Errorf("'color' must be one of 'always', 'never' or 'auto' but got '%s' in config file '%s'", f.Color, canonPath(f.path))
+	}
+	switch f.List.Sort {
+	case "", "modified", "created", "filename", "category":
+	default:
+		return errors.Errorf("'list.sort' must be one of 'modified', 'created', 'filename' or 'category' but got '%s' in config file '%s'", f.List.Sort, canonPath(f.path))
+	}
+	if f.Save.MessageTemplate != "" {
+		if _, err := parseSaveMessageTemplate(f.Save.MessageTemplate); err != nil {
+			return errors.Wrapf(err, "Invalid 'save.message_template' in config file '%s'", canonPath(f.path))
+		}
+	}
+	switch f.Sync.Strategy {
+	case "", "rebase", "merge":
+	default:
+		return errors.Errorf("'sync.strategy' must be one of 'rebase' or 'merge' but got '%s' in config file '%s'", f.Sync.Strategy, canonPath(f.path))
+	}
+	for _, kv := range [][2]string{
+		{"export.category", f.Export.Category},
+		{"export.tag", f.Export.Tag},
+		{"export.exclude_tag", f.Export.ExcludeTag},
+	} {
+		if _, err := regexp.Compile(kv[1]); err != nil {
+			return errors.Wrapf(err, "Invalid regular expression of '%s' in config file '%s'", kv[0], canonPath(f.path))
+		}
+	}
+	for name, path := range f.Notebooks {
+		if err := validateDirname(name); err != nil {
+			return errors.Wrapf(err, "Invalid notebook name '%s' in config file '%s'", name, canonPath(f.path))
+		}
+		if path == "" {
+			return errors.Errorf("Home directory of notebook '%s' is empty in config file '%s'", name, canonPath(f.path))
+		}
+	}
+	return nil
+}
+
+// configFileSaveSect represents [save] section of config file which configures `notes save`
+type configFileSaveSect struct {
+	MessageTemplate string `toml:"message_template,omitempty" yaml:"message_template,omitempty"`
+	Auto            *bool  `toml:"auto,omitempty" yaml:"auto,omitempty"`
+	AutoPush        *bool  `toml:"auto_push,omitempty" yaml:"auto_push,omitempty"`
+	Remote          string `toml:"remote,omitempty" yaml:"remote,omitempty"`
+	Branch          string `toml:"branch,omitempty" yaml:"branch,omitempty"`
+}
+
+// configFileSyncSect represents [sync] section of config file which configures `notes sync`
+type configFileSyncSect struct {
+	Remote   string `toml:"remote,omitempty" yaml:"remote,omitempty"`
+	Branch   string `toml:"branch,omitempty" yaml:"branch,omitempty"`
+	Strategy string `toml:"strategy,omitempty" yaml:"strategy,omitempty"`
+}
+
+// configFileExportSect represents [export] section of config file which configures rules to publish
+// notes with `notes export hugo` and `notes export jekyll`
+type configFileExportSect struct {
+	Category   string `toml:"category,omitempty" yaml:"category,omitempty"`
+	Tag        string `toml:"tag,omitempty" yaml:"tag,omitempty"`
+	ExcludeTag string `toml:"exclude_tag,omitempty" yaml:"exclude_tag,omitempty"`
+}
+
+// configFileWatchSect represents [watch] section of config file which configures `notes watch`
+type configFileWatchSect struct {
+	Command string `toml:"command,omitempty" yaml:"command,omitempty"`
+	Save    *bool  `toml:"save,omitempty" yaml:"save,omitempty"`
+}
+
+// isYAMLConfigFile returns true when the config file at given path is written in YAML. Otherwise it is
+// written in TOML
+func isYAMLConfigFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// loadConfigFile loads config file at given path. When the file does not exist, it returns nil
+// without an error since all config files are optional
+func loadConfigFile(path string) (*configFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "Cannot read config file '%s'", canonPath(path))
+	}
+
+	f := &configFile{path: path}
+	if isYAMLConfigFile(path) {
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		// Empty YAML file is decoded as io.EOF
+		if err := dec.Decode(f); err != nil && err != io.EOF {
+			return nil, errors.Wrapf(err, "Cannot parse config file '%s' as YAML", canonPath(path))
+		}
+	} else {
+		md, err := toml.Decode(string(b), f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot parse config file '%s' as TOML", canonPath(path))
+		}
+
+		if keys := md.Undecoded(); len(keys) > 0 {
+			ss := make([]string, 0, len(keys))
+			for _, k := range keys {
+				ss = append(ss, k.String())
+			}
+			sort.Strings(ss)
+			return nil, errors.Errorf("Unknown keys in config file '%s': %s", canonPath(path), strings.Join(ss, ", "))
+		}
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// save writes the config to the file in TOML or YAML depending on its file extension. When the file or
+// its parent directory does not exist, they are created. Note that comments in the file are not preserved
+func (f *configFile) save() error {
+	var b bytes.Buffer
+	if isYAMLConfigFile(f.path) {
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err := enc.Encode(f); err != nil {
+			return errors.Wrap(err, "Cannot encode config as YAML")
+		}
+		if err := enc.Close(); err != nil {
+			return errors.Wrap(err, "Cannot encode config as YAML")
+		}
+	} else {
+		enc := toml.NewEncoder(&b)
+		enc.Indent = ""
+		if err := enc.Encode(f); err != nil {
+			return errors.Wrap(err, "Cannot encode config as TOML")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return errors.Wrapf(err, "Cannot create directory for config file '%s'", canonPath(f.path))
+	}
+
+	if err := os.WriteFile(f.path, b.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "Cannot write config file '%s'", canonPath(f.path))
+	}
+
+	return nil
+}
+
+// set sets given value to the key. Empty value means removing the key from config file
+func (f *configFile) set(key, val string) error {
+	switch key {
+	case "home":
+		f.Home = val
+	case "git":
+		f.Git = val
+	case "editor":
+		f.Editor = val
+	case "pager":
+		f.Pager = val
+	case "color":
+		f.Color = val
+	case "gitattributes":
+		b, err := parseBoolConfig(key, val)
+		if err != nil {
+			return err
+		}
+		f.GitAttributes = b
+	case "list.sort":
+		f.List.Sort = val
+	case "list.oneline":
+		b, err := parseBoolConfig(key, val)
+		if err != nil {
+			return err
+		}
+		f.List.Oneline = b
+	case "save.message_template":
+		f.Save.MessageTemplate = val
+	case "save.auto":
+		b, err := parseBoolConfig(key, val)
+		if err != nil {
+			return err
+		}
+		f.Save.Auto = b
+	case "save.auto_push":
+		b, err := parseBoolConfig(key, val)
+		if err != nil {
+			return err
+		}
+		f.Save.AutoPush = b
+	case "save.remote":
+		f.Save.Remote = val
+	case "save.branch":
+		f.Save.Branch = val
+	case "sync.remote":
+		f.Sync.Remote = val
+	case "sync.branch":
+		f.Sync.Branch = val
+	case "sync.strategy":
+		f.Sync.Strategy = val
+	case "export.category":
+		f.Export.Category = val
+	case "export.tag":
+		f.Export.Tag = val
+	case "export.exclude_tag":
+		f.Export.ExcludeTag = val
+	case "watch.command":
+		f.Watch.Command = val
+	case "watch.save":
+		b, err := parseBoolConfig(key, val)
+		if err != nil {
+			return err
+		}
+		f.Watch.Save = b
+	default:
+		if !strings.HasPrefix(key, "notebooks.") {
+			return errors.Errorf("Unknown config key '%s'. Available keys are %s", key, strings.Join(configFileKeys, ", "))
+		}
+		name := strings.TrimPrefix(key, "notebooks.")
+		if val == "" {
+			delete(f.Notebooks, name)
+			break
+		}
+		if f.Notebooks == nil {
+			f.Notebooks = map[string]string{}
+		}
+		f.Notebooks[name] = val
+	}
+	return f.validate()
+}
+
+// parseBoolConfig parses a boolean value of the key. Empty value means removing the key
+func parseBoolConfig(key, val string) (*bool, error) {
+	if val == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, errors.Errorf("'%s' must be boolean value but got '%s'", key, val)
+	}
+	return &b, nil
+}
+
+// configFileKeys is a list of all keys which can be configured in config file
+var configFileKeys = []string{"home", "git", "editor", "pager", "color", "gitattributes", "list.sort", "list.oneline", "save.message_template", "save.auto", "save.auto_push", "save.remote", "save.branch", "sync.remote", "sync.branch", "sync.strategy", "export.category", "export.tag", "export.exclude_tag", "watch.command", "watch.save", "notebooks.<name>"}
+
+// findConfigFile returns a path to the config file whose path without file extension is base. Extensions
+// '.toml', '.yaml' and '.yml' are looked up in this order. When none of them exists, a path to TOML file
+// is returned
+func findConfigFile(base string) string {
+	for _, ext := range []string{".toml", ".yaml", ".yml"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return base + ".toml"
+}
+
+// userConfigFilePath returns a path to user-wide config file. $XDG_CONFIG_HOME is respected
+func userConfigFilePath() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return findConfigFile(filepath.Join(xdg, "notes-cli", "config")), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		if env := os.Getenv("APPDATA"); env != "" {
+			return findConfigFile(filepath.Join(env, "notes-cli", "config")), nil
+		}
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot locate home directory for config file. Please set $XDG_CONFIG_HOME")
+	}
+
+	return findConfigFile(filepath.Join(u.HomeDir, ".config", "notes-cli", "config")), nil
+}
+
+// homeConfigFilePath returns a path to config file put in home of notes
+func homeConfigFilePath(home string) string {
+	return findConfigFile(filepath.Join(home, ".notes"))
+}
+
+// configFiles is a list of loaded config files. Prior file comes first
+type configFiles []*configFile
+
+// lookup looks up a value from config files. It returns the value and a path to the file where the
+// value was found. When the value is not found in any file, it returns empty strings
+func (fs configFiles) lookup(get func(f *configFile) string) (string, string) {
+	for _, f := range fs {
+		if v := get(f); v != "" {
+			return v, f.path
+		}
+	}
+	return "", ""
+}
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -22,8 +23,12 @@ func testNewConfigEnvGuard() *tmpenv.Envguard {
 		"NOTES_CLI_PAGER",
 		"EDITOR",
 		"PAGER",
+		"APPDATA",
 	)
 	panicIfErr(err)
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	panicIfErr(g.Setenv("XDG_CONFIG_HOME", testNoConfigFileDir(cwd)))
 	return g
 }
 
@@ -260,3 +265,231 @@ func TestNewConfigExpandTilde(t *testing.T) {
 		t.Fatal("'~' was not expanded collectly. Wanted", cwd, "but got", c.HomePath)
 	}
 }
+
+func TestNewConfigFromConfigFile(t *testing.T) {
+	for _, tc := range []struct {
+		xdg  string
+		file string
+	}{
+		{"xdg", "config.toml"},
+		{"yaml", "config.yaml"},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			testNewConfigFromConfigFile(t, tc.xdg, tc.file)
+		})
+	}
+}
+
+func testNewConfigFromConfigFile(t *testing.T, dir, name string) {
+	g := testNewConfigEnvGuard()
+	defer func() { panicIfErr(g.Restore()) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	xdg := filepath.Join(cwd, "testdata", "config", dir)
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", xdg))
+	file := filepath.Join(xdg, "notes-cli", name)
+
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { panicIfErr(os.RemoveAll("test-config-file-home")) }()
+
+	if c.HomePath != "test-config-file-home" {
+		t.Fatal("Home is unexpected:", c.HomePath)
+	}
+	if c.EditorCmd != "vim" {
+		t.Fatal("Editor is unexpected:", c.EditorCmd)
+	}
+	if c.PagerCmd != "more" {
+		t.Fatal("Pager is unexpected:", c.PagerCmd)
+	}
+	if c.Color != "never" {
+		t.Fatal("Color is unexpected:", c.Color)
+	}
+	if c.List.SortBy != "modified" || !c.List.Oneline {
+		t.Fatal("Defaults of list command are unexpected:", c.List)
+	}
+	if !c.Save.Auto || c.Save.AutoPush {
+		t.Fatal("Config of save command is unexpected:", c.Save)
+	}
+	if c.Sync.Strategy != "merge" {
+		t.Fatal("Config of sync command is unexpected:", c.Sync)
+	}
+	if c.Export.ExcludeTag != "^draft$" || c.Export.Category != "" {
+		t.Fatal("Config of export is unexpected:", c.Export)
+	}
+	if c.Watch.Command != "make" || !c.Watch.Save {
+		t.Fatal("Config of watch command is unexpected:", c.Watch)
+	}
+
+	for _, k := range []string{"home", "editor", "pager", "color", "list.sort", "list.oneline", "save.auto", "sync.strategy", "export.exclude_tag", "watch.command", "watch.save"} {
+		if c.Sources[k] != file {
+			t.Error("Source of", k, "should be", file, "but got", c.Sources[k])
+		}
+	}
+	if c.Sources["git"] != "default" {
+		t.Error("Source of git is unexpected:", c.Sources["git"])
+	}
+}
+
+func TestNewConfigEnvIsPrioritizedOverConfigFile(t *testing.T) {
+	g := testNewConfigEnvGuard()
+	defer func() { panicIfErr(g.Restore()) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	home := filepath.Join(cwd, "testdata", "config", "home-with-file")
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", filepath.Join(cwd, "testdata", "config", "xdg")))
+	panicIfErr(os.Setenv("NOTES_CLI_HOME", home))
+	panicIfErr(os.Setenv("NOTES_CLI_PAGER", "less"))
+
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.HomePath != home || c.Sources["home"] != "$NOTES_CLI_HOME" {
+		t.Fatal("Home is unexpected:", c.HomePath, c.Sources["home"])
+	}
+	if c.PagerCmd != "less" || c.Sources["pager"] != "$NOTES_CLI_PAGER" {
+		t.Fatal("Pager is unexpected:", c.PagerCmd, c.Sources["pager"])
+	}
+
+	// Config file in home is prioritized over user-wide config file
+	hf := filepath.Join(home, ".notes.toml")
+	if c.EditorCmd != "nano" || c.Sources["editor"] != hf {
+		t.Fatal("Editor is unexpected:", c.EditorCmd, c.Sources["editor"])
+	}
+	if c.List.SortBy != "filename" || c.Sources["list.sort"] != hf {
+		t.Fatal("Sort order is unexpected:", c.List.SortBy, c.Sources["list.sort"])
+	}
+
+	// Values not configured in home are looked up from user-wide config file
+	if c.Color != "never" || !c.List.Oneline {
+		t.Fatal("Values in user-wide config file were not loaded:", c.Color, c.List)
+	}
+
+	panicIfErr(os.Setenv("NOTES_CLI_EDITOR", "emacs"))
+	c, err = NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.EditorCmd != "emacs" || c.Sources["editor"] != "$NOTES_CLI_EDITOR" {
+		t.Fatal("Editor is unexpected:", c.EditorCmd, c.Sources["editor"])
+	}
+}
+
+func TestNewConfigBrokenConfigFile(t *testing.T) {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+
+	for _, tc := range []struct {
+		what string
+		xdg  string
+		home string
+		want string
+	}{
+		{
+			what: "broken TOML",
+			xdg:  "broken",
+			want: "Cannot parse config file",
+		},
+		{
+			what: "unknown keys",
+			xdg:  "unknown-key",
+			want: "Unknown keys in config file",
+		},
+		{
+			what: "broken YAML",
+			xdg:  "broken-yaml",
+			want: "Cannot parse config file",
+		},
+		{
+			what: "unknown keys in YAML",
+			xdg:  "unknown-key-yaml",
+			want: "field unknown not found",
+		},
+		{
+			what: "invalid value",
+			xdg:  "invalid-value",
+			want: "'list.sort' must be one of",
+		},
+		{
+			what: "home in home",
+			home: "home-in-home",
+			want: "'home' and 'notebooks' cannot be configured in config file in home",
+		},
+	} {
+		t.Run(tc.what, func(t *testing.T) {
+			g := testNewConfigEnvGuard()
+			defer func() { panicIfErr(g.Restore()) }()
+
+			if tc.xdg != "" {
+				panicIfErr(os.Setenv("XDG_CONFIG_HOME", filepath.Join(cwd, "testdata", "config", tc.xdg)))
+			}
+			if tc.home != "" {
+				panicIfErr(os.Setenv("NOTES_CLI_HOME", filepath.Join(cwd, "testdata", "config", tc.home)))
+			}
+
+			_, err := NewConfig()
+			if err == nil {
+				t.Fatal("Error did not occur")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestNewNotebookConfig(t *testing.T) {
+	g := testNewConfigEnvGuard()
+	defer func() { panicIfErr(g.Restore()) }()
+
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	panicIfErr(os.Setenv("XDG_CONFIG_HOME", filepath.Join(cwd, "testdata", "config", "notebooks")))
+	panicIfErr(os.Setenv("NOTES_CLI_HOME", filepath.Join("testdata", "list", "normal")))
+
+	c, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Notebook != "normal" {
+		t.Fatal("Notebook should be detected from home:", c.Notebook)
+	}
+	want := map[string]string{
+		"normal": filepath.Join("testdata", "list", "normal"),
+		"nested": filepath.Join("testdata", "list", "nested"),
+	}
+	if !reflect.DeepEqual(c.Notebooks, want) {
+		t.Fatal("Notebooks are unexpected:", c.Notebooks)
+	}
+
+	nested, ok := c.ForNotebook("nested")
+	if !ok {
+		t.Fatal("Notebook 'nested' was not found")
+	}
+	if nested.HomePath != want["nested"] || nested.Notebook != "nested" || c.Notebook != "normal" {
+		t.Fatal("Config was not copied for notebook:", nested.HomePath, nested.Notebook, c.Notebook)
+	}
+	if _, ok := c.ForNotebook("unknown"); ok {
+		t.Fatal("Unknown notebook was found")
+	}
+
+	// --notebook is prioritized over $NOTES_CLI_HOME
+	c, err = NewNotebookConfig("nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HomePath != want["nested"] || c.Notebook != "nested" || c.Sources["home"] != "--notebook" {
+		t.Fatal("Home is not notebook's home:", c.HomePath, c.Notebook, c.Sources["home"])
+	}
+
+	_, err = NewNotebookConfig("unknown")
+	if err == nil || !strings.Contains(err.Error(), "Notebook 'unknown' is not configured. Configured notebooks are [nested, normal]") {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 )
 
 // Note: External command name must consist of alphabets, numbers, dash '-' and underscore '_'.
@@ -21,6 +23,30 @@ type ExternalCmd struct {
 	Args []string
 	// NotesPath is an executable path of the `notes` command. This is passed to the first argument of external subcommand
 	NotesPath string
+	// Env is environment variables added to the external subcommand's environment
+	Env []string
+}
+
+// externalCmdEnv returns environment variables to tell notebooks to external subcommands.
+// $NOTES_CLI_HOME is set to the current home so that `notes` run by the subcommand uses the same
+// notebook. $NOTES_CLI_NOTEBOOKS contains homes of all notebooks separated with newlines
+func externalCmdEnv(c *Config) []string {
+	names := make([]string, 0, len(c.Notebooks))
+	for n := range c.Notebooks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	homes := make([]string, 0, len(names))
+	for _, n := range names {
+		homes = append(homes, c.Notebooks[n])
+	}
+
+	return []string{
+		"NOTES_CLI_HOME=" + c.HomePath,
+		"NOTES_CLI_NOTEBOOK=" + c.Notebook,
+		"NOTES_CLI_NOTEBOOKS=" + strings.Join(homes, "\n"),
+	}
 }
 
 // Do invokes external subcommand with exec. If it did not exit successfully this function returns an error
@@ -30,6 +56,9 @@ func (cmd *ExternalCmd) Do() error {
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	c.Stdin = os.Stdin
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
+	}
 	if err := c.Run(); err != nil {
 		name := filepath.Base(cmd.ExePath)
 		return errors.Wrapf(err, "External command '%s' did not exit successfully", name)
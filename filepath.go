@@ -36,3 +36,33 @@ func validateDirname(name string) error {
 	}
 	return nil
 }
+
+// noteRelPath resolves a note specified in command arguments to a slash-separated relative path from
+// home. The note can be specified with an absolute path or a relative path from home such as
+// "category/file.md". ".md" extension can be omitted. Note that the file may not exist
+func noteRelPath(home, note string) (string, error) {
+	rel := filepath.Clean(note)
+	if filepath.IsAbs(rel) {
+		r, err := filepath.Rel(home, rel)
+		if err != nil {
+			return "", errors.Wrapf(err, "Cannot resolve note '%s'", note)
+		}
+		rel = r
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("Note '%s' is not in home '%s'. Please specify it with 'category/file.md'", note, canonPath(home))
+	}
+	if !strings.HasSuffix(rel, ".md") {
+		rel += ".md"
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// isInDir returns true when the path is put under the directory. Both paths should be absolute
+func isInDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -3,6 +3,7 @@ package notes
 import (
 	"os/user"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -78,3 +79,39 @@ func TestValidateDirnameInvalid(t *testing.T) {
 		})
 	}
 }
+
+func TestNoteRelPath(t *testing.T) {
+	home := filepath.FromSlash("/path/to/home")
+	if runtime.GOOS == "windows" {
+		home = `C:\path\to\home`
+	}
+
+	for _, tc := range []struct {
+		note string
+		want string
+	}{
+		{"memo/foo.md", "memo/foo.md"},
+		{"memo/foo", "memo/foo.md"},
+		{"blog/tech/foo.md", "blog/tech/foo.md"},
+		{"./memo/../memo/foo.md", "memo/foo.md"},
+		{filepath.Join(home, "memo", "foo.md"), "memo/foo.md"},
+	} {
+		t.Run(tc.note, func(t *testing.T) {
+			have, err := noteRelPath(home, tc.note)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have != tc.want {
+				t.Fatalf("Wanted '%s' but have '%s'", tc.want, have)
+			}
+		})
+	}
+
+	for _, note := range []string{"..", "../foo.md", ".", filepath.Join(filepath.Dir(home), "foo.md")} {
+		t.Run("error "+note, func(t *testing.T) {
+			if _, err := noteRelPath(home, note); err == nil || !strings.Contains(err.Error(), "is not in home") {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
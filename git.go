@@ -6,12 +6,37 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Git represents Git command for specific repository
 type Git struct {
 	bin  string
 	root string
+	// attributes is true when .gitattributes should be set up in addition to .gitignore
+	attributes bool
+}
+
+// defaultGitignore is a list of patterns written to .gitignore in home. Files created by notes command
+// for internal use, swap files of editors and junk files of OS are ignored
+var defaultGitignore = []string{
+	".trash/",
+	".notes-index",
+	"*.swp",
+	"*.swo",
+	"*~",
+	".*.un~",
+	"4913",
+	".#*",
+	".DS_Store",
+	"Thumbs.db",
+	"desktop.ini",
+}
+
+// defaultGitattributes is a list of entries written to .gitattributes in home. Git's builtin diff
+// driver for Markdown shows headings in hunk headers
+var defaultGitattributes = []string{
+	"*.md diff=markdown",
 }
 
 func (git *Git) canonRoot() string {
@@ -59,7 +84,76 @@ func (git *Git) Init() error {
 	if err != nil {
 		return errors.Wrapf(err, "Cannot init Git repository at '%s': %s", git.canonRoot(), out)
 	}
-	return nil
+
+	_, err = git.Setup()
+	return err
+}
+
+// appendMissingLines appends lines which are not contained in the file yet. When the file does not exist,
+// it is created. It returns true when the file was modified
+func appendMissingLines(path string, lines []string) (bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.Wrapf(err, "Cannot read '%s'", canonPath(path))
+	}
+
+	exists := map[string]struct{}{}
+	for _, l := range strings.Split(string(b), "\n") {
+		exists[strings.TrimSpace(l)] = struct{}{}
+	}
+
+	var buf strings.Builder
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		buf.WriteRune('\n')
+	}
+	added := false
+	for _, l := range lines {
+		if _, ok := exists[l]; ok {
+			continue
+		}
+		buf.WriteString(l + "\n")
+		added = true
+	}
+	if !added {
+		return false, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, errors.Wrapf(err, "Cannot open '%s'", canonPath(path))
+	}
+	defer f.Close()
+	if _, err := f.WriteString(buf.String()); err != nil {
+		return false, errors.Wrapf(err, "Cannot write to '%s'", canonPath(path))
+	}
+	return true, nil
+}
+
+// Setup writes default .gitignore to the repository. When 'gitattributes' config is enabled,
+// .gitattributes is also written. Entries already existing in the files are kept and only missing
+// entries are appended. It returns paths to the files which were modified
+func (git *Git) Setup() ([]string, error) {
+	type file struct {
+		name  string
+		lines []string
+	}
+	files := []file{{".gitignore", defaultGitignore}}
+	if git.attributes {
+		files = append(files, file{".gitattributes", defaultGitattributes})
+	}
+
+	updated := []string{}
+	for _, f := range files {
+		p := filepath.Join(git.root, f.name)
+		modified, err := appendMissingLines(p, f.lines)
+		if err != nil {
+			return nil, err
+		}
+		if modified {
+			updated = append(updated, p)
+		}
+	}
+	return updated, nil
 }
 
 // AddAll runs `git add -A`
@@ -71,40 +165,346 @@ func (git *Git) AddAll() error {
 	return nil
 }
 
-// Commit runs `git commit` with given message
-func (git *Git) Commit(msg string) error {
-	out, err := git.Exec("commit", "-m", msg)
+// Commit runs `git commit` with given message. When paths are given, only changes of the paths are
+// committed even if other changes are staged
+func (git *Git) Commit(msg string, paths ...string) error {
+	args := []string{"-m", msg}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
+	}
+	out, err := git.Exec("commit", args...)
 	if err != nil {
 		return errors.Wrapf(err, "Cannot commit changes to repository at '%s': %s", git.canonRoot(), out)
 	}
 	return nil
 }
 
-// TrackingRemote returns remote name branch name. It fails when current branch does not track any branch
+// GitChange represents a change of file which is staged in index tree
+type GitChange struct {
+	// Status is a status letter of the change. 'A' (added), 'M' (modified), 'D' (deleted), 'R' (renamed),
+	// 'C' (copied) or 'T' (type changed)
+	Status byte
+	// Path is a slash-separated relative path of the changed file from root of the repository
+	Path string
+	// OldPath is a slash-separated relative path of the file before renamed or copied. Otherwise it is empty
+	OldPath string
+}
+
+// StagedChanges returns changes of files staged in index tree. Renamed files are detected. When paths
+// are given, only changes of the paths are returned
+func (git *Git) StagedChanges(paths ...string) ([]*GitChange, error) {
+	args := append([]string{"--cached", "--name-status", "-M", "-z", "--"}, paths...)
+	out, err := git.Exec("diff", args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot retrieve staged changes at '%s': %s", git.canonRoot(), out)
+	}
+
+	// Output is NUL-separated like "M\x00path\x00R100\x00old\x00new\x00"
+	ss := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	changes := []*GitChange{}
+	for i := 0; i < len(ss); i++ {
+		if ss[i] == "" {
+			continue
+		}
+		c := &GitChange{Status: ss[i][0]}
+		if c.Status == 'R' || c.Status == 'C' {
+			if i+2 >= len(ss) {
+				return nil, errors.Errorf("Unexpected output of staged changes at '%s': %q", git.canonRoot(), out)
+			}
+			c.OldPath = ss[i+1]
+			c.Path = ss[i+2]
+			i += 2
+		} else {
+			if i+1 >= len(ss) {
+				return nil, errors.Errorf("Unexpected output of staged changes at '%s': %q", git.canonRoot(), out)
+			}
+			c.Path = ss[i+1]
+			i++
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, nil
+}
+
+// Changes returns changes of files in working tree which are not committed yet including untracked
+// files. Unlike StagedChanges, changes which are not staged are also included. Untracked files are
+// reported as added. When paths are given, only changes of the paths are returned
+func (git *Git) Changes(paths ...string) ([]*GitChange, error) {
+	args := append([]string{"--porcelain", "-z", "--untracked-files=all", "--"}, paths...)
+	out, err := git.Exec("status", args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot retrieve changes at '%s': %s", git.canonRoot(), out)
+	}
+
+	// Each entry is like "XY path\x00". Renamed entry is followed by original path like "R  new\x00old\x00"
+	ss := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	changes := []*GitChange{}
+	for i := 0; i < len(ss); i++ {
+		e := ss[i]
+		if len(e) < 4 {
+			continue
+		}
+		x, y := e[0], e[1]
+		c := &GitChange{Path: e[3:]}
+		switch {
+		case x == '?':
+			c.Status = 'A'
+		case x == 'R' || x == 'C':
+			if i+1 >= len(ss) {
+				return nil, errors.Errorf("Unexpected output of changes at '%s': %q", git.canonRoot(), out)
+			}
+			c.Status = x
+			c.OldPath = ss[i+1]
+			i++
+		case x == 'D' || y == 'D':
+			c.Status = 'D'
+		case x == 'A':
+			c.Status = 'A'
+		default:
+			c.Status = 'M'
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, nil
+}
+
+// TrackingRemote returns remote name and branch name of the remote which current branch tracks. Branch
+// names containing slashes like "feature/notes" are supported. It fails when current branch does not
+// track any branch
 func (git *Git) TrackingRemote() (string, string, error) {
-	s, err := git.Exec("rev-parse", "--abbrev-ref", "--symbolic", "@{u}")
+	ref, err := git.Exec("symbolic-ref", "HEAD")
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Cannot retrieve current branch at '%s': %s", git.canonRoot(), ref)
+	}
+
+	// e.g. "origin\x00refs/heads/feature/notes"
+	out, err := git.Exec("for-each-ref", "--format=%(upstream:remotename)%00%(upstream:remoteref)", ref)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Cannot retrieve remote name at '%s': %s", git.canonRoot(), out)
+	}
+
+	ss := strings.SplitN(out, "\x00", 2)
+	if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+		return "", "", errors.Errorf("Cannot retrieve remote name: No upstream is configured for branch '%s' at '%s'", strings.TrimPrefix(ref, "refs/heads/"), git.canonRoot())
+	}
+
+	return ss[0], strings.TrimPrefix(ss[1], "refs/heads/"), nil
+}
+
+// Add runs `git add` with given paths
+func (git *Git) Add(paths ...string) error {
+	args := append([]string{"--"}, paths...)
+	out, err := git.Exec("add", args...)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot add files to index tree at '%s': %s", git.canonRoot(), out)
+	}
+	return nil
+}
+
+// CurrentBranch returns a name of current branch. It fails when HEAD is detached
+func (git *Git) CurrentBranch() (string, error) {
+	out, err := git.Exec("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", errors.Wrapf(err, "Cannot retrieve current branch at '%s': %s", git.canonRoot(), out)
+	}
+	return out, nil
+}
+
+// HasCommit returns whether current branch has at least one commit
+func (git *Git) HasCommit() bool {
+	_, err := git.Exec("rev-parse", "--verify", "--quiet", "HEAD")
+	return err == nil
+}
+
+// RemoteBranchExists returns whether the branch exists in the remote. This accesses the remote
+func (git *Git) RemoteBranchExists(remote, branch string) (bool, error) {
+	out, err := git.Exec("ls-remote", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		return false, errors.Wrapf(err, "Cannot access to remote '%s' at '%s': %s", remote, git.canonRoot(), out)
+	}
+	return out != "", nil
+}
+
+// Fetch fetches given branch of the remote. Fetched revision can be referred as FETCH_HEAD
+func (git *Git) Fetch(remote, branch string) error {
+	out, err := git.Exec("fetch", remote, branch)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot fetch %s/%s at '%s': %s", remote, branch, git.canonRoot(), out)
+	}
+	return nil
+}
+
+// Rebase runs `git rebase` onto given revision
+func (git *Git) Rebase(rev string) error {
+	out, err := git.Exec("rebase", rev)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot rebase onto '%s' at '%s': %s", rev, git.canonRoot(), out)
+	}
+	return nil
+}
+
+// Merge runs `git merge` with given revision. Default message is used for merge commit
+func (git *Git) Merge(rev string) error {
+	out, err := git.Exec("merge", "--no-edit", rev)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot merge '%s' at '%s': %s", rev, git.canonRoot(), out)
+	}
+	return nil
+}
+
+// Continue runs `git rebase --continue` or `git merge --continue` without opening an editor. op must be
+// "rebase" or "merge"
+func (git *Git) Continue(op string) error {
+	c := git.Command(op, "--continue")
+	// Prevent Git from opening an editor to edit commit message
+	c.Env = append(os.Environ(), "GIT_EDITOR=true")
+	b, err := c.CombinedOutput()
+	if err != nil {
+		out := strings.ReplaceAll(strings.TrimSuffix(string(b), "\n"), "\n", " ")
+		return errors.Wrapf(err, "Cannot continue %s at '%s': %s", op, git.canonRoot(), out)
+	}
+	return nil
+}
+
+// InProgress returns "rebase" or "merge" when rebase or merge is in progress. Otherwise returns empty
+// string
+func (git *Git) InProgress() string {
+	dir := filepath.Join(git.root, ".git")
+	for _, d := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(dir, d)); err == nil {
+			return "rebase"
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "MERGE_HEAD")); err == nil {
+		return "merge"
+	}
+	return ""
+}
+
+// ConflictedFiles returns slash-separated relative paths of files which are unmerged due to conflicts
+func (git *Git) ConflictedFiles() ([]string, error) {
+	out, err := git.Exec("diff", "--name-only", "--diff-filter=U", "-z")
 	if err != nil {
-		return "", "", errors.Wrapf(err, "Cannot retrieve remote name: %s", s)
+		return nil, errors.Wrapf(err, "Cannot retrieve conflicted files at '%s': %s", git.canonRoot(), out)
 	}
-	// e.g. origin/master
-	ss := strings.Split(s, "/")
-	return ss[0], ss[1], nil
+	files := []string{}
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// GitCommit represents a commit in history of Git repository
+type GitCommit struct {
+	// Hash is an abbreviated commit hash
+	Hash string
+	// Date is a date when the commit was authored
+	Date time.Time
+	// Subject is the first line of commit message
+	Subject string
+}
+
+// Log returns commits which touched the file at given path in reverse chronological order. Renames
+// of the file are followed. path is a slash-separated relative path from root of the repository
+func (git *Git) Log(path string) ([]*GitCommit, error) {
+	out, err := git.Exec("log", "--follow", "--format=%h%x00%aI%x00%s", "--", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot retrieve history of '%s' at '%s': %s", path, git.canonRoot(), out)
+	}
+
+	commits := []*GitCommit{}
+	if out == "" {
+		return commits, nil
+	}
+	for _, l := range strings.Split(out, "\n") {
+		ss := strings.SplitN(l, "\x00", 3)
+		if len(ss) != 3 {
+			return nil, errors.Errorf("Unexpected output of history at '%s': %q", git.canonRoot(), l)
+		}
+		t, err := time.Parse(time.RFC3339, ss[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot parse date of commit %s", ss[0])
+		}
+		commits = append(commits, &GitCommit{ss[0], t, ss[2]})
+	}
+	return commits, nil
+}
+
+// Diff returns output of `git diff` for the file at given path. When rev is empty, changes from HEAD
+// are shown. When colorful is true, the output contains color sequences
+func (git *Git) Diff(path, rev string, colorful bool) (string, error) {
+	args := []string{"--color=never"}
+	if colorful {
+		args[0] = "--color=always"
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+	args = append(args, rev, "--", path)
+
+	b, err := git.Command("diff", args...).Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "Cannot show diff of '%s' from '%s' at '%s': %s", path, rev, git.canonRoot(), stderrOf(err))
+	}
+	return string(b), nil
 }
 
-// Push pushes given branch of repository to the given remote
+// FileAt returns content of the file at given path at the revision. path is a slash-separated relative
+// path from root of the repository
+func (git *Git) FileAt(path, rev string) ([]byte, error) {
+	b, err := git.Command("show", rev+":"+path).Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot retrieve '%s' at revision '%s' at '%s': %s", path, rev, git.canonRoot(), stderrOf(err))
+	}
+	return b, nil
+}
+
+func stderrOf(err error) string {
+	if e, ok := err.(*exec.ExitError); ok {
+		return strings.TrimSpace(strings.ReplaceAll(string(e.Stderr), "\n", " "))
+	}
+	return ""
+}
+
+// Push pushes current branch of repository to the given branch of the given remote. The remote branch
+// is set as upstream only when current branch does not track any branch yet so that pushing to other
+// remote such as a mirror does not change the upstream
 func (git *Git) Push(remote, branch string) error {
-	out, err := git.Exec("push", "-u", remote, branch)
+	args := []string{remote, "HEAD:" + branch}
+	if _, _, err := git.TrackingRemote(); err != nil {
+		args = append([]string{"-u"}, args...)
+	}
+	out, err := git.Exec("push", args...)
 	if err != nil {
 		return errors.Wrapf(err, "Cannot push changes to %s/%s at '%s': %s", remote, branch, git.canonRoot(), out)
 	}
 	return nil
 }
 
+// newGitForCmd creates Git instance for given subcommand which requires Git repository at home
+func newGitForCmd(subcmd string, c *Config) (*Git, error) {
+	git := NewGit(c)
+	if git == nil {
+		return nil, errors.Errorf("'%s' command cannot work without Git. Please check Git command listed in output of 'config' command is available", subcmd)
+	}
+
+	if _, err := os.Stat(filepath.Join(c.HomePath, ".git")); err != nil {
+		return nil, errors.New("'.git' directory does not exist in home. Please create a new note with `notes new` at first")
+	}
+
+	return git, nil
+}
+
 // NewGit creates Git instance from Config value. Home directory is assumed to be a root of Git repository
 func NewGit(c *Config) *Git {
 	if c.GitPath == "" {
 		// Git is optional
 		return nil
 	}
-	return &Git{c.GitPath, c.HomePath}
+	return &Git{c.GitPath, c.HomePath, c.GitAttributes}
 }
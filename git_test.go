@@ -3,6 +3,7 @@ package notes
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -90,27 +91,97 @@ func TestGitInitAddCommit(t *testing.T) {
 	}
 }
 
+func TestGitStagedChanges(t *testing.T) {
+	dir := "test-tmp-dir-git-staged"
+	panicIfErr(os.Mkdir(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	g := NewGit(&Config{GitPath: "git", HomePath: dir})
+	panicIfErr(g.Init())
+	_, err := g.Exec("config", "user.name", "You")
+	panicIfErr(err)
+	_, err = g.Exec("config", "user.email", "you@example.com")
+	panicIfErr(err)
+
+	write := func(name, content string) {
+		panicIfErr(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	write("a.txt", "this is a\n")
+	write("b.txt", "this is b\n")
+	write("c.txt", "this is c which will be renamed\n")
+	panicIfErr(g.AddAll())
+	panicIfErr(g.Commit("initial"))
+
+	changes, err := g.StagedChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatal("No change should be staged after commit:", changes)
+	}
+
+	write("a.txt", "this is modified a\n")
+	panicIfErr(os.Remove(filepath.Join(dir, "b.txt")))
+	panicIfErr(os.Rename(filepath.Join(dir, "c.txt"), filepath.Join(dir, "d.txt")))
+	write("e.txt", "this is new file\n")
+	panicIfErr(g.AddAll())
+
+	changes, err = g.StagedChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []GitChange{
+		{Status: 'M', Path: "a.txt"},
+		{Status: 'D', Path: "b.txt"},
+		{Status: 'R', Path: "d.txt", OldPath: "c.txt"},
+		{Status: 'A', Path: "e.txt"},
+	}
+	if len(changes) != len(want) {
+		t.Fatal("Unexpected number of changes:", len(changes), changes)
+	}
+	for i, w := range want {
+		if have := *changes[i]; have != w {
+			t.Errorf("Unexpected change at %d. want %+v but have %+v", i, w, have)
+		}
+	}
+}
+
 func TestGitTrackingRemote(t *testing.T) {
-	// This test cannot be run on CI since they use detached HEAD for clone
-	wantErr := false
-	if _, ok := os.LookupEnv("GITHUB_ACTIONS"); ok {
-		wantErr = true
+	repos := newTestSyncRepos("test-tmp-dir-git-tracking")
+	defer repos.cleanup()
+
+	cfg := repos.clone("a")
+	g := NewGit(cfg)
+
+	if _, _, err := g.TrackingRemote(); err == nil || !strings.Contains(err.Error(), "No upstream is configured") {
+		t.Fatal("Unexpected error for branch which tracks nothing:", err)
 	}
 
-	g := NewGit(&Config{GitPath: "git", HomePath: "."})
+	writeTestNote(cfg, "memo/foo.md", testSyncNote)
+	panicIfErr(g.AddAll())
+	panicIfErr(g.Commit("hello"))
+
+	out, err := g.Exec("remote", "rename", "origin", "my-remote")
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	out, err = g.Exec("checkout", "-b", "feature/notes")
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	panicIfErr(g.Push("my-remote", "feature/notes"))
+
 	re, br, err := g.TrackingRemote()
 	if err != nil {
-		if wantErr {
-			return
-		}
 		t.Fatal(err)
 	}
-	if re != "origin" {
+	if re != "my-remote" {
 		t.Error("Unexpected remote name", re)
 	}
-	out, err := g.Exec("rev-parse", "--verify", br)
-	if err != nil {
-		t.Error("Branch is not correct", br, out)
+	if br != "feature/notes" {
+		t.Error("Unexpected branch name", br)
 	}
 }
 
@@ -163,3 +234,73 @@ func TestGitInitTwice(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGitInitWritesGitignore(t *testing.T) {
+	dir := "test-tmp-dir-git-init-ignore"
+	panicIfErr(os.Mkdir(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	g := NewGit(&Config{GitPath: "git", HomePath: dir})
+	if err := g.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		t.Fatal(".gitignore was not created", err)
+	}
+	if have, want := string(b), strings.Join(defaultGitignore, "\n")+"\n"; have != want {
+		t.Fatalf("Unexpected .gitignore: want %q but have %q", want, have)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".gitattributes")); err == nil {
+		t.Fatal(".gitattributes should not be created when 'gitattributes' config is disabled")
+	}
+}
+
+func TestGitSetupAppendsMissingEntries(t *testing.T) {
+	dir := "test-tmp-dir-git-setup"
+	panicIfErr(os.Mkdir(dir, 0755))
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+
+	ignore := filepath.Join(dir, ".gitignore")
+	// Last line has no newline
+	panicIfErr(os.WriteFile(ignore, []byte("secret.md\n.DS_Store"), 0644))
+
+	g := NewGit(&Config{GitPath: "git", HomePath: dir, GitAttributes: true})
+	updated, err := g.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{ignore, filepath.Join(dir, ".gitattributes")}
+	if !reflect.DeepEqual(updated, want) {
+		t.Fatal("Unexpected updated files:", updated)
+	}
+
+	b, err := os.ReadFile(ignore)
+	panicIfErr(err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if lines[0] != "secret.md" || lines[1] != ".DS_Store" {
+		t.Fatal("Existing entries were not kept:", lines)
+	}
+	if strings.Count(string(b), ".DS_Store") != 1 {
+		t.Fatal("Existing entry was duplicated:", string(b))
+	}
+	if len(lines) != len(defaultGitignore)+1 {
+		t.Fatal("Unexpected number of entries:", lines)
+	}
+
+	b, err = os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	panicIfErr(err)
+	if string(b) != "*.md diff=markdown\n" {
+		t.Fatal("Unexpected .gitattributes:", string(b))
+	}
+
+	updated, err = g.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 0 {
+		t.Fatal("Nothing should be updated at second time:", updated)
+	}
+}
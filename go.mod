@@ -3,8 +3,10 @@ module github.com/rhysd/notes-cli
 go 1.19
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/fatih/color v1.13.0
+	github.com/fsnotify/fsnotify v1.6.0
 	github.com/google/go-cmp v0.5.8
 	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51
 	github.com/mattn/go-colorable v0.1.13
@@ -13,8 +15,10 @@ require (
 	github.com/rhysd/go-fakeio v1.0.0
 	github.com/rhysd/go-github-selfupdate v1.2.3
 	github.com/rhysd/go-tmpenv v1.2.0
+	github.com/yuin/goldmark v1.5.4
 	golang.org/x/text v0.3.7
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,170 @@
+package notes
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// reWikiLink matches wiki-style link such as [[category/file]], [[Title]], [[Title#Heading]] or
+// [[Title|text]]
+var reWikiLink = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+
+// reListItem matches start of list item such as "- foo" or "1. foo"
+var reListItem = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d+[.)])(?:\s|$)`)
+
+// noteLink represents a wiki-style link in body of note
+type noteLink struct {
+	// Target is a link target such as "category/file" or "Title". Heading and text of link are not included
+	Target string
+	// Line is a line number of the link in the note file. It starts from 1
+	Line int
+}
+
+// splitWikiLink splits content of wiki-style link into its target and the rest (heading and text)
+func splitWikiLink(content string) (string, string) {
+	if i := strings.IndexAny(content, "#|"); i >= 0 {
+		return strings.TrimSpace(content[:i]), content[i:]
+	}
+	return strings.TrimSpace(content), ""
+}
+
+// mapWikiLinks calls the function for each wiki-style link in body with its line number (starting from
+// 0) and target, and replaces the target with returned string. Links in code blocks and inline code are
+// ignored. Indented lines are treated as code block only when they start after an empty line outside
+// lists since they are continuation of paragraph or nested list items otherwise
+func mapWikiLinks(body string, f func(line int, target string) string) string {
+	lines := strings.SplitAfter(body, "\n")
+	inCode, inIndentedCode, inList, afterBlank := false, false, false, true
+	for i, l := range lines {
+		if t := strings.TrimSpace(l); strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			inCode = !inCode
+			inIndentedCode, afterBlank = false, false
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if strings.TrimSpace(l) == "" {
+			afterBlank = true
+			continue
+		}
+
+		indented := strings.HasPrefix(l, "    ") || strings.HasPrefix(l, "\t")
+		if indented && (inIndentedCode || afterBlank && !inList) {
+			inIndentedCode, afterBlank = true, false
+			continue
+		}
+		if !indented {
+			// Paragraph after empty line ends the list
+			inList = reListItem.MatchString(l) || inList && !afterBlank
+		}
+		inIndentedCode, afterBlank = false, false
+
+		parts := strings.Split(l, "`")
+		for j := 0; j < len(parts); j += 2 {
+			parts[j] = reWikiLink.ReplaceAllStringFunc(parts[j], func(m string) string {
+				target, rest := splitWikiLink(m[2 : len(m)-2])
+				if target == "" {
+					return m
+				}
+				return "[[" + f(i, target) + rest + "]]"
+			})
+		}
+		lines[i] = strings.Join(parts, "`")
+	}
+	return strings.Join(lines, "")
+}
+
+// readContent reads whole content of the note file and splits it into header and body
+func (note *Note) readContent() (string, string, error) {
+	b, err := os.ReadFile(note.FilePath())
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Cannot read note '%s'", note.RelFilePath())
+	}
+	return note.splitNoteContent(string(b))
+}
+
+// links reads wiki-style links in body of the note. Title and metadata are skipped
+func (note *Note) links() ([]*noteLink, error) {
+	header, body, err := note.readContent()
+	if err != nil {
+		return nil, err
+	}
+	offset := strings.Count(header, "\n") + 1
+	links := []*noteLink{}
+	mapWikiLinks(body, func(line int, target string) string {
+		links = append(links, &noteLink{target, line + offset})
+		return target
+	})
+	return links, nil
+}
+
+// noteLinkIndex resolves targets of wiki-style links into notes
+type noteLinkIndex struct {
+	notes   []*Note
+	byPath  map[string]*Note
+	byTitle map[string]*Note
+}
+
+// newNoteLinkIndex creates an index of given notes. Notes are sorted by their paths. When multiple notes
+// have the same title, [[Title]] refers the first one
+func newNoteLinkIndex(notes []*Note) *noteLinkIndex {
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].RelFilePath() < notes[j].RelFilePath()
+	})
+	idx := &noteLinkIndex{notes, make(map[string]*Note, len(notes)), make(map[string]*Note, len(notes))}
+	for _, n := range notes {
+		idx.byPath[linkPathOf(n)] = n
+		t := strings.ToLower(n.Title)
+		if _, ok := idx.byTitle[t]; !ok {
+			idx.byTitle[t] = n
+		}
+	}
+	return idx
+}
+
+// collectNoteLinkIndex collects all notes in home and creates an index of them
+func collectNoteLinkIndex(cfg *Config) (*noteLinkIndex, error) {
+	notes, err := collectNotes(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newNoteLinkIndex(notes), nil
+}
+
+// linkPathOf returns a slash-separated path of the note from home without '.md' for [[category/file]]
+// style link
+func linkPathOf(note *Note) string {
+	return strings.TrimSuffix(note.Category+"/"+note.File, ".md")
+}
+
+// resolvePath resolves [[category/file]] style link. '.md' extension can be omitted
+func (idx *noteLinkIndex) resolvePath(target string) *Note {
+	return idx.byPath[strings.TrimSuffix(target, ".md")]
+}
+
+// resolve resolves the target of link into a note. Path is preferred to title. Titles are compared
+// case-insensitively. When no note is found, it returns nil
+func (idx *noteLinkIndex) resolve(target string) *Note {
+	if n := idx.resolvePath(target); n != nil {
+		return n
+	}
+	return idx.byTitle[strings.ToLower(target)]
+}
+
+// note finds a note in the index by an argument of command such as "category/file.md"
+func (idx *noteLinkIndex) note(home, arg string) (*Note, error) {
+	rel, err := noteRelPath(home, arg)
+	if err != nil {
+		return nil, err
+	}
+	n := idx.resolvePath(rel)
+	if n == nil {
+		return nil, errors.Errorf("Note '%s' does not exist", rel)
+	}
+	return n, nil
+}
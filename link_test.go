@@ -0,0 +1,133 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testLinksConfig() *Config {
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	return &Config{HomePath: filepath.Join(cwd, "testdata", "links", "normal")}
+}
+
+func TestMapWikiLinks(t *testing.T) {
+	body := "[[foo]] and [[ bar/baz#Heading|text ]]\n" +
+		"`[[inline]]` [[after-inline]]\n" +
+		"\n" +
+		"    [[indented code]]\n" +
+		"```\n" +
+		"[[fenced]]\n" +
+		"```\n" +
+		"[[]] [[last]]"
+
+	targets := []string{}
+	lines := []int{}
+	have := mapWikiLinks(body, func(line int, target string) string {
+		targets = append(targets, target)
+		lines = append(lines, line)
+		return "x/" + target
+	})
+
+	if !cmp.Equal(targets, []string{"foo", "bar/baz", "after-inline", "last"}) {
+		t.Fatal("Unexpected targets:", targets)
+	}
+	if !cmp.Equal(lines, []int{0, 0, 1, 7}) {
+		t.Fatal("Unexpected lines:", lines)
+	}
+
+	want := "[[x/foo]] and [[x/bar/baz#Heading|text ]]\n" +
+		"`[[inline]]` [[x/after-inline]]\n" +
+		"\n" +
+		"    [[indented code]]\n" +
+		"```\n" +
+		"[[fenced]]\n" +
+		"```\n" +
+		"[[]] [[x/last]]"
+	if have != want {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestMapWikiLinksIndented(t *testing.T) {
+	body := "- [[top]]\n" +
+		"    - [[nested]]\n" +
+		"\t- [[nested with tab]]\n" +
+		"\n" +
+		"    [[loose list item]]\n" +
+		"1. [[ordered]]\n" +
+		"    1. [[nested ordered]]\n" +
+		"\n" +
+		"paragraph\n" +
+		"    [[continuation]]\n" +
+		"\n" +
+		"    [[indented code]]\n" +
+		"\n" +
+		"    [[indented code after blank]]\n" +
+		"[[after code]]\n"
+
+	targets := []string{}
+	mapWikiLinks(body, func(line int, target string) string {
+		targets = append(targets, target)
+		return target
+	})
+
+	want := []string{"top", "nested", "nested with tab", "loose list item", "ordered", "nested ordered", "continuation", "after code"}
+	if !cmp.Equal(targets, want) {
+		t.Fatal(cmp.Diff(want, targets))
+	}
+}
+
+func TestNoteLinks(t *testing.T) {
+	cfg := testLinksConfig()
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "memo", "a.md"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := n.links()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*noteLink{
+		{"memo/b", 7},
+		{"Note C", 7},
+		{"memo/b.md", 8},
+		{"Missing", 8},
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestNoteLinkIndexResolve(t *testing.T) {
+	idx, err := collectNoteLinkIndex(testLinksConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		target string
+		want   string
+	}{
+		{"memo/a", "memo/a.md"},
+		{"memo/a.md", "memo/a.md"},
+		{"A", "memo/a.md"},
+		{"note c", "blog/c.md"},
+		{"blog/c", "blog/c.md"},
+		{"memo", ""},
+		{"Missing", ""},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			have := ""
+			if n := idx.resolve(tc.target); n != nil {
+				have = filepath.ToSlash(n.RelFilePath())
+			}
+			if have != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/pkg/errors"
@@ -22,8 +23,9 @@ var (
 	// ***
 	// ___
 	//   -	-   - -  -
-	reHorizontalRule = regexp.MustCompile(`^\s{0,3}(?:(?:-+\s*){3,}|(?:\*+\s*){3,}|(?:_+\s*){3,})$`)
-	closingComment   = []byte("-->\n")
+	reHorizontalRule  = regexp.MustCompile(`^\s{0,3}(?:(?:-+\s*){3,}|(?:\*+\s*){3,}|(?:_+\s*){3,})$`)
+	closingComment    = []byte("-->\n")
+	dueMetadataPrefix = []byte("- Due: ")
 )
 
 // MismatchCategoryError represents an error caused when a user specifies mismatched category
@@ -55,6 +57,9 @@ type Note struct {
 	File string
 	// Title is a title string of the note. When the note is not created yet, it may be empty
 	Title string
+	// Due is a due date of the note written as '- Due: YYYY-MM-DD' metadata. It is optional and zero
+	// value means the note has no due date
+	Due time.Time
 }
 
 // DirPath returns the absolute category directory path of the note
@@ -88,20 +93,9 @@ func (note *Note) TemplatePath() (string, bool) {
 	}
 }
 
-// Create creates a file of the note. When title is empty, file name omitting file extension is used
-// for it. This function will fail when the file is already existing.
-func (note *Note) Create() error {
-	var template []byte
-	if p, ok := note.TemplatePath(); ok {
-		b, err := os.ReadFile(p)
-		if err != nil {
-			return errors.Wrapf(err, "Cannot read template file %q", p)
-		}
-		template = b
-	}
-
-	var b bytes.Buffer
-
+// writeHeader writes title and metadata of the note. When commentOut is true, comment is started before
+// metadata to surround it with comment
+func (note *Note) writeHeader(b *bytes.Buffer, commentOut bool) {
 	// Write title
 	title := note.Title
 	if title == "" {
@@ -110,23 +104,18 @@ func (note *Note) Create() error {
 	b.WriteString(title + "\n")
 	b.WriteString(strings.Repeat("=", runewidth.StringWidth(title)) + "\n")
 
-	if template != nil && bytes.HasPrefix(template, []byte("-->")) {
-		// User expects metadata to be commented out. Start to surround metadata with comment
+	if commentOut {
 		b.WriteString("<!--\n")
 	}
 
 	// Write metadata
-	fmt.Fprintf(&b, "- Category: %s\n", note.Category)
-	fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(note.Tags, ", "))
-	fmt.Fprintf(&b, "- Created: %s\n", note.Created.Format(time.RFC3339))
-
-	if len(template) > 0 {
-		b.Write(template)
-	} else {
-		// When template is not inserted, it's better to separate metadata and body with empty line
-		b.WriteRune('\n')
-	}
+	fmt.Fprintf(b, "- Category: %s\n", note.Category)
+	fmt.Fprintf(b, "- Tags: %s\n", strings.Join(note.Tags, ", "))
+	fmt.Fprintf(b, "- Created: %s\n", note.Created.Format(time.RFC3339))
+}
 
+// createFile creates a file of the note with the content. It fails when the file already exists
+func (note *Note) createFile(content []byte) error {
 	d := note.DirPath()
 	if err := os.MkdirAll(d, 0755); err != nil {
 		return errors.Wrapf(err, "Could not create category directory '%s'", d)
@@ -143,11 +132,38 @@ func (note *Note) Create() error {
 	}
 	defer f.Close()
 
-	f.Write(b.Bytes())
+	f.Write(content)
 
 	return nil
 }
 
+// Create creates a file of the note. When title is empty, file name omitting file extension is used
+// for it. This function will fail when the file is already existing.
+func (note *Note) Create() error {
+	var template []byte
+	if p, ok := note.TemplatePath(); ok {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot read template file %q", p)
+		}
+		template = b
+	}
+
+	var b bytes.Buffer
+
+	// When template starts with '-->', user expects metadata to be commented out
+	note.writeHeader(&b, template != nil && bytes.HasPrefix(template, []byte("-->")))
+
+	if len(template) > 0 {
+		b.Write(template)
+	} else {
+		// When template is not inserted, it's better to separate metadata and body with empty line
+		b.WriteRune('\n')
+	}
+
+	return note.createFile(b.Bytes())
+}
+
 // Open opens the note using an editor command user set. When user did not set any editor command
 // with $NOTES_CLI_EDITOR, this method fails. Otherwise, an editor process is spawned with argument
 // of path to the note file
@@ -193,7 +209,7 @@ func (note *Note) ReadBodyLines(maxLines int) (string, int, error) {
 		if err != nil {
 			break
 		}
-		if len(b) > 1 && !reHorizontalRule.Match(b) && !bytes.Equal(b, closingComment) {
+		if len(b) > 1 && !reHorizontalRule.Match(b) && !bytes.Equal(b, closingComment) && !bytes.HasPrefix(b, dueMetadataPrefix) {
 			buf.Write(b)
 			readLines++
 			break
@@ -212,6 +228,123 @@ func (note *Note) ReadBodyLines(maxLines int) (string, int, error) {
 	return buf.String(), readLines, nil
 }
 
+// splitNoteContent splits content of note file into header (title and metadata) and body. Leading empty
+// lines, horizontal rules and closing comment of metadata in body are included in the header
+func (note *Note) splitNoteContent(content string) (string, string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	i := 0
+	sawCat, sawTags, sawCreated := false, false, false
+	for ; i < len(lines) && !(sawCat && sawTags && sawCreated); i++ {
+		l := lines[i]
+		if strings.HasPrefix(l, "- Category: ") {
+			sawCat = true
+		} else if strings.HasPrefix(l, "- Tags:") {
+			sawTags = true
+		} else if strings.HasPrefix(l, "- Created: ") {
+			sawCreated = true
+		}
+	}
+	if !(sawCat && sawTags && sawCreated) {
+		return "", "", errors.Errorf("Cannot read metadata of note file. Some metadata may be missing in '%s'", note.RelFilePath())
+	}
+
+	for ; i < len(lines); i++ {
+		l := strings.TrimRight(lines[i], "\r\n")
+		if l != "" && !reHorizontalRule.MatchString(l) && l != "-->" && !strings.HasPrefix(l, "- Due: ") {
+			break
+		}
+	}
+
+	return strings.Join(lines[:i], ""), strings.Join(lines[i:], ""), nil
+}
+
+// ReadBody reads whole body of note. Title and metadata are not included. Leading empty lines,
+// horizontal rules and closing comment of metadata are also skipped
+func (note *Note) ReadBody() (string, error) {
+	b, err := os.ReadFile(note.FilePath())
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot read note file")
+	}
+	_, body, err := note.splitNoteContent(string(b))
+	return body, err
+}
+
+// WriteBody replaces whole body of note with given body. Title and metadata are kept as-is
+func (note *Note) WriteBody(body string) error {
+	p := note.FilePath()
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return errors.Wrap(err, "Cannot read note file")
+	}
+	header, _, err := note.splitNoteContent(string(b))
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(header, "\n") {
+		header += "\n"
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		body += "\n"
+	}
+	return errors.Wrapf(os.WriteFile(p, []byte(header+body), 0644), "Cannot write note '%s'", note.RelFilePath())
+}
+
+// createNoteWithBody creates a file of the note and writes given body after its metadata. This function
+// fails when the file already exists
+func createNoteWithBody(note *Note, body string) error {
+	if err := note.Create(); err != nil {
+		return err
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(note.FilePath(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Cannot open created note to write body")
+	}
+	defer f.Close()
+	if _, err := f.WriteString(body + "\n"); err != nil {
+		return errors.Wrapf(err, "Cannot write body to note '%s'", note.RelFilePath())
+	}
+	return nil
+}
+
+// fileNameOfTitle converts title of note into file name. Characters other than letters, digits and '_'
+// are replaced with '-'
+func fileNameOfTitle(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	f := strings.TrimSuffix(b.String(), "-")
+	if f == "" {
+		f = "untitled"
+	}
+	return f + ".md"
+}
+
+// parseDueDate parses due date in 'YYYY-MM-DD' format as a date in local time
+func parseDueDate(s string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(s), time.Local)
+	if err != nil {
+		return time.Time{}, errors.Errorf("Due date must be in 'YYYY-MM-DD' format but got '%s'", strings.TrimSpace(s))
+	}
+	return t, nil
+}
+
 // NewNote creates a new note instance with given parameters and configuration. Category and file name
 // cannot be empty. If given file name lacks file extension, it automatically adds ".md" to file name.
 func NewNote(cat, tags, file, title string, cfg *Config) (*Note, error) {
@@ -240,12 +373,12 @@ func NewNote(cat, tags, file, title string, cfg *Config) (*Note, error) {
 	if !strings.HasSuffix(file, ".md") {
 		file += ".md"
 	}
-	return &Note{cfg, cat, ts, time.Now(), file, title}, nil
+	return &Note{Config: cfg, Category: cat, Tags: ts, Created: time.Now(), File: file, Title: title}, nil
 }
 
 // LoadNote reads note file from given path, parses it and creates Note instance. When given file path
 // does not exist or when the file does note contain mandatory metadata ('Category', 'Tags' and 'Created'),
-// this function returns an error
+// this function returns an error. Malformed optional 'Due' metadata is ignored
 func LoadNote(path string, cfg *Config) (*Note, error) {
 	// This is necessary for macOS, where path contains NFD format
 	path = normPathNFD(path)
@@ -261,9 +394,13 @@ func LoadNote(path string, cfg *Config) (*Note, error) {
 	note.File = filepath.Base(path)
 
 	s := bufio.NewScanner(f)
-	titleFound := false
+	titleFound, complete := false, false
 	for s.Scan() {
 		line := s.Text()
+		// Optional metadata may follow mandatory metadata
+		if complete && !strings.HasPrefix(line, "- Due: ") {
+			break
+		}
 		// First line is title
 		if !titleFound {
 			if reTitleBar.MatchString(line) {
@@ -291,10 +428,13 @@ func LoadNote(path string, cfg *Config) (*Note, error) {
 				return nil, errors.Wrapf(err, "Cannot parse created date time as RFC3339 format: %s", line)
 			}
 			note.Created = t
+		} else if strings.HasPrefix(line, "- Due: ") {
+			// Malformed due date is ignored not to prevent loading the note. It is reported by `notes agenda`
+			if t, err := parseDueDate(line[7:]); err == nil {
+				note.Due = t
+			}
 		}
-		if note.Category != "" && note.Tags != nil && !note.Created.IsZero() && note.Title != "" {
-			break
-		}
+		complete = note.Category != "" && note.Tags != nil && !note.Created.IsZero() && note.Title != ""
 	}
 	if err := s.Err(); err != nil {
 		return nil, errors.Wrapf(err, "Cannot read note file '%s'", canonPath(path))
@@ -441,6 +441,57 @@ func TestNoteReadBodyNFailure(t *testing.T) {
 	}
 }
 
+func TestNoteReadBody(t *testing.T) {
+	cfg := noteTestdataConfig()
+	for _, tc := range []struct {
+		cat  string
+		file string
+		want string
+	}{
+		{"read-body", "short", "this\nis\ntest\n"},
+		{"read-body", "newlines-before-body", "this\nis\ntest\n"},
+		{"read-body", "no-body", ""},
+		{"read-body", "ignore-horizontal-rules", "text\n\n---\n^ not ignored\n"},
+		{"hide-metadata", "1.md", "this\nis\ntest\n"},
+		{"hide-metadata", "2.md", "this\nis\ntest\n"},
+	} {
+		t.Run(tc.cat+"/"+tc.file, func(t *testing.T) {
+			n, err := NewNote(tc.cat, "", tc.file, "this is title", cfg)
+			panicIfErr(err)
+			have, err := n.ReadBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have != tc.want {
+				t.Fatalf("have:\n%s\nwant:\n%s\nread string is unexpected", have, tc.want)
+			}
+		})
+	}
+
+	n, err := NewNote("read-body", "", "long", "this is title", cfg)
+	panicIfErr(err)
+	have, err := n.ReadBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := strings.Count(have, "\n"); c <= 4 {
+		t.Fatal("Whole body should be read but only", c, "lines were read")
+	}
+}
+
+func TestNoteReadBodyFailure(t *testing.T) {
+	cfg := noteTestdataConfig()
+	for _, file := range []string{"missing-created", "missing-tags", "missing-category"} {
+		t.Run(file, func(t *testing.T) {
+			n, err := NewNote("fail", "", file, "this is title", cfg)
+			panicIfErr(err)
+			if _, err := n.ReadBody(); err == nil || !strings.Contains(err.Error(), "Some metadata may be missing") {
+				t.Fatal("Unexpected error:", err)
+			}
+		})
+	}
+}
+
 func TestLoadNote(t *testing.T) {
 	cmpopt := cmpopts.IgnoreFields(Note{}, "Config", "Created")
 	cfg := noteTestdataConfig()
@@ -568,3 +619,116 @@ func TestLoadNoteMismatchCategory(t *testing.T) {
 		t.Fatal("Unexpected error:", err)
 	}
 }
+
+func TestNoteWriteBody(t *testing.T) {
+	dir := "test-tmp-dir-write-body"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+
+	n, err := NewNote("memo", "a, b", "foo", "This is title", cfg)
+	panicIfErr(err)
+	panicIfErr(createNoteWithBody(n, "old body\n"))
+
+	before, err := os.ReadFile(n.FilePath())
+	panicIfErr(err)
+
+	if err := n.WriteBody("new\nbody"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(n.FilePath())
+	panicIfErr(err)
+	want := strings.Replace(string(before), "old body\n", "new\nbody\n", 1)
+	if string(b) != want {
+		t.Fatalf("Wanted %q but got %q", want, string(b))
+	}
+
+	if err := n.WriteBody(""); err != nil {
+		t.Fatal(err)
+	}
+	body, err := n.ReadBody()
+	panicIfErr(err)
+	if body != "" {
+		t.Fatalf("Body was not cleared: %q", body)
+	}
+	l, err := LoadNote(n.FilePath(), cfg)
+	panicIfErr(err)
+	if l.Title != "This is title" || strings.Join(l.Tags, ",") != "a,b" {
+		t.Fatal("Metadata was not kept:", l.Title, l.Tags)
+	}
+}
+
+func TestFileNameOfTitle(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		want  string
+	}{
+		{"Meeting Memo", "meeting-memo.md"},
+		{"  Hello, World!!  ", "hello-world.md"},
+		{"日本語 メモ", "日本語-メモ.md"},
+		{"???", "untitled.md"},
+	} {
+		if have := fileNameOfTitle(tc.title); have != tc.want {
+			t.Errorf("Wanted %q for %q but got %q", tc.want, tc.title, have)
+		}
+	}
+}
+
+func TestLoadNoteWithDue(t *testing.T) {
+	cfg := testAgendaConfig("normal")
+	for _, tc := range []struct {
+		path string
+		due  time.Time
+		body string
+	}{
+		{"blog/post.md", time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local), "Body of the post.\n"},
+		{"memo/shopping.md", time.Time{}, "- [ ] milk @due(2026-10-17)\n"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			n, err := LoadNote(filepath.Join(cfg.HomePath, filepath.FromSlash(tc.path)), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !n.Due.Equal(tc.due) {
+				t.Fatal("Unexpected due date:", n.Due)
+			}
+			body, err := n.ReadBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(body, tc.body) {
+				t.Fatalf("Due metadata should not be included in body: %q", body)
+			}
+			body, _, err = n.ReadBodyLines(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body != tc.body {
+				t.Fatalf("Due metadata should not be included in body lines: %q", body)
+			}
+		})
+	}
+}
+
+func TestLoadNoteWithInvalidDue(t *testing.T) {
+	dir := "test-tmp-dir-load-invalid-due"
+	defer func() { panicIfErr(os.RemoveAll(dir)) }()
+	cwd, err := os.Getwd()
+	panicIfErr(err)
+	cfg := &Config{HomePath: filepath.Join(cwd, dir)}
+	writeTestNote(cfg, "memo/foo.md", "foo\n===\n- Category: memo\n- Tags: a\n- Created: 2026-10-01T10:00:00+09:00\n- Due: 2026-13-01\n\nbody\n")
+
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "memo", "foo.md"), cfg)
+	if err != nil {
+		t.Fatal("Malformed due date should not prevent loading note:", err)
+	}
+	if n.Title != "foo" || n.Category != "memo" || !n.Due.IsZero() {
+		t.Fatal("Unexpected note:", n.Title, n.Category, n.Due)
+	}
+	body, err := n.ReadBody()
+	panicIfErr(err)
+	if body != "body\n" {
+		t.Fatalf("Unexpected body: %q", body)
+	}
+}
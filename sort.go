@@ -99,3 +99,19 @@ func sortByModified(notes []*Note) error {
 
 	return nil
 }
+
+// sortNotes sorts given notes by one of "modified", "created", "filename" or "category". Unknown or empty
+// value means "created"
+func sortNotes(notes []*Note, by string) error {
+	switch strings.ToLower(by) {
+	case "filename":
+		sortByFilename(notes)
+	case "category":
+		sortByCategory(notes)
+	case "modified":
+		return sortByModified(notes)
+	default:
+		sortByCreated(notes)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package notes
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// reTask matches a task item of list such as "- [ ] do something" or "1. [x] done". The second group is
+// the check mark and the last group is text of the task
+var reTask = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])(\]\s+)(.*?)\s*$`)
+
+// reTaskDue matches inline due date of task item such as "@due(2026-11-01)"
+var reTaskDue = regexp.MustCompile(`\s*@due\(([^)]*)\)`)
+
+// noteTask represents a task item with checkbox in body of note
+type noteTask struct {
+	// Line is a line number of the task in the note file. It starts from 1
+	Line int
+	// Done is true when the checkbox is checked
+	Done bool
+	// Text is a text of the task following the checkbox
+	Text string
+}
+
+// parseTasks parses task items in body of note. offset is a line number of the first line of the body.
+// Task items in code blocks are ignored
+func parseTasks(body string, offset int) []*noteTask {
+	tasks := []*noteTask{}
+	inCode := false
+	for i, l := range strings.Split(body, "\n") {
+		if t := strings.TrimSpace(l); strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		m := reTask.FindStringSubmatch(l)
+		if m == nil || m[4] == "" {
+			continue
+		}
+		tasks = append(tasks, &noteTask{i + offset, m[2] != " ", m[4]})
+	}
+	return tasks
+}
+
+// tasks reads task items in body of the note. Title and metadata are skipped
+func (note *Note) tasks() ([]*noteTask, error) {
+	header, body, err := note.readContent()
+	if err != nil {
+		return nil, err
+	}
+	return parseTasks(body, strings.Count(header, "\n")+1), nil
+}
+
+// due returns a due date of the task written as "@due(YYYY-MM-DD)" in its text. It returns zero value
+// when the task has no due date
+func (task *noteTask) due() (time.Time, error) {
+	m := reTaskDue.FindStringSubmatch(task.Text)
+	if m == nil {
+		return time.Time{}, nil
+	}
+	return parseDueDate(m[1])
+}
+
+// textWithoutDue returns text of the task removing its due date
+func (task *noteTask) textWithoutDue() string {
+	return strings.TrimSpace(reTaskDue.ReplaceAllString(task.Text, ""))
+}
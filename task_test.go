@@ -0,0 +1,92 @@
+package notes
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTasks(t *testing.T) {
+	body := "- [ ] first\n" +
+		"  * [x] nested done\n" +
+		"+ [X]  upper case  \n" +
+		"1. [ ] numbered\n" +
+		"2) [ ] numbered with paren\r\n" +
+		"- [ ]\n" +
+		"- [] not task\n" +
+		"[ ] not list\n" +
+		"```\n" +
+		"- [ ] in code\n" +
+		"```\n" +
+		"- [ ] last"
+
+	have := parseTasks(body, 7)
+	want := []*noteTask{
+		{7, false, "first"},
+		{8, true, "nested done"},
+		{9, true, "upper case"},
+		{10, false, "numbered"},
+		{11, false, "numbered with paren"},
+		{18, false, "last"},
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestNoteTasks(t *testing.T) {
+	cfg := testTodoConfig()
+	n, err := LoadNote(filepath.Join(cfg.HomePath, "memo", "shopping.md"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := n.tasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*noteTask{
+		{7, false, "milk"},
+		{8, true, "eggs"},
+		{9, false, "bread"},
+		{10, true, "butter"},
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestTaskDue(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		due  time.Time
+		want string
+		err  bool
+	}{
+		{"tag release @due(2026-10-19)", time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local), "tag release", false},
+		{"@due( 2026-01-02 ) start of text", time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local), "start of text", false},
+		{"no due date", time.Time{}, "no due date", false},
+		{"broken @due(tomorrow)", time.Time{}, "broken", true},
+	} {
+		t.Run(tc.text, func(t *testing.T) {
+			task := &noteTask{Text: tc.text}
+			due, err := task.due()
+			if tc.err {
+				if err == nil {
+					t.Fatal("Error did not occur")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !due.Equal(tc.due) {
+				t.Fatal("Unexpected due date:", due)
+			}
+			if have := task.textWithoutDue(); have != tc.want {
+				t.Fatalf("Wanted %q but have %q", tc.want, have)
+			}
+		})
+	}
+}
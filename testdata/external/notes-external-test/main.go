@@ -3,10 +3,16 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 func main() {
 	fmt.Println("Output from stdout")
 	fmt.Fprintln(os.Stderr, "Output from stderr")
 	fmt.Println(os.Args[1:])
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "NOTES_CLI_") {
+			fmt.Printf("%q\n", e)
+		}
+	}
 }